@@ -0,0 +1,615 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+	"time"
+)
+
+// aiffTextChunks - text chunks in write order
+var aiffTextChunks = []string{aiffNameChunk, aiffAuthorChunk, aiffAnnotationChunk, aiffCopyrightChunk}
+
+// AIFF - NAME, AUTH, ANNO and (c) text chunks and ID3v2 tag from 'ID3 ' chunk.
+// Text chunks have priority on read, ID3v2 tag keeps other fields
+type AIFF struct {
+	FormType string // AIFF or AIFC
+	Chunks   []*IFFChunk
+	Text     map[string]string // text chunk ID to value, first chunk of each ID
+	ID3      Metadata          // *ID3v22, *ID3v23 or *ID3v24
+
+	trailer *fileRegion // data after FORM chunk, e.g. ID3v1 tag, written as is
+}
+
+func (aiff *AIFF) GetAllTagNames() []string {
+	result := make([]string, 0, len(aiff.Text))
+	for key := range aiff.Text {
+		result = append(result, key)
+	}
+	if aiff.ID3 != nil {
+		result = append(result, aiff.ID3.GetAllTagNames()...)
+	}
+	return result
+}
+
+func (aiff *AIFF) GetVersion() Version {
+	return VersionAIFF
+}
+
+// GetFileData - sound data chunk
+func (aiff *AIFF) GetFileData() []byte {
+	chunk := findIFFChunk(aiff.Chunks, aiffSoundDataChunk)
+	if chunk == nil {
+		return nil
+	}
+	return readSection(chunk.Reader())
+}
+
+func (aiff *AIFF) AudioReader() io.Reader {
+	chunk := findIFFChunk(aiff.Chunks, aiffSoundDataChunk)
+	if chunk == nil {
+		return bytes.NewReader(nil)
+	}
+	return chunk.Reader()
+}
+
+func (aiff *AIFF) GetTitle() (string, error) {
+	title, err := aiff.GetText(aiffNameChunk)
+	if err == nil || aiff.ID3 == nil {
+		return title, err
+	}
+	return aiff.ID3.GetTitle()
+}
+
+func (aiff *AIFF) GetArtist() (string, error) {
+	artist, err := aiff.GetText(aiffAuthorChunk)
+	if err == nil || aiff.ID3 == nil {
+		return artist, err
+	}
+	return aiff.ID3.GetArtist()
+}
+
+func (aiff *AIFF) GetAlbum() (string, error) {
+	if aiff.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return aiff.ID3.GetAlbum()
+}
+
+func (aiff *AIFF) GetYear() (int, error) {
+	if aiff.ID3 == nil {
+		return 0, ErrTagNotFound
+	}
+	return aiff.ID3.GetYear()
+}
+
+func (aiff *AIFF) GetComment() (string, error) {
+	comment, err := aiff.GetText(aiffAnnotationChunk)
+	if err == nil || aiff.ID3 == nil {
+		return comment, err
+	}
+	return aiff.ID3.GetComment()
+}
+
+func (aiff *AIFF) GetGenre() (string, error) {
+	if aiff.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return aiff.ID3.GetGenre()
+}
+
+func (aiff *AIFF) GetAlbumArtist() (string, error) {
+	if aiff.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return aiff.ID3.GetAlbumArtist()
+}
+
+func (aiff *AIFF) GetDate() (time.Time, error) {
+	if aiff.ID3 == nil {
+		return time.Now(), ErrTagNotFound
+	}
+	return aiff.ID3.GetDate()
+}
+
+func (aiff *AIFF) GetArranger() (string, error) {
+	if aiff.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return aiff.ID3.GetArranger()
+}
+
+func (aiff *AIFF) GetAuthor() (string, error) {
+	if aiff.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return aiff.ID3.GetAuthor()
+}
+
+func (aiff *AIFF) GetBPM() (int, error) {
+	if aiff.ID3 == nil {
+		return 0, ErrTagNotFound
+	}
+	return aiff.ID3.GetBPM()
+}
+
+func (aiff *AIFF) GetCatalogNumber() (string, error) {
+	if aiff.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return aiff.ID3.GetCatalogNumber()
+}
+
+func (aiff *AIFF) GetCompilation() (string, error) {
+	if aiff.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return aiff.ID3.GetCompilation()
+}
+
+func (aiff *AIFF) GetComposer() (string, error) {
+	if aiff.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return aiff.ID3.GetComposer()
+}
+
+func (aiff *AIFF) GetConductor() (string, error) {
+	if aiff.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return aiff.ID3.GetConductor()
+}
+
+func (aiff *AIFF) GetCopyright() (string, error) {
+	copyright, err := aiff.GetText(aiffCopyrightChunk)
+	if err == nil || aiff.ID3 == nil {
+		return copyright, err
+	}
+	return aiff.ID3.GetCopyright()
+}
+
+func (aiff *AIFF) GetDescription() (string, error) {
+	if aiff.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return aiff.ID3.GetDescription()
+}
+
+func (aiff *AIFF) GetDiscNumber() (int, int, error) {
+	if aiff.ID3 == nil {
+		return 0, 0, ErrTagNotFound
+	}
+	return aiff.ID3.GetDiscNumber()
+}
+
+func (aiff *AIFF) GetEncodedBy() (string, error) {
+	if aiff.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return aiff.ID3.GetEncodedBy()
+}
+
+func (aiff *AIFF) GetTrackNumber() (int, int, error) {
+	if aiff.ID3 == nil {
+		return 0, 0, ErrTagNotFound
+	}
+	return aiff.ID3.GetTrackNumber()
+}
+
+func (aiff *AIFF) GetPicture() (image.Image, error) {
+	if aiff.ID3 == nil {
+		return nil, ErrTagNotFound
+	}
+	return aiff.ID3.GetPicture()
+}
+
+func (aiff *AIFF) GetLyrics(language string) (string, error) {
+	if aiff.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return aiff.ID3.GetLyrics(language)
+}
+
+func (aiff *AIFF) SetTitle(title string) error {
+	aiff.Text[aiffNameChunk] = title
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.SetTitle(title)
+}
+
+func (aiff *AIFF) SetArtist(artist string) error {
+	aiff.Text[aiffAuthorChunk] = artist
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.SetArtist(artist)
+}
+
+func (aiff *AIFF) SetAlbum(album string) error {
+	return aiff.id3().SetAlbum(album)
+}
+
+func (aiff *AIFF) SetYear(year int) error {
+	return aiff.id3().SetYear(year)
+}
+
+func (aiff *AIFF) SetComment(comment string) error {
+	aiff.Text[aiffAnnotationChunk] = comment
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.SetComment(comment)
+}
+
+func (aiff *AIFF) SetGenre(genre string) error {
+	return aiff.id3().SetGenre(genre)
+}
+
+func (aiff *AIFF) SetAlbumArtist(albumArtist string) error {
+	return aiff.id3().SetAlbumArtist(albumArtist)
+}
+
+func (aiff *AIFF) SetDate(date time.Time) error {
+	return aiff.id3().SetDate(date)
+}
+
+func (aiff *AIFF) SetArranger(arranger string) error {
+	return aiff.id3().SetArranger(arranger)
+}
+
+func (aiff *AIFF) SetAuthor(author string) error {
+	return aiff.id3().SetAuthor(author)
+}
+
+func (aiff *AIFF) SetBPM(bmp int) error {
+	return aiff.id3().SetBPM(bmp)
+}
+
+func (aiff *AIFF) SetCatalogNumber(catalogNumber string) error {
+	return aiff.id3().SetCatalogNumber(catalogNumber)
+}
+
+func (aiff *AIFF) SetCompilation(compilation string) error {
+	return aiff.id3().SetCompilation(compilation)
+}
+
+func (aiff *AIFF) SetComposer(composer string) error {
+	return aiff.id3().SetComposer(composer)
+}
+
+func (aiff *AIFF) SetConductor(conductor string) error {
+	return aiff.id3().SetConductor(conductor)
+}
+
+func (aiff *AIFF) SetCopyright(copyright string) error {
+	aiff.Text[aiffCopyrightChunk] = copyright
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.SetCopyright(copyright)
+}
+
+func (aiff *AIFF) SetDescription(description string) error {
+	return aiff.id3().SetDescription(description)
+}
+
+func (aiff *AIFF) SetDiscNumber(number int, total int) error {
+	return aiff.id3().SetDiscNumber(number, total)
+}
+
+func (aiff *AIFF) SetEncodedBy(encodedBy string) error {
+	return aiff.id3().SetEncodedBy(encodedBy)
+}
+
+func (aiff *AIFF) SetTrackNumber(number int, total int) error {
+	return aiff.id3().SetTrackNumber(number, total)
+}
+
+func (aiff *AIFF) SetPicture(picture image.Image) error {
+	return aiff.id3().SetPicture(picture)
+}
+
+func (aiff *AIFF) SetLyrics(language string, lyrics string) error {
+	return aiff.id3().SetLyrics(language, lyrics)
+}
+
+func (aiff *AIFF) DeleteAll() error {
+	aiff.Text = map[string]string{}
+	aiff.ID3 = nil
+	return nil
+}
+
+func (aiff *AIFF) DeleteTitle() error {
+	delete(aiff.Text, aiffNameChunk)
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteTitle()
+}
+
+func (aiff *AIFF) DeleteArtist() error {
+	delete(aiff.Text, aiffAuthorChunk)
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteArtist()
+}
+
+func (aiff *AIFF) DeleteAlbum() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteAlbum()
+}
+
+func (aiff *AIFF) DeleteYear() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteYear()
+}
+
+func (aiff *AIFF) DeleteComment() error {
+	delete(aiff.Text, aiffAnnotationChunk)
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteComment()
+}
+
+func (aiff *AIFF) DeleteGenre() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteGenre()
+}
+
+func (aiff *AIFF) DeleteAlbumArtist() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteAlbumArtist()
+}
+
+func (aiff *AIFF) DeleteDate() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteDate()
+}
+
+func (aiff *AIFF) DeleteArranger() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteArranger()
+}
+
+func (aiff *AIFF) DeleteAuthor() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteAuthor()
+}
+
+func (aiff *AIFF) DeleteBPM() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteBPM()
+}
+
+func (aiff *AIFF) DeleteCatalogNumber() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteCatalogNumber()
+}
+
+func (aiff *AIFF) DeleteCompilation() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteCompilation()
+}
+
+func (aiff *AIFF) DeleteComposer() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteComposer()
+}
+
+func (aiff *AIFF) DeleteConductor() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteConductor()
+}
+
+func (aiff *AIFF) DeleteCopyright() error {
+	delete(aiff.Text, aiffCopyrightChunk)
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteCopyright()
+}
+
+func (aiff *AIFF) DeleteDescription() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteDescription()
+}
+
+func (aiff *AIFF) DeleteDiscNumber() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteDiscNumber()
+}
+
+func (aiff *AIFF) DeleteEncodedBy() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteEncodedBy()
+}
+
+func (aiff *AIFF) DeleteTrackNumber() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteTrackNumber()
+}
+
+func (aiff *AIFF) DeletePicture() error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeletePicture()
+}
+
+func (aiff *AIFF) DeleteLyrics(language string) error {
+	if aiff.ID3 == nil {
+		return nil
+	}
+	return aiff.ID3.DeleteLyrics(language)
+}
+
+func (aiff *AIFF) SaveFile(path string) error {
+	return saveFile(path, aiff.Save, SaveOptions{})
+}
+
+// Save - write FORM file with big endian sizes and rebuilt text and 'ID3 ' chunks.
+// Chunks keep their order, new chunks are added to the end
+func (aiff *AIFF) Save(input io.WriteSeeker) error {
+	var id3 []byte
+	if aiff.ID3 != nil {
+		buffer := &writeSeekBuffer{}
+		if err := aiff.ID3.Save(buffer); err != nil {
+			return err
+		}
+		id3 = buffer.Bytes()
+	}
+
+	written := map[string]bool{}
+	chunks := make([]*IFFChunk, 0, len(aiff.Chunks)+len(aiffTextChunks)+1)
+	for _, chunk := range aiff.Chunks {
+		switch {
+		case isAIFFTextChunk(chunk.ID):
+			// only first chunk of each ID holds the value
+			if written[chunk.ID] {
+				chunks = append(chunks, chunk)
+				continue
+			}
+			written[chunk.ID] = true
+			if value, ok := aiff.Text[chunk.ID]; ok {
+				chunks = append(chunks, &IFFChunk{ID: chunk.ID, Data: []byte(value)})
+			}
+		case isIFFID3(chunk):
+			if id3 != nil {
+				chunks = append(chunks, &IFFChunk{ID: aiffID3Chunk, Data: id3})
+				id3 = nil
+			}
+		default:
+			chunks = append(chunks, chunk)
+		}
+	}
+	for _, id := range aiffTextChunks {
+		if value, ok := aiff.Text[id]; ok && !written[id] {
+			chunks = append(chunks, &IFFChunk{ID: id, Data: []byte(value)})
+		}
+	}
+	if id3 != nil {
+		chunks = append(chunks, &IFFChunk{ID: aiffID3Chunk, Data: id3})
+	}
+
+	err := writeIFF(input, binary.BigEndian, aiffFORMIdentifier, aiff.FormType, chunks)
+	if err != nil {
+		return err
+	}
+	err = writeAudio(input, nil, aiff.trailer)
+	if err != nil {
+		return err
+	}
+	aiff.Chunks = chunks
+	return nil
+}
+
+func checkAIFF(input io.ReadSeeker) bool {
+	header, err := seekAndRead(input, 0, io.SeekStart, 12)
+	if err != nil {
+		return false
+	}
+	formType := string(header[8:12])
+	return string(header[0:4]) == aiffFORMIdentifier && (formType == aiffFormType || formType == aifcFormType)
+}
+
+func ReadAIFF(input io.ReadSeeker) (*AIFF, error) {
+	aiff := AIFF{
+		Text: map[string]string{},
+	}
+
+	header, err := seekAndRead(input, 0, io.SeekStart, 12)
+	if err != nil {
+		return nil, err
+	}
+	aiff.FormType = string(header[8:12])
+	if string(header[0:4]) != aiffFORMIdentifier || (aiff.FormType != aiffFormType && aiff.FormType != aifcFormType) {
+		return nil, ErrFileMarker
+	}
+
+	// chunks after FORM header
+	aiff.Chunks, aiff.trailer, err = readIFFFileChunks(input, binary.BigEndian, aiffSoundDataChunk)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, chunk := range aiff.Chunks {
+		switch {
+		case isAIFFTextChunk(chunk.ID):
+			if _, ok := aiff.Text[chunk.ID]; !ok {
+				aiff.Text[chunk.ID] = string(chunk.Data)
+			}
+		case isIFFID3(chunk) && aiff.ID3 == nil:
+			aiff.ID3, err = readIFFID3(chunk.Data)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &aiff, nil
+}
+
+func isAIFFTextChunk(id string) bool {
+	for _, text := range aiffTextChunks {
+		if id == text {
+			return true
+		}
+	}
+	return false
+}
+
+func (aiff *AIFF) GetText(id string) (string, error) {
+	value, ok := aiff.Text[id]
+	if !ok {
+		return "", ErrTagNotFound
+	}
+	return value, nil
+}
+
+// id3 - ID3v2 tag for fields without text chunk, created on demand
+func (aiff *AIFF) id3() Metadata {
+	if aiff.ID3 == nil {
+		aiff.ID3 = &ID3v24{
+			Marker:  id3MarkerValue,
+			Version: VersionID3v24,
+		}
+	}
+	return aiff.ID3
+}
@@ -0,0 +1,658 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APEv2ItemType - bits 1-2 of the item flags
+type APEv2ItemType byte
+
+const (
+	APEv2ItemText    APEv2ItemType = 0 // UTF-8 text, multiple values are separated by zero byte
+	APEv2ItemBinary  APEv2ItemType = 1 // binary data
+	APEv2ItemLocator APEv2ItemType = 2 // UTF-8 link to external information
+)
+
+type APEv2Item struct {
+	Key      string
+	Type     APEv2ItemType
+	ReadOnly bool
+	Value    []byte
+}
+
+// APEv2 - tag at the end of the file (before ID3v1 if any)
+type APEv2 struct {
+	Version int
+	Items   []*APEv2Item
+
+	// Data - file data before tag, nil if it is read from the source on demand
+	Data  []byte
+	audio *fileRegion
+	// ID3v1 tag after APEv2 tag, saved as is
+	ID3v1 []byte
+}
+
+func (ape *APEv2) GetAllTagNames() []string {
+	result := make([]string, 0, len(ape.Items))
+	for _, item := range ape.Items {
+		result = append(result, item.Key)
+	}
+	return result
+}
+
+func (ape *APEv2) GetVersion() Version {
+	return VersionAPEv2
+}
+
+func (ape *APEv2) GetFileData() []byte {
+	return readSection(audioSection(ape.Data, ape.audio))
+}
+
+func (ape *APEv2) AudioReader() io.Reader {
+	return audioSection(ape.Data, ape.audio)
+}
+
+func (ape *APEv2) GetTitle() (string, error) {
+	return ape.GetText("Title")
+}
+
+func (ape *APEv2) GetArtist() (string, error) {
+	return ape.GetText("Artist")
+}
+
+func (ape *APEv2) GetAlbum() (string, error) {
+	return ape.GetText("Album")
+}
+
+func (ape *APEv2) GetYear() (int, error) {
+	year, err := ape.GetText("Year")
+	if err != nil {
+		return 0, err
+	}
+	if len(year) > 4 {
+		year = year[0:4]
+	}
+	return strconv.Atoi(year)
+}
+
+func (ape *APEv2) GetComment() (string, error) {
+	return ape.GetText("Comment")
+}
+
+func (ape *APEv2) GetGenre() (string, error) {
+	return ape.GetText("Genre")
+}
+
+func (ape *APEv2) GetAlbumArtist() (string, error) {
+	return ape.GetText("Album Artist")
+}
+
+func (ape *APEv2) GetDate() (time.Time, error) {
+	date, err := ape.GetText("Record Date")
+	if err != nil {
+		return time.Now(), err
+	}
+	return time.Parse("2006-01-02T15:04:05", date)
+}
+
+func (ape *APEv2) GetArranger() (string, error) {
+	return ape.GetText("Arranger")
+}
+
+func (ape *APEv2) GetAuthor() (string, error) {
+	return ape.GetText("Lyricist")
+}
+
+func (ape *APEv2) GetBPM() (int, error) {
+	return ape.GetInt("BPM")
+}
+
+func (ape *APEv2) GetCatalogNumber() (string, error) {
+	return ape.GetText("Catalog")
+}
+
+func (ape *APEv2) GetCompilation() (string, error) {
+	return ape.GetText("Compilation")
+}
+
+func (ape *APEv2) GetComposer() (string, error) {
+	return ape.GetText("Composer")
+}
+
+func (ape *APEv2) GetConductor() (string, error) {
+	return ape.GetText("Conductor")
+}
+
+func (ape *APEv2) GetCopyright() (string, error) {
+	return ape.GetText("Copyright")
+}
+
+func (ape *APEv2) GetDescription() (string, error) {
+	return ape.GetText("Subtitle")
+}
+
+func (ape *APEv2) GetDiscNumber() (int, int, error) {
+	return ape.GetNumberTotal("Disc")
+}
+
+func (ape *APEv2) GetEncodedBy() (string, error) {
+	return ape.GetText("Encoded By")
+}
+
+func (ape *APEv2) GetTrackNumber() (int, int, error) {
+	return ape.GetNumberTotal("Track")
+}
+
+// GetPicture - image from 'Cover Art (Front)' item.
+// Binary item is file name, zero byte and image data, locator item is link to image
+func (ape *APEv2) GetPicture() (image.Image, error) {
+	item, err := ape.GetItem(apev2CoverArtFront)
+	if err != nil {
+		return nil, err
+	}
+
+	switch item.Type {
+	case APEv2ItemBinary:
+		parts := bytes.SplitN(item.Value, []byte{0}, 2)
+		if len(parts) != 2 {
+			return nil, ErrIncorrectTag
+		}
+		picture, _, err := image.Decode(bytes.NewReader(parts[1]))
+		return picture, err
+	case APEv2ItemLocator:
+		return downloadImage(string(item.Value))
+	}
+
+	return nil, ErrIncorrectTag
+}
+
+// GetLyrics - Lyrics item, APEv2 lyrics have no language
+func (ape *APEv2) GetLyrics(language string) (string, error) {
+	return ape.GetText("Lyrics")
+}
+
+func (ape *APEv2) SetTitle(title string) error {
+	return ape.SetText("Title", title)
+}
+
+func (ape *APEv2) SetArtist(artist string) error {
+	return ape.SetText("Artist", artist)
+}
+
+func (ape *APEv2) SetAlbum(album string) error {
+	return ape.SetText("Album", album)
+}
+
+func (ape *APEv2) SetYear(year int) error {
+	return ape.SetText("Year", strconv.Itoa(year))
+}
+
+func (ape *APEv2) SetComment(comment string) error {
+	return ape.SetText("Comment", comment)
+}
+
+func (ape *APEv2) SetGenre(genre string) error {
+	return ape.SetText("Genre", genre)
+}
+
+func (ape *APEv2) SetAlbumArtist(albumArtist string) error {
+	return ape.SetText("Album Artist", albumArtist)
+}
+
+func (ape *APEv2) SetDate(date time.Time) error {
+	return ape.SetText("Record Date", date.Format("2006-01-02T15:04:05"))
+}
+
+func (ape *APEv2) SetArranger(arranger string) error {
+	return ape.SetText("Arranger", arranger)
+}
+
+func (ape *APEv2) SetAuthor(author string) error {
+	return ape.SetText("Lyricist", author)
+}
+
+func (ape *APEv2) SetBPM(bmp int) error {
+	return ape.SetText("BPM", strconv.Itoa(bmp))
+}
+
+func (ape *APEv2) SetCatalogNumber(catalogNumber string) error {
+	return ape.SetText("Catalog", catalogNumber)
+}
+
+func (ape *APEv2) SetCompilation(compilation string) error {
+	return ape.SetText("Compilation", compilation)
+}
+
+func (ape *APEv2) SetComposer(composer string) error {
+	return ape.SetText("Composer", composer)
+}
+
+func (ape *APEv2) SetConductor(conductor string) error {
+	return ape.SetText("Conductor", conductor)
+}
+
+func (ape *APEv2) SetCopyright(copyright string) error {
+	return ape.SetText("Copyright", copyright)
+}
+
+func (ape *APEv2) SetDescription(description string) error {
+	return ape.SetText("Subtitle", description)
+}
+
+func (ape *APEv2) SetDiscNumber(number int, total int) error {
+	return ape.SetText("Disc", strconv.Itoa(number)+"/"+strconv.Itoa(total))
+}
+
+func (ape *APEv2) SetEncodedBy(encodedBy string) error {
+	return ape.SetText("Encoded By", encodedBy)
+}
+
+func (ape *APEv2) SetTrackNumber(number int, total int) error {
+	return ape.SetText("Track", strconv.Itoa(number)+"/"+strconv.Itoa(total))
+}
+
+func (ape *APEv2) SetPicture(picture image.Image) error {
+	// Only PNG
+	buf := new(bytes.Buffer)
+	err := png.Encode(buf, picture)
+	if err != nil {
+		return err
+	}
+
+	value := append([]byte("cover.png\x00"), buf.Bytes()...)
+	return ape.SetItem(&APEv2Item{
+		Key:   apev2CoverArtFront,
+		Type:  APEv2ItemBinary,
+		Value: value,
+	})
+}
+
+func (ape *APEv2) SetLyrics(language string, lyrics string) error {
+	return ape.SetText("Lyrics", lyrics)
+}
+
+func (ape *APEv2) DeleteAll() error {
+	ape.Items = []*APEv2Item{}
+	return nil
+}
+
+func (ape *APEv2) DeleteTitle() error {
+	return ape.DeleteItem("Title")
+}
+
+func (ape *APEv2) DeleteArtist() error {
+	return ape.DeleteItem("Artist")
+}
+
+func (ape *APEv2) DeleteAlbum() error {
+	return ape.DeleteItem("Album")
+}
+
+func (ape *APEv2) DeleteYear() error {
+	return ape.DeleteItem("Year")
+}
+
+func (ape *APEv2) DeleteComment() error {
+	return ape.DeleteItem("Comment")
+}
+
+func (ape *APEv2) DeleteGenre() error {
+	return ape.DeleteItem("Genre")
+}
+
+func (ape *APEv2) DeleteAlbumArtist() error {
+	return ape.DeleteItem("Album Artist")
+}
+
+func (ape *APEv2) DeleteDate() error {
+	return ape.DeleteItem("Record Date")
+}
+
+func (ape *APEv2) DeleteArranger() error {
+	return ape.DeleteItem("Arranger")
+}
+
+func (ape *APEv2) DeleteAuthor() error {
+	return ape.DeleteItem("Lyricist")
+}
+
+func (ape *APEv2) DeleteBPM() error {
+	return ape.DeleteItem("BPM")
+}
+
+func (ape *APEv2) DeleteCatalogNumber() error {
+	return ape.DeleteItem("Catalog")
+}
+
+func (ape *APEv2) DeleteCompilation() error {
+	return ape.DeleteItem("Compilation")
+}
+
+func (ape *APEv2) DeleteComposer() error {
+	return ape.DeleteItem("Composer")
+}
+
+func (ape *APEv2) DeleteConductor() error {
+	return ape.DeleteItem("Conductor")
+}
+
+func (ape *APEv2) DeleteCopyright() error {
+	return ape.DeleteItem("Copyright")
+}
+
+func (ape *APEv2) DeleteDescription() error {
+	return ape.DeleteItem("Subtitle")
+}
+
+func (ape *APEv2) DeleteDiscNumber() error {
+	return ape.DeleteItem("Disc")
+}
+
+func (ape *APEv2) DeleteEncodedBy() error {
+	return ape.DeleteItem("Encoded By")
+}
+
+func (ape *APEv2) DeleteTrackNumber() error {
+	return ape.DeleteItem("Track")
+}
+
+func (ape *APEv2) DeletePicture() error {
+	return ape.DeleteItem(apev2CoverArtFront)
+}
+
+func (ape *APEv2) DeleteLyrics(language string) error {
+	return ape.DeleteItem("Lyrics")
+}
+
+func (ape *APEv2) SaveFile(path string) error {
+	return saveFile(path, ape.Save, SaveOptions{})
+}
+
+// Save - write file data, APEv2 tag with header and footer, and ID3v1 tag
+func (ape *APEv2) Save(input io.WriteSeeker) error {
+	err := writeAudio(input, ape.Data, ape.audio)
+	if err != nil {
+		return err
+	}
+
+	items := new(bytes.Buffer)
+	for _, item := range ape.Items {
+		err = item.Write(items)
+		if err != nil {
+			return err
+		}
+	}
+	size := items.Len() + apev2HeaderSize
+
+	// header
+	err = writeAPEv2Header(input, size, len(ape.Items), apev2FlagHasHeader|apev2FlagIsHeader)
+	if err != nil {
+		return err
+	}
+
+	_, err = input.Write(items.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// footer
+	err = writeAPEv2Header(input, size, len(ape.Items), apev2FlagHasHeader)
+	if err != nil {
+		return err
+	}
+
+	_, err = input.Write(ape.ID3v1)
+	return err
+}
+
+// Write - write item
+// Size of value  4 bytes, little endian
+// Item flags     4 bytes, little endian
+// Key            ASCII 0x20-0x7E, terminated by zero byte
+// Value
+func (item *APEv2Item) Write(w io.Writer) error {
+	flags := uint32(item.Type) << 1
+	if item.ReadOnly {
+		flags |= apev2FlagReadOnly
+	}
+
+	header := make([]byte, 8, 8+len(item.Key)+1)
+	binary.LittleEndian.PutUint32(header[0:4], uint32(len(item.Value)))
+	binary.LittleEndian.PutUint32(header[4:8], flags)
+	header = append(header, item.Key...)
+	header = append(header, 0)
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(item.Value); err != nil {
+		return err
+	}
+	return nil
+}
+
+/*
+Header and footer:
+
+	Preamble     8 bytes "APETAGEX"
+	Version      4 bytes, 1000 or 2000
+	Tag size     4 bytes, items and footer, without header
+	Item count   4 bytes
+	Tag flags    4 bytes
+	Reserved     8 bytes, must be zero
+*/
+func writeAPEv2Header(w io.Writer, size int, count int, flags uint32) error {
+	header := make([]byte, apev2HeaderSize)
+	copy(header, apev2Preamble)
+	binary.LittleEndian.PutUint32(header[8:12], apev2Version)
+	binary.LittleEndian.PutUint32(header[12:16], uint32(size))
+	binary.LittleEndian.PutUint32(header[16:20], uint32(count))
+	binary.LittleEndian.PutUint32(header[20:24], flags)
+
+	_, err := w.Write(header)
+	return err
+}
+
+// apev2FooterOffset - footer position from the end of file
+func apev2FooterOffset(input io.ReadSeeker) (int64, bool) {
+	// footer at the end or before ID3v1
+	for _, offset := range []int64{apev2HeaderSize, apev2HeaderSize + id3v1SizeHeader} {
+		marker, err := seekAndReadString(input, -offset, io.SeekEnd, len(apev2Preamble))
+		if err == nil && marker == apev2Preamble {
+			if offset == apev2HeaderSize || checkID3v1(input) {
+				return offset, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func checkAPEv2(input io.ReadSeeker) bool {
+	_, ok := apev2FooterOffset(input)
+	return ok
+}
+
+// NewAPEv2 - empty tag after audio data
+func NewAPEv2(audio io.Reader) (*APEv2, error) {
+	region, err := readerRegion(audio)
+	if err != nil {
+		return nil, err
+	}
+	return &APEv2{
+		Version: apev2Version,
+		audio:   region,
+	}, nil
+}
+
+func ReadAPEv2(input io.ReadSeeker) (*APEv2, error) {
+	ape := APEv2{}
+
+	offset, ok := apev2FooterOffset(input)
+	if !ok {
+		return nil, ErrFileMarker
+	}
+
+	end, err := input.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	footerStart := end - offset
+	footer, err := seekAndRead(input, footerStart, io.SeekStart, apev2HeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	ape.Version = int(binary.LittleEndian.Uint32(footer[8:12]))
+	size := int64(binary.LittleEndian.Uint32(footer[12:16]))
+	count := int(binary.LittleEndian.Uint32(footer[16:20]))
+	flags := binary.LittleEndian.Uint32(footer[20:24])
+
+	itemsStart := footerStart + apev2HeaderSize - size
+	if size < apev2HeaderSize || itemsStart < 0 {
+		return nil, ErrIncorrectLength
+	}
+
+	tagStart := itemsStart
+	if flags&apev2FlagHasHeader != 0 {
+		tagStart -= apev2HeaderSize
+		if tagStart < 0 {
+			return nil, ErrIncorrectTag
+		}
+	}
+
+	// header and items
+	data, err := seekAndRead(input, tagStart, io.SeekStart, int(footerStart-tagStart))
+	if err != nil {
+		return nil, err
+	}
+	if tagStart != itemsStart && string(data[:len(apev2Preamble)]) != apev2Preamble {
+		return nil, ErrIncorrectTag
+	}
+
+	ape.Items, err = readAPEv2Items(data[itemsStart-tagStart:], count)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset > apev2HeaderSize {
+		ape.ID3v1, err = seekAndRead(input, footerStart+apev2HeaderSize, io.SeekStart, int(offset-apev2HeaderSize))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ape.audio, err = readRegion(input, 0, tagStart)
+	if err != nil {
+		return nil, err
+	}
+	return &ape, nil
+}
+
+func readAPEv2Items(data []byte, count int) ([]*APEv2Item, error) {
+	items := make([]*APEv2Item, 0, count)
+	for i := 0; i < count; i++ {
+		if len(data) < 9 {
+			return nil, ErrIncorrectLength
+		}
+		size := int(binary.LittleEndian.Uint32(data[0:4]))
+		flags := binary.LittleEndian.Uint32(data[4:8])
+
+		end := bytes.IndexByte(data[8:], 0)
+		if end < 0 {
+			return nil, ErrIncorrectTag
+		}
+		key := string(data[8 : 8+end])
+		data = data[8+end+1:]
+
+		if size > len(data) {
+			return nil, ErrIncorrectLength
+		}
+
+		items = append(items, &APEv2Item{
+			Key:      key,
+			Type:     APEv2ItemType(flags >> 1 & 3),
+			ReadOnly: flags&apev2FlagReadOnly != 0,
+			Value:    data[:size],
+		})
+		data = data[size:]
+	}
+	return items, nil
+}
+
+// GetItem - keys are case insensitive
+func (ape *APEv2) GetItem(key string) (*APEv2Item, error) {
+	for _, item := range ape.Items {
+		if strings.EqualFold(item.Key, key) {
+			return item, nil
+		}
+	}
+	return nil, ErrTagNotFound
+}
+
+// SetItem - replace item with the same key or append a new one
+func (ape *APEv2) SetItem(item *APEv2Item) error {
+	if len(item.Key) < 2 || len(item.Key) > 255 {
+		return ErrIncorrectLength
+	}
+	for i := range ape.Items {
+		if strings.EqualFold(ape.Items[i].Key, item.Key) {
+			ape.Items[i] = item
+			return nil
+		}
+	}
+	ape.Items = append(ape.Items, item)
+	return nil
+}
+
+func (ape *APEv2) DeleteItem(key string) error {
+	for i := range ape.Items {
+		if strings.EqualFold(ape.Items[i].Key, key) {
+			ape.Items = append(ape.Items[:i], ape.Items[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (ape *APEv2) GetText(key string) (string, error) {
+	item, err := ape.GetItem(key)
+	if err != nil {
+		return "", err
+	}
+	if item.Type != APEv2ItemText {
+		return "", ErrIncorrectTag
+	}
+	return string(item.Value), nil
+}
+
+func (ape *APEv2) SetText(key string, value string) error {
+	return ape.SetItem(&APEv2Item{
+		Key:   key,
+		Type:  APEv2ItemText,
+		Value: []byte(value),
+	})
+}
+
+func (ape *APEv2) GetInt(key string) (int, error) {
+	value, err := ape.GetText(key)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(value)
+}
+
+// GetNumberTotal - value in format 'number/total' or 'number'
+func (ape *APEv2) GetNumberTotal(key string) (int, int, error) {
+	value, err := ape.GetText(key)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return parseNumberTotal(value)
+}
@@ -0,0 +1,189 @@
+package tag
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// fileRegion - audio data as byte range of the source. It isn't read into memory,
+// Save copies it from the source, so the source must stay open until then
+type fileRegion struct {
+	source io.ReaderAt
+	offset int64
+	size   int64
+}
+
+// readRegion - size bytes of input from offset, -1 for the rest of input.
+// Input without io.ReaderAt is read into memory
+func readRegion(input io.ReadSeeker, offset int64, size int64) (*fileRegion, error) {
+	end, err := input.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if size < 0 {
+		size = end - offset
+	}
+	if offset < 0 || size < 0 || offset+size > end {
+		return nil, ErrIncorrectLength
+	}
+	if section, ok := input.(*inputSection); ok && section.input != nil {
+		return readRegion(section.input, section.offset+offset, size)
+	}
+
+	if file, ok := input.(*namedFile); ok {
+		return &fileRegion{source: file.source, offset: offset, size: size}, nil
+	}
+	if source, ok := input.(io.ReaderAt); ok {
+		return &fileRegion{source: source, offset: offset, size: size}, nil
+	}
+
+	_, err = input.Seek(offset, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(input, size))
+	if err != nil {
+		return nil, err
+	}
+	return &fileRegion{source: bytes.NewReader(data), size: int64(len(data))}, nil
+}
+
+// inputSection - byte range of input read as a separate file, its regions are regions of input
+type inputSection struct {
+	*io.SectionReader
+	input  io.ReadSeeker // nil if the section is read into memory
+	offset int64
+}
+
+// newInputSection - size bytes of input from offset. Input without io.ReaderAt is read into memory
+func newInputSection(input io.ReadSeeker, offset int64, size int64) (*inputSection, error) {
+	if source, ok := input.(io.ReaderAt); ok {
+		return &inputSection{
+			SectionReader: io.NewSectionReader(source, offset, size),
+			input:         input,
+			offset:        offset,
+		}, nil
+	}
+
+	region, err := readRegion(input, offset, size)
+	if err != nil {
+		return nil, err
+	}
+	return &inputSection{SectionReader: io.NewSectionReader(region.source, region.offset, region.size)}, nil
+}
+
+// namedFile - file opened by ReadFile, its regions read from source
+type namedFile struct {
+	*os.File
+	source *fileSource
+}
+
+// fileSource - file opened by ReadFile or saveFile, it's kept open while its regions are used
+// and closed when they are unreachable, so the file replaced by SaveFile is still read.
+// Reads fail with ErrFileChanged if size of the file differs from the one at reading,
+// e.g. audio is moved by SaveInPlace of another Metadata
+type fileSource struct {
+	file *os.File
+	size int64
+}
+
+func newFileSource(file *os.File) (*fileSource, error) {
+	source := &fileSource{file: file}
+	err := source.update()
+	if err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// update - size of the file written by this Metadata
+func (source *fileSource) update() error {
+	info, err := source.file.Stat()
+	if err != nil {
+		return err
+	}
+	source.size = info.Size()
+	return nil
+}
+
+func (source *fileSource) ReadAt(p []byte, offset int64) (int, error) {
+	info, err := source.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if info.Size() != source.size {
+		return 0, ErrFileChanged
+	}
+	return source.file.ReadAt(p, offset)
+}
+
+// readerRegion - rest of audio from current position
+func readerRegion(audio io.Reader) (*fileRegion, error) {
+	if input, ok := audio.(io.ReadSeeker); ok {
+		offset, err := input.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		return readRegion(input, offset, -1)
+	}
+
+	data, err := ioutil.ReadAll(audio)
+	if err != nil {
+		return nil, err
+	}
+	return &fileRegion{source: bytes.NewReader(data), size: int64(len(data))}, nil
+}
+
+// audioSection - audio data set by user, or region of the source
+func audioSection(data []byte, region *fileRegion) *io.SectionReader {
+	if data != nil || region == nil {
+		return io.NewSectionReader(bytes.NewReader(data), 0, int64(len(data)))
+	}
+	return io.NewSectionReader(region.source, region.offset, region.size)
+}
+
+// readSection - all data of section, nil on error
+func readSection(section *io.SectionReader) []byte {
+	data := make([]byte, section.Size())
+	n, _ := section.ReadAt(data, 0)
+	if n < len(data) {
+		return nil
+	}
+	return data
+}
+
+// readSectionAt - size bytes of section from offset, nil if they are out of section
+func readSectionAt(section *io.SectionReader, offset int64, size int64) []byte {
+	if offset < 0 || size < 0 || offset+size > section.Size() {
+		return nil
+	}
+	data := make([]byte, size)
+	n, _ := section.ReadAt(data, offset)
+	if n < len(data) {
+		return nil
+	}
+	return data
+}
+
+// writeSection - copy section to output
+func writeSection(output io.Writer, section *io.SectionReader) error {
+	_, err := io.CopyBuffer(output, section, make([]byte, audioCopyBufferSize))
+	return err
+}
+
+// sameFile - region is a part of the file
+func (region *fileRegion) sameFile(info os.FileInfo) bool {
+	var sourceInfo os.FileInfo
+	var err error
+	switch source := region.source.(type) {
+	case *os.File:
+		sourceInfo, err = source.Stat()
+	case *fileSource:
+		sourceInfo, err = source.file.Stat()
+	default:
+		return false
+	}
+	return err == nil && os.SameFile(info, sourceInfo)
+}
@@ -0,0 +1,900 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+	"strconv"
+	"time"
+)
+
+// FileTag - tag found by ReadAll and its byte range in the file.
+// Native tag of a container covers the whole container
+type FileTag struct {
+	Version  Version
+	Offset   int64
+	Size     int64
+	Metadata Metadata    // nil for Lyrics3 tags, they are kept as is
+	region   *fileRegion // tag or audio without Metadata, read from the source on demand
+}
+
+// Composite - all tags of a file. Getters return the first value in Priority order,
+// setters and deleters change Targets tags, tags without the field are skipped
+type Composite struct {
+	Tags     []*FileTag // file order
+	Priority []Version  // read order, other tags follow in file order
+	Targets  []Version  // written tags, all tags if empty
+	parts    []*FileTag // tags and audio in file order
+}
+
+func (composite *Composite) GetAllTagNames() []string {
+	var result []string
+	found := map[string]bool{}
+	for _, metadata := range composite.readOrder() {
+		for _, name := range metadata.GetAllTagNames() {
+			if !found[name] {
+				found[name] = true
+				result = append(result, name)
+			}
+		}
+	}
+	return result
+}
+
+// GetVersion - version of the first tag in read order
+func (composite *Composite) GetVersion() Version {
+	for _, fileTag := range composite.orderedTags() {
+		return fileTag.Version
+	}
+	return VersionUndefined
+}
+
+// GetFileData - audio between tags or file data of the container
+func (composite *Composite) GetFileData() []byte {
+	for _, part := range composite.parts {
+		if part.Version == VersionUndefined {
+			return readSection(audioSection(nil, part.region))
+		}
+		if isContainerVersion(part.Version) {
+			return part.Metadata.GetFileData()
+		}
+	}
+	return nil
+}
+
+// AudioReader - audio between tags or file data of the container
+func (composite *Composite) AudioReader() io.Reader {
+	for _, part := range composite.parts {
+		if part.Version == VersionUndefined {
+			return audioSection(nil, part.region)
+		}
+		if isContainerVersion(part.Version) {
+			return part.Metadata.AudioReader()
+		}
+	}
+	return bytes.NewReader(nil)
+}
+
+func (composite *Composite) GetTitle() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetTitle()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetArtist() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetArtist()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetAlbum() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetAlbum()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetYear() (int, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetYear()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return 0, ErrTagNotFound
+}
+
+func (composite *Composite) GetComment() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetComment()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetGenre() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetGenre()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetAlbumArtist() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetAlbumArtist()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetDate() (time.Time, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetDate()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return time.Now(), ErrTagNotFound
+}
+
+func (composite *Composite) GetArranger() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetArranger()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetAuthor() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetAuthor()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetBPM() (int, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetBPM()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return 0, ErrTagNotFound
+}
+
+func (composite *Composite) GetCatalogNumber() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetCatalogNumber()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetCompilation() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetCompilation()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetComposer() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetComposer()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetConductor() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetConductor()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetCopyright() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetCopyright()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetDescription() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetDescription()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetDiscNumber() (int, int, error) {
+	for _, metadata := range composite.readOrder() {
+		number, total, err := metadata.GetDiscNumber()
+		if err == nil {
+			return number, total, nil
+		}
+	}
+	return 0, 0, ErrTagNotFound
+}
+
+func (composite *Composite) GetEncodedBy() (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetEncodedBy()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) GetTrackNumber() (int, int, error) {
+	for _, metadata := range composite.readOrder() {
+		number, total, err := metadata.GetTrackNumber()
+		if err == nil {
+			return number, total, nil
+		}
+	}
+	return 0, 0, ErrTagNotFound
+}
+
+func (composite *Composite) GetPicture() (image.Image, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetPicture()
+		if err == nil {
+			return value, nil
+		}
+	}
+	return nil, ErrTagNotFound
+}
+
+func (composite *Composite) GetLyrics(language string) (string, error) {
+	for _, metadata := range composite.readOrder() {
+		value, err := metadata.GetLyrics(language)
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (composite *Composite) SetTitle(title string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetTitle(title)
+	})
+}
+
+func (composite *Composite) SetArtist(artist string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetArtist(artist)
+	})
+}
+
+func (composite *Composite) SetAlbum(album string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetAlbum(album)
+	})
+}
+
+func (composite *Composite) SetYear(year int) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetYear(year)
+	})
+}
+
+func (composite *Composite) SetComment(comment string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetComment(comment)
+	})
+}
+
+func (composite *Composite) SetGenre(genre string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetGenre(genre)
+	})
+}
+
+func (composite *Composite) SetAlbumArtist(albumArtist string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetAlbumArtist(albumArtist)
+	})
+}
+
+func (composite *Composite) SetDate(date time.Time) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetDate(date)
+	})
+}
+
+func (composite *Composite) SetArranger(arranger string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetArranger(arranger)
+	})
+}
+
+func (composite *Composite) SetAuthor(author string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetAuthor(author)
+	})
+}
+
+func (composite *Composite) SetBPM(bmp int) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetBPM(bmp)
+	})
+}
+
+func (composite *Composite) SetCatalogNumber(catalogNumber string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetCatalogNumber(catalogNumber)
+	})
+}
+
+func (composite *Composite) SetCompilation(compilation string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetCompilation(compilation)
+	})
+}
+
+func (composite *Composite) SetComposer(composer string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetComposer(composer)
+	})
+}
+
+func (composite *Composite) SetConductor(conductor string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetConductor(conductor)
+	})
+}
+
+func (composite *Composite) SetCopyright(copyright string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetCopyright(copyright)
+	})
+}
+
+func (composite *Composite) SetDescription(description string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetDescription(description)
+	})
+}
+
+func (composite *Composite) SetDiscNumber(number int, total int) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetDiscNumber(number, total)
+	})
+}
+
+func (composite *Composite) SetEncodedBy(encodedBy string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetEncodedBy(encodedBy)
+	})
+}
+
+func (composite *Composite) SetTrackNumber(number int, total int) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetTrackNumber(number, total)
+	})
+}
+
+func (composite *Composite) SetPicture(picture image.Image) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetPicture(picture)
+	})
+}
+
+func (composite *Composite) SetLyrics(language string, lyrics string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.SetLyrics(language, lyrics)
+	})
+}
+
+func (composite *Composite) DeleteAll() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteAll()
+	})
+}
+
+func (composite *Composite) DeleteTitle() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteTitle()
+	})
+}
+
+func (composite *Composite) DeleteArtist() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteArtist()
+	})
+}
+
+func (composite *Composite) DeleteAlbum() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteAlbum()
+	})
+}
+
+func (composite *Composite) DeleteYear() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteYear()
+	})
+}
+
+func (composite *Composite) DeleteComment() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteComment()
+	})
+}
+
+func (composite *Composite) DeleteGenre() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteGenre()
+	})
+}
+
+func (composite *Composite) DeleteAlbumArtist() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteAlbumArtist()
+	})
+}
+
+func (composite *Composite) DeleteDate() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteDate()
+	})
+}
+
+func (composite *Composite) DeleteArranger() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteArranger()
+	})
+}
+
+func (composite *Composite) DeleteAuthor() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteAuthor()
+	})
+}
+
+func (composite *Composite) DeleteBPM() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteBPM()
+	})
+}
+
+func (composite *Composite) DeleteCatalogNumber() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteCatalogNumber()
+	})
+}
+
+func (composite *Composite) DeleteCompilation() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteCompilation()
+	})
+}
+
+func (composite *Composite) DeleteComposer() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteComposer()
+	})
+}
+
+func (composite *Composite) DeleteConductor() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteConductor()
+	})
+}
+
+func (composite *Composite) DeleteCopyright() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteCopyright()
+	})
+}
+
+func (composite *Composite) DeleteDescription() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteDescription()
+	})
+}
+
+func (composite *Composite) DeleteDiscNumber() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteDiscNumber()
+	})
+}
+
+func (composite *Composite) DeleteEncodedBy() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteEncodedBy()
+	})
+}
+
+func (composite *Composite) DeleteTrackNumber() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteTrackNumber()
+	})
+}
+
+func (composite *Composite) DeletePicture() error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeletePicture()
+	})
+}
+
+func (composite *Composite) DeleteLyrics(language string) error {
+	return composite.write(func(metadata Metadata) error {
+		return metadata.DeleteLyrics(language)
+	})
+}
+
+func (composite *Composite) SaveFile(path string) error {
+	return saveFile(path, composite.Save, SaveOptions{})
+}
+
+// Save - write all tags and audio in file order, byte ranges of tags are updated
+func (composite *Composite) Save(input io.WriteSeeker) error {
+	offset, err := input.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	start := offset
+	for _, part := range composite.parts {
+		if part.Metadata != nil {
+			err = part.Metadata.Save(input)
+		} else {
+			err = writeAudio(input, nil, part.region)
+		}
+		if err != nil {
+			return err
+		}
+
+		end, err := input.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		part.Offset = offset - start
+		part.Size = end - offset
+		offset = end
+	}
+	return nil
+}
+
+// ReadAll - leading ID3v2 tags, native tag of the container, and trailing APEv2,
+// ID3v2.4, Lyrics3 and ID3v1 tags. Each tag is read from its own byte range of input
+func ReadAll(input io.ReadSeeker) (*Composite, error) {
+	size, err := input.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	var leading []*FileTag
+	var start int64
+	for {
+		header, err := seekAndRead(input, start, io.SeekStart, 10)
+		if err != nil || string(header[0:3]) != id3MarkerValue {
+			break
+		}
+		tagSize := 10 + int64(ByteToIntSynchsafe(header[6:10]))
+		if header[3] == 4 && id3v24Flags(header[5]).HasFooter() {
+			tagSize += 10
+		}
+		if start+tagSize > size {
+			return nil, ErrIncorrectLength
+		}
+		fileTag, err := readFileTag(input, start, start+tagSize)
+		if err != nil {
+			return nil, err
+		}
+		leading = append(leading, fileTag)
+		start += tagSize
+	}
+
+	var trailing []*FileTag
+	end := size
+	if end-start >= id3v1SizeHeader {
+		marker, err := seekAndRead(input, end-id3v1SizeHeader, io.SeekStart, id3v1SizeType)
+		if err == nil && string(marker) == id3MarkerName {
+			fileTag, err := readFileTag(input, end-id3v1SizeHeader, end)
+			if err != nil {
+				return nil, err
+			}
+			trailing = append(trailing, fileTag)
+			end -= id3v1SizeHeader
+		}
+	}
+	for {
+		section, err := newInputSection(input, start, end-start)
+		if err != nil {
+			return nil, err
+		}
+		tagStart, version := trailingTagStart(section.SectionReader)
+		if version == VersionUndefined {
+			break
+		}
+		fileTag, err := readFileTag(input, start+tagStart, end)
+		if err != nil {
+			return nil, err
+		}
+		trailing = append([]*FileTag{fileTag}, trailing...)
+		end = start + tagStart
+	}
+
+	// container with native tag or audio
+	middle := &FileTag{
+		Offset: start,
+		Size:   end - start,
+	}
+	reader, err := newInputSection(input, start, end-start)
+	if err != nil {
+		return nil, err
+	}
+	container, offset := detectContainer(reader)
+	if version := containerVersion(reader, container, offset); offset == 0 && version != VersionUndefined {
+		middle.Version = version
+		middle.Metadata, err = readVersion(reader, version)
+	} else {
+		middle.region, err = readRegion(input, start, end-start)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	composite := Composite{}
+	composite.parts = append(composite.parts, leading...)
+	composite.parts = append(composite.parts, middle)
+	composite.parts = append(composite.parts, trailing...)
+	for _, part := range composite.parts {
+		if part.Version != VersionUndefined {
+			composite.Tags = append(composite.Tags, part)
+		}
+	}
+	return &composite, nil
+}
+
+// readFileTag - tag which takes exactly bytes from start to end of input
+func readFileTag(input io.ReadSeeker, start int64, end int64) (*FileTag, error) {
+	fileTag := FileTag{
+		Offset: start,
+		Size:   end - start,
+	}
+
+	reader, err := newInputSection(input, start, end-start)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case checkID3v24(reader):
+		fileTag.Version = VersionID3v24
+		fileTag.Metadata, err = ReadID3v24(reader)
+	case checkID3v23(reader):
+		fileTag.Version = VersionID3v23
+		fileTag.Metadata, err = ReadID3v23(reader)
+	case checkID3v22(reader):
+		fileTag.Version = VersionID3v22
+		fileTag.Metadata, err = ReadID3v22(reader)
+	case checkAPEv2(reader):
+		fileTag.Version = VersionAPEv2
+		fileTag.Metadata, err = ReadAPEv2(reader)
+	case checkID3v1(reader):
+		fileTag.Version = VersionID3v1
+		fileTag.Metadata, err = ReadID3v1(reader)
+	default:
+		fileTag.Version = VersionLyrics3
+		fileTag.region, err = readRegion(input, start, end-start)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &fileTag, nil
+}
+
+// trailingTagStart - APEv2, ID3v2.4 with footer or Lyrics3 tag at the end of data
+func trailingTagStart(data *io.SectionReader) (int64, Version) {
+	end := data.Size()
+
+	// ID3v2.4 footer
+	footer := readSectionAt(data, end-10, 10)
+	if footer != nil && string(footer[0:3]) == id3v24FooterMarker && footer[3] == 4 {
+		start := end - 20 - int64(ByteToIntSynchsafe(footer[6:10]))
+		if string(readSectionAt(data, start, 3)) == id3MarkerValue {
+			return start, VersionID3v24
+		}
+	}
+
+	// APEv2 footer
+	footer = readSectionAt(data, end-apev2HeaderSize, apev2HeaderSize)
+	if footer != nil && string(footer[:len(apev2Preamble)]) == apev2Preamble {
+		start := end - int64(binary.LittleEndian.Uint32(footer[12:16]))
+		if binary.LittleEndian.Uint32(footer[20:24])&apev2FlagHasHeader != 0 {
+			start -= apev2HeaderSize
+		}
+		if start >= 0 && start < end-apev2HeaderSize {
+			return start, VersionAPEv2
+		}
+	}
+
+	// Lyrics3 v2, 6 digits size before the end marker
+	suffix := int64(len(lyrics3v2End) + 6)
+	footer = readSectionAt(data, end-suffix, suffix)
+	if footer != nil && string(footer[6:]) == lyrics3v2End {
+		size, err := strconv.Atoi(string(footer[:6]))
+		start := end - suffix - int64(size)
+		if err == nil && string(readSectionAt(data, start, int64(len(lyrics3Begin)))) == lyrics3Begin {
+			return start, VersionLyrics3
+		}
+	}
+
+	// Lyrics3 v1
+	if string(readSectionAt(data, end-int64(len(lyrics3v1End)), int64(len(lyrics3v1End)))) == lyrics3v1End {
+		from := end - lyrics3v1MaxSize
+		if from < 0 {
+			from = 0
+		}
+		start := bytes.LastIndex(readSectionAt(data, from, end-from), []byte(lyrics3Begin))
+		if start >= 0 {
+			return from + int64(start), VersionLyrics3
+		}
+	}
+
+	return 0, VersionUndefined
+}
+
+// readOrder - Metadata of tags in read order
+func (composite *Composite) readOrder() []Metadata {
+	var result []Metadata
+	for _, fileTag := range composite.orderedTags() {
+		result = append(result, fileTag.Metadata)
+	}
+	return result
+}
+
+// orderedTags - tags with Metadata, Priority versions first
+func (composite *Composite) orderedTags() []*FileTag {
+	var result []*FileTag
+	for _, version := range composite.Priority {
+		for _, fileTag := range composite.Tags {
+			if fileTag.Version == version && fileTag.Metadata != nil {
+				result = append(result, fileTag)
+			}
+		}
+	}
+	for _, fileTag := range composite.Tags {
+		if fileTag.Metadata != nil && !containsVersion(composite.Priority, fileTag.Version) {
+			result = append(result, fileTag)
+		}
+	}
+	return result
+}
+
+// write - change Targets tags, ErrUnsupportedTag of a tag is skipped.
+// Values too long for ID3v1 are truncated, so it isn't left stale when other tags are changed
+func (composite *Composite) write(change func(metadata Metadata) error) error {
+	for _, fileTag := range composite.Tags {
+		if fileTag.Metadata == nil {
+			continue
+		}
+		if len(composite.Targets) > 0 && !containsVersion(composite.Targets, fileTag.Version) {
+			continue
+		}
+		metadata := fileTag.Metadata
+		if id3v1, ok := metadata.(*ID3v1); ok {
+			metadata = truncatedID3v1{id3v1}
+		}
+		err := change(metadata)
+		if err != nil && err != ErrUnsupportedTag {
+			return err
+		}
+	}
+	return nil
+}
+
+// truncatedID3v1 - ID3v1 tag written by Composite. Text is truncated to the field length,
+// genre not in the list of genres is deleted
+type truncatedID3v1 struct {
+	*ID3v1
+}
+
+func (id3v1 truncatedID3v1) SetTitle(title string) error {
+	return id3v1.ID3v1.SetTitle(truncateString(title, 30))
+}
+
+func (id3v1 truncatedID3v1) SetArtist(artist string) error {
+	return id3v1.ID3v1.SetArtist(truncateString(artist, 30))
+}
+
+func (id3v1 truncatedID3v1) SetAlbum(album string) error {
+	return id3v1.ID3v1.SetAlbum(truncateString(album, 30))
+}
+
+func (id3v1 truncatedID3v1) SetComment(comment string) error {
+	// track number takes 2 last bytes
+	if id3v1.ZeroByte == 0 {
+		return id3v1.ID3v1.SetComment(truncateString(comment, 28))
+	}
+	return id3v1.ID3v1.SetComment(truncateString(comment, 30))
+}
+
+func (id3v1 truncatedID3v1) SetGenre(genre string) error {
+	err := id3v1.ID3v1.SetGenre(genre)
+	if err == ErrNotFoundGenre {
+		return id3v1.DeleteGenre()
+	}
+	return err
+}
+
+func (id3v1 truncatedID3v1) SetTrackNumber(number int, total int) error {
+	id3v1.Comment = truncateString(id3v1.Comment, 28)
+	return id3v1.ID3v1.SetTrackNumber(number, total)
+}
+
+// truncateString - value cut to size bytes at a character boundary
+func truncateString(value string, size int) string {
+	if len(value) <= size {
+		return value
+	}
+	end := 0
+	for i := range value {
+		if i > size {
+			break
+		}
+		end = i
+	}
+	return value[:end]
+}
+
+func containsVersion(versions []Version, version Version) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// isContainerVersion - tag is a part of the container format
+func isContainerVersion(version Version) bool {
+	switch version {
+	case VersionMP4, VersionFLAC, VersionOggVorbis, VersionOpus, VersionWAV, VersionAIFF, VersionMatroska, VersionDSF:
+		return true
+	}
+	return false
+}
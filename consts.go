@@ -9,6 +9,28 @@ const (
 	VersionID3v24    Version = 4
 	VersionMP4       Version = 5
 	VersionFLAC      Version = 6
+	VersionOggVorbis Version = 7
+	VersionOpus      Version = 8
+	VersionAPEv2     Version = 9
+	VersionWAV       Version = 10
+	VersionAIFF      Version = 11
+	VersionMatroska  Version = 12
+	VersionDSF       Version = 13
+	VersionLyrics3   Version = 14
+
+	ContainerUnknown  Container = 0
+	ContainerMPEG     Container = 1 // MPEG audio frames, mp3
+	ContainerMP4      Container = 2
+	ContainerFLAC     Container = 3
+	ContainerOgg      Container = 4
+	ContainerWAV      Container = 5
+	ContainerAIFF     Container = 6
+	ContainerMatroska Container = 7
+	ContainerDSF      Container = 8
+
+	audioCopyBufferSize = 1 << 20 // buffer for copying audio data from the source on save
+	DefaultPadding      = 1024    // padding written by SaveInPlace if tag doesn't fit into the file
+	backupSuffix        = ".bak"  // backup of the file replaced by SaveFileWithOptions
 
 	// id3 consts.
 	id3v1SizeHeader       = 128    // ID3v1 constant header size
@@ -19,17 +41,152 @@ const (
 	id3MarkerValue        = "ID3"  // marker tag value for id3 format
 	id3v2FrameTXXX        = "TXXX" // TXXX frame name for id3v2 format
 	id3v22FrameHeaderSize = 6      // id3v22 frame header size
+	id3v2FrameHeaderSize  = 10     // id3v23 and id3v24 frame header size
+	id3v24FooterMarker    = "3DI"  // marker of id3v24 footer, copy of header at the end of tag
+	id3v24FrameSEEK       = "SEEK" // id3v24 frame with offset from the end of tag to the next tag
+
+	// id3v2 extended header flags.
+	id3v23ExtendedCRC          = 0x80 // id3v23 extended header has CRC-32 of frames
+	id3v24ExtendedUpdate       = 0x40 // id3v24 tag is an update
+	id3v24ExtendedCRC          = 0x20 // id3v24 extended header has CRC-32 of frames
+	id3v24ExtendedRestrictions = 0x10 // id3v24 extended header has tag restrictions
+
+	// id3v2 frame flags.
+	id3v23FrameCompression = 0x0080 // id3v23 frame is compressed with zlib, decompressed size follows header
+	id3v23FrameEncryption  = 0x0040 // id3v23 frame is encrypted, method byte follows header
+	id3v23FrameGrouping    = 0x0020 // id3v23 frame has group identifier byte
+	id3v24FrameStatus      = 0x7000 // id3v24 frame status flags, id3v23 status flags are one bit higher
+	id3v24FrameGrouping    = 0x0040 // id3v24 frame has group identifier byte
+	id3v24FrameCompression = 0x0008 // id3v24 frame is compressed with zlib
+	id3v24FrameEncryption  = 0x0004 // id3v24 frame is encrypted, method byte follows group identifier
+	id3v24FrameUnsync      = 0x0002 // id3v24 frame is unsynchronised
+	id3v24FrameDataLength  = 0x0001 // id3v24 frame has synchsafe data length indicator
 
 	// flac consts.
 	FLACIdentifier = "fLaC" // flac format identifier
 
-	// util consts.
-	encodingUTF8    string = "UTF-8"
-	encodingUTF16   string = "UTF-16"
-	encodingUTF16BE string = "UTF-16BE"
+	// ogg consts.
+	OggIdentifier              = "OggS"                   // ogg page capture pattern
+	vorbisIdentifier           = "vorbis"                 // vorbis header packet identifier
+	vorbisIdentificationHeader = 1                        // vorbis identification header packet type
+	vorbisCommentHeader        = 3                        // vorbis comment header packet type
+	vorbisSetupHeader          = 5                        // vorbis setup header packet type
+	vorbisPictureTag           = "METADATA_BLOCK_PICTURE" // vorbis comment with base64 flac picture block
+	vorbisLyricsTag            = "LYRICS"                 // vorbis comment with unsynchronised lyrics
+	vorbisUnsyncedLyricsTag    = "UNSYNCEDLYRICS"         // lyrics comment written by some taggers
+	opusHeadIdentifier         = "OpusHead"               // opus identification header packet
+	opusTagsIdentifier         = "OpusTags"               // opus comment header packet
+	opusTrackGainTag           = "R128_TRACK_GAIN"        // opus track gain comment
+	opusAlbumGainTag           = "R128_ALBUM_GAIN"        // opus album gain comment
+
+	// apev2 consts.
+	apev2Preamble      = "APETAGEX"          // apev2 header and footer preamble
+	apev2Version       = 2000                // apev2 version written on save
+	apev2HeaderSize    = 32                  // apev2 header and footer size
+	apev2FlagHasHeader = 1 << 31             // tag contains a header
+	apev2FlagIsHeader  = 1 << 29             // this is the header, not the footer
+	apev2FlagReadOnly  = 1                   // item is read only
+	apev2CoverArtFront = "Cover Art (Front)" // apev2 front cover item
+
+	// wav consts.
+	wavRIFFIdentifier = "RIFF" // riff file identifier
+	wavWAVEIdentifier = "WAVE" // riff form type for wav
+	wavDataChunk      = "data" // audio data chunk
+	wavListChunk      = "LIST" // list chunk
+	wavInfoIdentifier = "INFO" // list type of info chunk
+	wavID3Chunk       = "id3 " // id3v2 tag chunk
+
+	// aiff consts.
+	aiffFORMIdentifier  = "FORM" // aiff file identifier
+	aiffFormType        = "AIFF" // form type of aiff
+	aifcFormType        = "AIFC" // form type of compressed aiff
+	aiffSoundDataChunk  = "SSND" // sound data chunk
+	aiffNameChunk       = "NAME" // name text chunk
+	aiffAuthorChunk     = "AUTH" // author text chunk
+	aiffAnnotationChunk = "ANNO" // annotation text chunk
+	aiffCopyrightChunk  = "(c) " // copyright text chunk
+	aiffID3Chunk        = "ID3 " // id3v2 tag chunk
+
+	// matroska consts.
+	MatroskaTargetTrack     = 30         // TargetTypeValue of track, song or chapter tags
+	MatroskaTargetAlbum     = 50         // TargetTypeValue of album, movie or episode tags
+	MatroskaTargetVolume    = 60         // TargetTypeValue of edition, volume or season tags
+	matroskaEBML            = 0x1A45DFA3 // EBML header
+	matroskaDocType         = 0x4282     // EBML header document type
+	matroskaSegment         = 0x18538067 // segment with all top level elements
+	matroskaSeekHead        = 0x114D9B74 // index of top level elements
+	matroskaSeek            = 0x4DBB     // SeekHead entry
+	matroskaSeekID          = 0x53AB     // id of indexed element
+	matroskaSeekPosition    = 0x53AC     // position of indexed element from segment data
+	matroskaCluster         = 0x1F43B675 // audio and video blocks
+	matroskaAttachments     = 0x1941A469 // attached files
+	matroskaAttachedFile    = 0x61A7     // attached file
+	matroskaFileDesc        = 0x467E     // attached file description
+	matroskaFileName        = 0x466E     // attached file name
+	matroskaFileMediaType   = 0x4660     // attached file media type
+	matroskaFileData        = 0x465C     // attached file data
+	matroskaFileUID         = 0x46AE     // attached file unique id
+	matroskaTags            = 0x1254C367 // tags element
+	matroskaTag             = 0x7373     // tag with targets and simple tags
+	matroskaTargets         = 0x63C0     // tag targets
+	matroskaTargetValue     = 0x68CA     // TargetTypeValue, 50 by default
+	matroskaTargetType      = 0x63CA     // TargetType name
+	matroskaSimpleTag       = 0x67C8     // name and value
+	matroskaTagName         = 0x45A3     // simple tag name
+	matroskaTagLanguage     = 0x447A     // simple tag language, und by default
+	matroskaTagDefault      = 0x4484     // simple tag default flag, 1 by default
+	matroskaTagString       = 0x4487     // simple tag string value
+	matroskaTagBinary       = 0x4485     // simple tag binary value
+	matroskaVoid            = 0xEC       // padding element
+	matroskaCRC32           = 0xBF       // checksum of parent element
+	matroskaPadding         = 1024       // void size after element moved to the end of segment
+	matroskaDocTypeMatroska = "matroska" // matroska document type
+	matroskaDocTypeWebM     = "webm"     // webm document type
+
+	// dsf consts.
+	dsfIdentifier      = "DSD " // dsf file identifier
+	dsfHeaderSize      = 28     // DSD chunk size
+	dsfChunkHeaderSize = 12     // chunk id and 8 bytes size
+	dsfDataChunk       = "data" // sample data chunk
+
+	// lyrics3 consts.
+	lyrics3Begin     = "LYRICSBEGIN" // lyrics3 tag start
+	lyrics3v1End     = "LYRICSEND"   // lyrics3 v1 tag end
+	lyrics3v2End     = "LYRICS200"   // lyrics3 v2 tag end after 6 digits size
+	lyrics3v1MaxSize = 5100 + 11 + 9 // lyrics3 v1 maximum size with markers
+
+	// text encodings of ID3v2 frames.
+	EncodingISO88591 string = "ISO-8859-1" // $00
+	EncodingUTF16    string = "UTF-16"     // $01, with BOM
+	EncodingUTF16BE  string = "UTF-16BE"   // $02, ID3v2.4 only
+	EncodingUTF8     string = "UTF-8"      // $03, ID3v2.4 only
 
 	// mime types.
 	mimeImageJPEG = "image/jpeg"
 	mimeImagePNG  = "image/png"
+	mimeImageBMP  = "image/bmp"
 	mimeImageLink = "-->"
+
+	// picture types of ID3v2 APIC frame and FLAC PICTURE block.
+	PictureTypeOther              = 0  // other
+	PictureTypeFileIcon           = 1  // 32x32 pixels file icon, PNG only
+	PictureTypeOtherFileIcon      = 2  // other file icon
+	PictureTypeFrontCover         = 3  // cover (front)
+	PictureTypeBackCover          = 4  // cover (back)
+	PictureTypeLeafletPage        = 5  // leaflet page
+	PictureTypeMedia              = 6  // media, e.g. label side of CD
+	PictureTypeLeadArtist         = 7  // lead artist, lead performer, soloist
+	PictureTypeArtist             = 8  // artist, performer
+	PictureTypeConductor          = 9  // conductor
+	PictureTypeBand               = 10 // band, orchestra
+	PictureTypeComposer           = 11 // composer
+	PictureTypeLyricist           = 12 // lyricist, text writer
+	PictureTypeRecordingLocation  = 13 // recording location
+	PictureTypeDuringRecording    = 14 // during recording
+	PictureTypeDuringPerformance  = 15 // during performance
+	PictureTypeScreenCapture      = 16 // movie, video screen capture
+	PictureTypeBrightColouredFish = 17 // a bright coloured fish
+	PictureTypeIllustration       = 18 // illustration
+	PictureTypeBandLogo           = 19 // band, artist logotype
+	PictureTypePublisherLogo      = 20 // publisher, studio logotype
 )
@@ -0,0 +1,116 @@
+package tag
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+type Container int
+
+var containersMap = map[Container]string{
+	ContainerUnknown:  "",
+	ContainerMPEG:     "mpeg",
+	ContainerMP4:      "mp4",
+	ContainerFLAC:     "flac",
+	ContainerOgg:      "ogg",
+	ContainerWAV:      "wav",
+	ContainerAIFF:     "aiff",
+	ContainerMatroska: "matroska",
+	ContainerDSF:      "dsf",
+}
+
+func (c Container) String() string {
+	return containersMap[c]
+}
+
+// DetectContainer - container format by magic bytes after leading ID3v2 tags.
+// Tag formats are detected by CheckVersion
+func DetectContainer(input io.ReadSeeker) Container {
+	container, _ := detectContainer(input)
+	return container
+}
+
+// detectContainer - container and its offset after ID3v2 tags.
+// Data after ID3v2 tag without known magic is MPEG audio
+func detectContainer(input io.ReadSeeker) (Container, int64) {
+	var offset int64
+	for {
+		header, err := seekAndRead(input, offset, io.SeekStart, 10)
+		if err != nil || string(header[0:3]) != id3MarkerValue {
+			break
+		}
+		offset += 10 + int64(ByteToIntSynchsafe(header[6:10]))
+		if header[3] == 4 && id3v24Flags(header[5]).HasFooter() {
+			offset += 10
+		}
+	}
+
+	header, err := seekAndRead(input, offset, io.SeekStart, 12)
+	if err != nil {
+		if offset > 0 {
+			return ContainerMPEG, offset
+		}
+		return ContainerUnknown, offset
+	}
+
+	switch {
+	case string(header[0:4]) == FLACIdentifier:
+		return ContainerFLAC, offset
+	case string(header[0:4]) == OggIdentifier:
+		return ContainerOgg, offset
+	case string(header[0:4]) == wavRIFFIdentifier && string(header[8:12]) == wavWAVEIdentifier:
+		return ContainerWAV, offset
+	case string(header[0:4]) == aiffFORMIdentifier && (string(header[8:12]) == aiffFormType || string(header[8:12]) == aifcFormType):
+		return ContainerAIFF, offset
+	case string(header[0:4]) == dsfIdentifier:
+		return ContainerDSF, offset
+	case string(header[4:8]) == Mp4Marker:
+		return ContainerMP4, offset
+	case binary.BigEndian.Uint32(header[0:4]) == matroskaEBML:
+		return ContainerMatroska, offset
+	case header[0] == 0xFF && header[1]&0xE0 == 0xE0, offset > 0:
+		// frame sync
+		return ContainerMPEG, offset
+	}
+	return ContainerUnknown, offset
+}
+
+// containerVersion - native tag of the container at offset, VersionUndefined for MPEG audio
+func containerVersion(input io.ReadSeeker, container Container, offset int64) Version {
+	if container == ContainerUnknown || container == ContainerMPEG {
+		return VersionUndefined
+	}
+
+	// container after ID3v2 tags
+	if offset > 0 {
+		size, err := input.Seek(0, io.SeekEnd)
+		if err != nil {
+			return VersionUndefined
+		}
+		input, err = newInputSection(input, offset, size-offset)
+		if err != nil {
+			return VersionUndefined
+		}
+	}
+
+	checks := []struct {
+		container Container
+		version   Version
+		check     func(input io.ReadSeeker) bool
+	}{
+		{ContainerMP4, VersionMP4, checkMp4},
+		{ContainerFLAC, VersionFLAC, checkFLAC},
+		{ContainerOgg, VersionOggVorbis, checkOggVorbis},
+		{ContainerOgg, VersionOpus, checkOpus},
+		{ContainerWAV, VersionWAV, checkWAV},
+		{ContainerAIFF, VersionAIFF, checkAIFF},
+		{ContainerMatroska, VersionMatroska, checkMatroska},
+		{ContainerDSF, VersionDSF, checkDSF},
+	}
+	for _, c := range checks {
+		if c.container == container && c.check(input) {
+			return c.version
+		}
+	}
+	return VersionUndefined
+}
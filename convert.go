@@ -1,7 +1,9 @@
 package tag
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 )
 
 // nolint:gocyclo
@@ -70,6 +72,546 @@ func GetMap(metadata Metadata) map[string]interface{} {
 	if number, total, err := metadata.GetTrackNumber(); err == nil {
 		tags["track number"] = fmt.Sprintf("%d/%d", number, total)
 	}
+	if val, err = metadata.GetLyrics(""); err == nil {
+		tags["lyrics"] = val
+	}
 
 	return tags
 }
+
+// ID3v2.2 frame identifiers and their ID3v2.3 equivalents.
+// Frames without equivalent (e.g. CRM - encrypted meta frame) are dropped.
+var id3v22FrameNames = map[string]string{
+	"BUF": "RBUF",
+	"CNT": "PCNT",
+	"COM": "COMM",
+	"CRA": "AENC",
+	"ETC": "ETCO",
+	"EQU": "EQUA",
+	"GEO": "GEOB",
+	"IPL": "IPLS",
+	"LNK": "LINK",
+	"MCI": "MCDI",
+	"MLL": "MLLT",
+	"PIC": "APIC",
+	"POP": "POPM",
+	"REV": "RVRB",
+	"RVA": "RVAD",
+	"SLT": "SYLT",
+	"STC": "SYTC",
+	"TAL": "TALB",
+	"TBP": "TBPM",
+	"TCM": "TCOM",
+	"TCO": "TCON",
+	"TCP": "TCMP",
+	"TCR": "TCOP",
+	"TDA": "TDAT",
+	"TDY": "TDLY",
+	"TEN": "TENC",
+	"TFT": "TFLT",
+	"TIM": "TIME",
+	"TKE": "TKEY",
+	"TLA": "TLAN",
+	"TLE": "TLEN",
+	"TMT": "TMED",
+	"TOA": "TOPE",
+	"TOF": "TOFN",
+	"TOL": "TOLY",
+	"TOR": "TORY",
+	"TOT": "TOAL",
+	"TP1": "TPE1",
+	"TP2": "TPE2",
+	"TP3": "TPE3",
+	"TP4": "TPE4",
+	"TPA": "TPOS",
+	"TPB": "TPUB",
+	"TRC": "TSRC",
+	"TRD": "TRDA",
+	"TRK": "TRCK",
+	"TS2": "TSO2",
+	"TSA": "TSOA",
+	"TSC": "TSOC",
+	"TSI": "TSIZ",
+	"TSP": "TSOP",
+	"TSS": "TSSE",
+	"TST": "TSOT",
+	"TT1": "TIT1",
+	"TT2": "TIT2",
+	"TT3": "TIT3",
+	"TXT": "TEXT",
+	"TXX": "TXXX",
+	"TYE": "TYER",
+	"UFI": "UFID",
+	"ULT": "USLT",
+	"WAF": "WOAF",
+	"WAR": "WOAR",
+	"WAS": "WOAS",
+	"WCM": "WCOM",
+	"WCP": "WCOP",
+	"WPB": "WPUB",
+	"WXX": "WXXX",
+}
+
+// ID3v2.3 frames removed in ID3v2.4 without direct replacement.
+var id3v23OnlyFrames = map[string]bool{
+	"EQUA": true,
+	"RVAD": true,
+	"TRDA": true,
+	"TSIZ": true,
+}
+
+// ID3v2.4 frames unknown in ID3v2.3.
+var id3v24OnlyFrames = map[string]bool{
+	"ASPI": true,
+	"EQU2": true,
+	"RVA2": true,
+	"SEEK": true,
+	"SIGN": true,
+	"TDEN": true,
+	"TDRL": true,
+	"TDTG": true,
+	"TMOO": true,
+	"TPRO": true,
+	"TSST": true,
+}
+
+// ConvertID3v2 - convert ID3v2.2, ID3v2.3 or ID3v2.4 tag to ID3v2.3 or ID3v2.4.
+// Source tag isn't changed. Audio data is shared with the result.
+// Encrypted frames are dropped on conversion between ID3v2.3 and ID3v2.4, their data is laid out by version.
+// ID3v2.4 tag appended at the end of the file or chained by SEEK frames can't be converted to ID3v2.3
+func ConvertID3v2(m Metadata, target Version) (Metadata, error) {
+	if target != VersionID3v23 && target != VersionID3v24 {
+		return nil, ErrUnsupportedFormat
+	}
+
+	var frames []ID3v24Frame
+	var data []byte
+	var audio *fileRegion
+	var hasCRC bool
+	source := m.GetVersion()
+	switch id3v2 := m.(type) {
+	case *ID3v22:
+		frames = convertID3v22Frames(id3v2.Frames)
+		data, audio = id3v2.Data, id3v2.audio
+		// ID3v2.2 frames are mapped to ID3v2.3 frames
+		source = VersionID3v23
+	case *ID3v23:
+		for i := range id3v2.Frames {
+			frame := id3v2.Frames[i]
+			frames = append(frames, ID3v24Frame{
+				Key:   frame.Key,
+				Value: frame.Value,
+				Flags: frame.Flags.id3v24(),
+				Group: frame.Group,
+			})
+		}
+		data, audio = id3v2.Data, id3v2.audio
+		hasCRC = id3v2.ExtendedHeader != nil && id3v2.ExtendedHeader.HasCRC
+	case *ID3v24:
+		if target == VersionID3v24 {
+			// position of the tag, trailer and extended header are kept
+			result := *id3v2
+			result.Frames = append([]ID3v24Frame{}, id3v2.Frames...)
+			if id3v2.ExtendedHeader != nil {
+				extendedHeader := *id3v2.ExtendedHeader
+				result.ExtendedHeader = &extendedHeader
+			}
+			return &result, nil
+		}
+		// ID3v2.3 tag is written only at the start of the file, before audio with chained tags
+		if id3v2.AtEnd || id3v2.chained {
+			return nil, ErrUnsupportedFormat
+		}
+		frames = append(frames, id3v2.Frames...)
+		data, audio = id3v2.Data, id3v2.audio
+		hasCRC = id3v2.ExtendedHeader != nil && id3v2.ExtendedHeader.HasCRC
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	switch {
+	case source == VersionID3v23 && target == VersionID3v24:
+		frames = convertID3v23FramesToID3v24(frames)
+	case source == VersionID3v24 && target == VersionID3v23:
+		frames = convertID3v24FramesToID3v23(frames)
+	}
+
+	if target == VersionID3v24 {
+		result := &ID3v24{
+			Marker:  id3MarkerValue,
+			Version: VersionID3v24,
+			Frames:  frames,
+			Data:    data,
+			audio:   audio,
+		}
+		if hasCRC {
+			result.ExtendedHeader = &ID3v24ExtendedHeader{HasCRC: true}
+		}
+		return result, nil
+	}
+
+	result := &ID3v23{
+		Marker:  id3MarkerValue,
+		Version: VersionID3v23,
+		Frames:  make([]ID3v23Frame, 0, len(frames)),
+		Data:    data,
+		audio:   audio,
+	}
+	if hasCRC {
+		result.ExtendedHeader = &ID3v23ExtendedHeader{HasCRC: true}
+	}
+	for i := range frames {
+		frame := frames[i]
+		result.Frames = append(result.Frames, ID3v23Frame{
+			Key:   frame.Key,
+			Value: frame.Value,
+			Flags: frame.Flags.id3v23(),
+			Group: frame.Group,
+		})
+	}
+	return result, nil
+}
+
+func convertID3v22Frames(frames []ID3v22Frame) []ID3v24Frame {
+	result := make([]ID3v24Frame, 0, len(frames))
+	for i := range frames {
+		key, ok := id3v22FrameNames[frames[i].Key]
+		if !ok {
+			continue
+		}
+
+		value := frames[i].Value
+		if key == "APIC" {
+			var err error
+			value, err = convertID3v22Picture(value)
+			if err != nil {
+				continue
+			}
+		} else if key[0] == 'T' && key != "TXXX" {
+			value = trimID3v2Terminator(value)
+		}
+
+		result = append(result, ID3v24Frame{Key: key, Value: value})
+	}
+	return result
+}
+
+// convertID3v22Picture - PIC to APIC
+// [encoding][image format (3)][type][description][data] to
+// [encoding][MIME type $00][type][description][data].
+func convertID3v22Picture(value []byte) ([]byte, error) {
+	if len(value) < 5 {
+		return nil, ErrIncorrectLength
+	}
+	result := []byte{value[0]}
+	result = append(result, id3v22ImageFormatToMIME(string(value[1:4]))...)
+	result = append(result, 0)
+	return append(result, value[4:]...), nil
+}
+
+// nolint:gocyclo
+func convertID3v23FramesToID3v24(frames []ID3v24Frame) []ID3v24Frame {
+	var year, date, clock string
+	dateIndex := -1
+
+	result := make([]ID3v24Frame, 0, len(frames))
+	for i := range frames {
+		frame := frames[i]
+		if frame.Flags.IsEncrypted() {
+			continue
+		}
+		switch frame.Key {
+		case "TYER", "TDAT", "TIME":
+			value, err := getID3v2FrameString(frame.Value)
+			if err != nil {
+				continue
+			}
+			switch frame.Key {
+			case "TYER":
+				year = value
+			case "TDAT":
+				date = value
+			default:
+				clock = value
+			}
+			if dateIndex == -1 {
+				dateIndex = len(result)
+				result = append(result, ID3v24Frame{Key: "TDRC", Flags: frame.Flags, Group: frame.Group})
+			}
+		case "TORY":
+			frame.Key = "TDOR"
+			result = append(result, frame)
+		case "IPLS":
+			frame.Key = "TIPL"
+			result = append(result, frame)
+		default:
+			if id3v23OnlyFrames[frame.Key] {
+				continue
+			}
+			result = append(result, frame)
+		}
+	}
+
+	if dateIndex == -1 {
+		return result
+	}
+	if len(year) != 4 {
+		// date and time without a year can't be expressed with TDRC
+		return append(result[:dateIndex], result[dateIndex+1:]...)
+	}
+	timestamp := year
+	if len(date) == 4 {
+		// DDMM
+		timestamp += "-" + date[2:4] + "-" + date[0:2]
+		if len(clock) == 4 {
+			// HHMM
+			timestamp += "T" + clock[0:2] + ":" + clock[2:4] + ":00"
+		}
+	}
+	result[dateIndex].Value = SetString(timestamp)
+	return result
+}
+
+// nolint:gocyclo
+func convertID3v24FramesToID3v23(frames []ID3v24Frame) []ID3v24Frame {
+	var people [][]byte
+	peopleIndex := -1
+
+	result := make([]ID3v24Frame, 0, len(frames))
+	for i := range frames {
+		frame := frames[i]
+		if frame.Flags.IsEncrypted() {
+			continue
+		}
+		switch frame.Key {
+		case "TDRC":
+			value, err := getID3v2FrameString(frame.Value)
+			if err != nil || len(value) < 4 {
+				continue
+			}
+			// yyyy-MM-ddTHH:mm:ss with reduced precision
+			result = append(result, ID3v24Frame{
+				Key:   "TYER",
+				Value: SetString(value[0:4]),
+				Flags: frame.Flags,
+				Group: frame.Group,
+			})
+			if len(value) >= 10 {
+				result = append(result, ID3v24Frame{
+					Key:   "TDAT",
+					Value: SetString(value[8:10] + value[5:7]),
+					Flags: frame.Flags,
+					Group: frame.Group,
+				})
+			}
+			if len(value) >= 16 {
+				result = append(result, ID3v24Frame{
+					Key:   "TIME",
+					Value: SetString(value[11:13] + value[14:16]),
+					Flags: frame.Flags,
+					Group: frame.Group,
+				})
+			}
+		case "TDOR":
+			value, err := getID3v2FrameString(frame.Value)
+			if err != nil || len(value) < 4 {
+				continue
+			}
+			result = append(result, ID3v24Frame{
+				Key:   "TORY",
+				Value: SetString(value[0:4]),
+				Flags: frame.Flags,
+				Group: frame.Group,
+			})
+		case "TIPL", "TMCL":
+			value, err := getID3v2FrameString(frame.Value)
+			if err != nil || value == "" {
+				continue
+			}
+			people = append(people, []byte(value))
+			if peopleIndex == -1 {
+				peopleIndex = len(result)
+				result = append(result, ID3v24Frame{Key: "IPLS", Flags: frame.Flags, Group: frame.Group})
+			}
+		default:
+			if id3v24OnlyFrames[frame.Key] {
+				continue
+			}
+			if isID3v24TextFrame(frame.Key) {
+				// multiple values are separated by "/" in ID3v2.3
+				value, err := getID3v2FrameString(frame.Value)
+				if err == nil && strings.Contains(value, "\x00") {
+					frame.Value = SetString(strings.ReplaceAll(value, "\x00", "/"))
+				}
+			}
+			result = append(result, convertID3v23Encoding(frame))
+		}
+	}
+
+	if peopleIndex != -1 {
+		// both lists are 'involvement \x00 involvee' pairs
+		result[peopleIndex].Value = SetString(string(bytes.Join(people, []byte{0})))
+	}
+	return result
+}
+
+// convertID3v23Encoding - ID3v2.3 supports only ISO-8859-1 ($00) and UTF-16 with BOM ($01).
+// Text in UTF-16BE ($02) and UTF-8 ($03) is re-encoded to UTF-16 with BOM in all frames with encoding byte.
+// nolint:gocritic,gocyclo,funlen
+func convertID3v23Encoding(frame ID3v24Frame) ID3v24Frame {
+	if len(frame.Value) == 0 || (frame.Value[0] != 2 && frame.Value[0] != 3) {
+		return frame
+	}
+	encoding := GetEncoding(frame.Value[0])
+	data := frame.Value[1:]
+
+	var value []byte
+	switch {
+	case frame.Key[0] == 'T' || frame.Key == "IPLS":
+		// [encoding][text]
+		text, err := DecodeString(data, encoding)
+		if err != nil {
+			return frame
+		}
+		value = EncodeUTF16(text)
+	case frame.Key == "COMM" || frame.Key == "USLT" || frame.Key == "USER":
+		// [encoding][language (3)][description $00][text], USER has no description
+		if len(data) < 3 {
+			return frame
+		}
+		text, err := DecodeString(data[3:], encoding)
+		if err != nil {
+			return frame
+		}
+		value = append(append([]byte{}, data[:3]...), EncodeUTF16(text)...)
+	case frame.Key == "WXXX":
+		// [encoding][description $00][URL]
+		text, rest, err := encodeID3v23Strings(data, encoding, 1)
+		if err != nil {
+			return frame
+		}
+		value = append(text, rest...)
+	case frame.Key == "APIC" || frame.Key == "GEOB":
+		// [encoding][MIME $00][type][description $00][data]
+		// [encoding][MIME $00][filename $00][description $00][data]
+		parts := bytes.SplitN(data, []byte{0}, 2)
+		if len(parts) != 2 || len(parts[1]) == 0 {
+			return frame
+		}
+		value = append(append([]byte{}, parts[0]...), 0)
+		count := 2
+		if frame.Key == "APIC" {
+			value = append(value, parts[1][0])
+			parts[1] = parts[1][1:]
+			count = 1
+		}
+		text, rest, err := encodeID3v23Strings(parts[1], encoding, count)
+		if err != nil {
+			return frame
+		}
+		value = append(append(value, text...), rest...)
+	case frame.Key == "SYLT":
+		// [encoding][language (3)][timestamp format][content type][description $00]([text $00][timestamp (4)])...
+		if len(data) < 5 {
+			return frame
+		}
+		text, rest, err := encodeID3v23Strings(data[5:], encoding, 1)
+		if err != nil {
+			return frame
+		}
+		value = append(append([]byte{}, data[:5]...), text...)
+		for len(rest) > 0 {
+			text, rest, err = encodeID3v23Strings(rest, encoding, 1)
+			if err != nil || len(rest) < 4 {
+				return frame
+			}
+			value = append(append(value, text...), rest[:4]...)
+			rest = rest[4:]
+		}
+	case frame.Key == "OWNE" || frame.Key == "COMR":
+		// [encoding][price $00][date (8)][seller]
+		// [encoding][price $00][valid until (8)][contact URL $00][received as][seller $00][description $00][MIME $00][logo]
+		parts := bytes.SplitN(data, []byte{0}, 2)
+		if len(parts) != 2 || len(parts[1]) < 8 {
+			return frame
+		}
+		value = append(append(append([]byte{}, parts[0]...), 0), parts[1][:8]...)
+		if frame.Key == "OWNE" {
+			text, err := DecodeString(parts[1][8:], encoding)
+			if err != nil {
+				return frame
+			}
+			value = append(value, EncodeUTF16(text)...)
+			break
+		}
+		url := bytes.SplitN(parts[1][8:], []byte{0}, 2)
+		if len(url) != 2 || len(url[1]) == 0 {
+			return frame
+		}
+		text, rest, err := encodeID3v23Strings(url[1][1:], encoding, 2)
+		if err != nil {
+			return frame
+		}
+		value = append(append(value, url[0]...), 0, url[1][0])
+		value = append(append(value, text...), rest...)
+	default:
+		return frame
+	}
+	frame.Value = append([]byte{1}, value...)
+	return frame
+}
+
+// encodeID3v23Strings - re-encode count terminated strings at the start of data to UTF-16 with BOM.
+// Data after the strings is returned as is
+func encodeID3v23Strings(data []byte, encoding string, count int) ([]byte, []byte, error) {
+	var result []byte
+	for i := 0; i < count; i++ {
+		// terminator is $00 or $00 00 aligned to characters
+		end, size := bytes.IndexByte(data, 0), 1
+		if encoding == EncodingUTF16 || encoding == EncodingUTF16BE {
+			end, size = -1, 2
+			for j := 0; j+1 < len(data); j += 2 {
+				if data[j] == 0 && data[j+1] == 0 {
+					end = j
+					break
+				}
+			}
+		}
+		if end == -1 {
+			return nil, nil, ErrIncorrectTag
+		}
+		text, err := DecodeString(data[:end], encoding)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(append(result, EncodeUTF16(text)...), 0, 0)
+		data = data[end+size:]
+	}
+	return result, data, nil
+}
+
+// getID3v2FrameString - decode text frame without terminating zero characters.
+func getID3v2FrameString(value []byte) (string, error) {
+	str, err := GetString(value)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(str, "\x00"), nil
+}
+
+// trimID3v2Terminator - remove trailing string terminators from text frame value
+// $00 for ISO-8859-1 and UTF-8, $00 00 for UTF-16.
+func trimID3v2Terminator(value []byte) []byte {
+	if len(value) == 0 {
+		return value
+	}
+	step := 1
+	if value[0] == 1 || value[0] == 2 {
+		step = 2
+	}
+	for len(value) >= 1+step && bytes.Equal(value[len(value)-step:], make([]byte, step)) &&
+		(len(value)-1)%step == 0 {
+		value = value[:len(value)-step]
+	}
+	return value
+}
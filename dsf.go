@@ -0,0 +1,563 @@
+package tag
+
+import (
+	"encoding/binary"
+	"image"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// DSF - DSD stream file, ID3v2 tag is at the metadata pointer of DSD chunk
+type DSF struct {
+	Data  []byte   // fmt and data chunks, nil if they are read from the source on demand
+	ID3   Metadata // *ID3v23 or *ID3v24
+	audio *fileRegion
+}
+
+func (dsf *DSF) GetAllTagNames() []string {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.GetAllTagNames()
+}
+
+func (dsf *DSF) GetVersion() Version {
+	return VersionDSF
+}
+
+// GetFileData - sample data of data chunk
+func (dsf *DSF) GetFileData() []byte {
+	return readSection(dsf.dataChunk())
+}
+
+// AudioReader - sample data of data chunk
+func (dsf *DSF) AudioReader() io.Reader {
+	return dsf.dataChunk()
+}
+
+func (dsf *DSF) dataChunk() *io.SectionReader {
+	chunks := audioSection(dsf.Data, dsf.audio)
+	header := make([]byte, dsfChunkHeaderSize)
+	for offset := int64(0); offset+dsfChunkHeaderSize <= chunks.Size(); {
+		_, err := chunks.ReadAt(header, offset)
+		if err != nil {
+			break
+		}
+		size := binary.LittleEndian.Uint64(header[4:dsfChunkHeaderSize])
+		if size < dsfChunkHeaderSize || size > uint64(chunks.Size()-offset) {
+			break
+		}
+		if string(header[0:4]) == dsfDataChunk {
+			return io.NewSectionReader(chunks, offset+dsfChunkHeaderSize, int64(size)-dsfChunkHeaderSize)
+		}
+		offset += int64(size)
+	}
+	return io.NewSectionReader(chunks, 0, 0)
+}
+
+func (dsf *DSF) GetTitle() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetTitle()
+}
+
+func (dsf *DSF) GetArtist() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetArtist()
+}
+
+func (dsf *DSF) GetAlbum() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetAlbum()
+}
+
+func (dsf *DSF) GetYear() (int, error) {
+	if dsf.ID3 == nil {
+		return 0, ErrTagNotFound
+	}
+	return dsf.ID3.GetYear()
+}
+
+func (dsf *DSF) GetComment() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetComment()
+}
+
+func (dsf *DSF) GetGenre() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetGenre()
+}
+
+func (dsf *DSF) GetAlbumArtist() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetAlbumArtist()
+}
+
+func (dsf *DSF) GetDate() (time.Time, error) {
+	if dsf.ID3 == nil {
+		return time.Now(), ErrTagNotFound
+	}
+	return dsf.ID3.GetDate()
+}
+
+func (dsf *DSF) GetArranger() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetArranger()
+}
+
+func (dsf *DSF) GetAuthor() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetAuthor()
+}
+
+func (dsf *DSF) GetBPM() (int, error) {
+	if dsf.ID3 == nil {
+		return 0, ErrTagNotFound
+	}
+	return dsf.ID3.GetBPM()
+}
+
+func (dsf *DSF) GetCatalogNumber() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetCatalogNumber()
+}
+
+func (dsf *DSF) GetCompilation() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetCompilation()
+}
+
+func (dsf *DSF) GetComposer() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetComposer()
+}
+
+func (dsf *DSF) GetConductor() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetConductor()
+}
+
+func (dsf *DSF) GetCopyright() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetCopyright()
+}
+
+func (dsf *DSF) GetDescription() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetDescription()
+}
+
+func (dsf *DSF) GetDiscNumber() (int, int, error) {
+	if dsf.ID3 == nil {
+		return 0, 0, ErrTagNotFound
+	}
+	return dsf.ID3.GetDiscNumber()
+}
+
+func (dsf *DSF) GetEncodedBy() (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetEncodedBy()
+}
+
+func (dsf *DSF) GetTrackNumber() (int, int, error) {
+	if dsf.ID3 == nil {
+		return 0, 0, ErrTagNotFound
+	}
+	return dsf.ID3.GetTrackNumber()
+}
+
+func (dsf *DSF) GetPicture() (image.Image, error) {
+	if dsf.ID3 == nil {
+		return nil, ErrTagNotFound
+	}
+	return dsf.ID3.GetPicture()
+}
+
+func (dsf *DSF) GetLyrics(language string) (string, error) {
+	if dsf.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return dsf.ID3.GetLyrics(language)
+}
+
+func (dsf *DSF) SetTitle(title string) error {
+	return dsf.id3().SetTitle(title)
+}
+
+func (dsf *DSF) SetArtist(artist string) error {
+	return dsf.id3().SetArtist(artist)
+}
+
+func (dsf *DSF) SetAlbum(album string) error {
+	return dsf.id3().SetAlbum(album)
+}
+
+func (dsf *DSF) SetYear(year int) error {
+	return dsf.id3().SetYear(year)
+}
+
+func (dsf *DSF) SetComment(comment string) error {
+	return dsf.id3().SetComment(comment)
+}
+
+func (dsf *DSF) SetGenre(genre string) error {
+	return dsf.id3().SetGenre(genre)
+}
+
+func (dsf *DSF) SetAlbumArtist(albumArtist string) error {
+	return dsf.id3().SetAlbumArtist(albumArtist)
+}
+
+func (dsf *DSF) SetDate(date time.Time) error {
+	return dsf.id3().SetDate(date)
+}
+
+func (dsf *DSF) SetArranger(arranger string) error {
+	return dsf.id3().SetArranger(arranger)
+}
+
+func (dsf *DSF) SetAuthor(author string) error {
+	return dsf.id3().SetAuthor(author)
+}
+
+func (dsf *DSF) SetBPM(bmp int) error {
+	return dsf.id3().SetBPM(bmp)
+}
+
+func (dsf *DSF) SetCatalogNumber(catalogNumber string) error {
+	return dsf.id3().SetCatalogNumber(catalogNumber)
+}
+
+func (dsf *DSF) SetCompilation(compilation string) error {
+	return dsf.id3().SetCompilation(compilation)
+}
+
+func (dsf *DSF) SetComposer(composer string) error {
+	return dsf.id3().SetComposer(composer)
+}
+
+func (dsf *DSF) SetConductor(conductor string) error {
+	return dsf.id3().SetConductor(conductor)
+}
+
+func (dsf *DSF) SetCopyright(copyright string) error {
+	return dsf.id3().SetCopyright(copyright)
+}
+
+func (dsf *DSF) SetDescription(description string) error {
+	return dsf.id3().SetDescription(description)
+}
+
+func (dsf *DSF) SetDiscNumber(number int, total int) error {
+	return dsf.id3().SetDiscNumber(number, total)
+}
+
+func (dsf *DSF) SetEncodedBy(encodedBy string) error {
+	return dsf.id3().SetEncodedBy(encodedBy)
+}
+
+func (dsf *DSF) SetTrackNumber(number int, total int) error {
+	return dsf.id3().SetTrackNumber(number, total)
+}
+
+func (dsf *DSF) SetPicture(picture image.Image) error {
+	return dsf.id3().SetPicture(picture)
+}
+
+func (dsf *DSF) SetLyrics(language string, lyrics string) error {
+	return dsf.id3().SetLyrics(language, lyrics)
+}
+
+func (dsf *DSF) DeleteAll() error {
+	dsf.ID3 = nil
+	return nil
+}
+
+func (dsf *DSF) DeleteTitle() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteTitle()
+}
+
+func (dsf *DSF) DeleteArtist() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteArtist()
+}
+
+func (dsf *DSF) DeleteAlbum() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteAlbum()
+}
+
+func (dsf *DSF) DeleteYear() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteYear()
+}
+
+func (dsf *DSF) DeleteComment() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteComment()
+}
+
+func (dsf *DSF) DeleteGenre() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteGenre()
+}
+
+func (dsf *DSF) DeleteAlbumArtist() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteAlbumArtist()
+}
+
+func (dsf *DSF) DeleteDate() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteDate()
+}
+
+func (dsf *DSF) DeleteArranger() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteArranger()
+}
+
+func (dsf *DSF) DeleteAuthor() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteAuthor()
+}
+
+func (dsf *DSF) DeleteBPM() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteBPM()
+}
+
+func (dsf *DSF) DeleteCatalogNumber() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteCatalogNumber()
+}
+
+func (dsf *DSF) DeleteCompilation() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteCompilation()
+}
+
+func (dsf *DSF) DeleteComposer() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteComposer()
+}
+
+func (dsf *DSF) DeleteConductor() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteConductor()
+}
+
+func (dsf *DSF) DeleteCopyright() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteCopyright()
+}
+
+func (dsf *DSF) DeleteDescription() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteDescription()
+}
+
+func (dsf *DSF) DeleteDiscNumber() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteDiscNumber()
+}
+
+func (dsf *DSF) DeleteEncodedBy() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteEncodedBy()
+}
+
+func (dsf *DSF) DeleteTrackNumber() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteTrackNumber()
+}
+
+func (dsf *DSF) DeletePicture() error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeletePicture()
+}
+
+func (dsf *DSF) DeleteLyrics(language string) error {
+	if dsf.ID3 == nil {
+		return nil
+	}
+	return dsf.ID3.DeleteLyrics(language)
+}
+
+func (dsf *DSF) SaveFile(path string) error {
+	return saveFile(path, dsf.Save, SaveOptions{})
+}
+
+// Save - write DSD chunk with new total size and metadata pointer, fmt and data chunks
+// and ID3v2 tag at the end of file. Metadata pointer is 0 without tag
+func (dsf *DSF) Save(input io.WriteSeeker) error {
+	var id3 []byte
+	if dsf.ID3 != nil {
+		buffer := &writeSeekBuffer{}
+		if err := dsf.ID3.Save(buffer); err != nil {
+			return err
+		}
+		id3 = buffer.Bytes()
+	}
+
+	chunks := audioSection(dsf.Data, dsf.audio)
+	header := make([]byte, dsfHeaderSize)
+	copy(header, dsfIdentifier)
+	binary.LittleEndian.PutUint64(header[4:12], dsfHeaderSize)
+	binary.LittleEndian.PutUint64(header[12:20], uint64(dsfHeaderSize+chunks.Size()+int64(len(id3))))
+	if id3 != nil {
+		binary.LittleEndian.PutUint64(header[20:28], uint64(dsfHeaderSize+chunks.Size()))
+	}
+
+	_, err := input.Write(header)
+	if err != nil {
+		return err
+	}
+	err = writeAudio(input, dsf.Data, dsf.audio)
+	if err != nil {
+		return err
+	}
+	_, err = input.Write(id3)
+	return err
+}
+
+func checkDSF(input io.ReadSeeker) bool {
+	header, err := seekAndRead(input, 0, io.SeekStart, dsfHeaderSize)
+	if err != nil {
+		return false
+	}
+	return string(header[0:4]) == dsfIdentifier && binary.LittleEndian.Uint64(header[4:12]) == dsfHeaderSize
+}
+
+func ReadDSF(input io.ReadSeeker) (*DSF, error) {
+	header, err := seekAndRead(input, 0, io.SeekStart, dsfHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	if string(header[0:4]) != dsfIdentifier || binary.LittleEndian.Uint64(header[4:12]) != dsfHeaderSize {
+		return nil, ErrFileMarker
+	}
+
+	// chunks after DSD chunk
+	end, err := input.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	dsf := DSF{}
+	chunksEnd := end
+	pointer := int64(binary.LittleEndian.Uint64(header[20:28]))
+	if pointer != 0 {
+		if pointer < dsfHeaderSize || pointer > end {
+			return nil, ErrIncorrectLength
+		}
+		_, err = input.Seek(pointer, io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
+		var data []byte
+		data, err = ioutil.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+
+		// unsupported metadata is kept with chunks
+		dsf.ID3, err = readIFFID3(data)
+		if err != nil {
+			return nil, err
+		}
+		if dsf.ID3 != nil {
+			chunksEnd = pointer
+		}
+	}
+
+	dsf.audio, err = readRegion(input, dsfHeaderSize, chunksEnd-dsfHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	return &dsf, nil
+}
+
+// id3 - ID3v2 tag, created on demand
+func (dsf *DSF) id3() Metadata {
+	if dsf.ID3 == nil {
+		dsf.ID3 = &ID3v24{
+			Marker:  id3MarkerValue,
+			Version: VersionID3v24,
+		}
+	}
+	return dsf.ID3
+}
@@ -0,0 +1,233 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+)
+
+// ebmlElement - element header position and its payload
+type ebmlElement struct {
+	ID         uint32
+	Offset     int // element start
+	HeaderSize int // id and size length
+	Size       int // payload size, -1 for unknown size
+	Data       []byte
+}
+
+// end - position after the element
+func (element *ebmlElement) end() int {
+	return element.Offset + element.HeaderSize + element.Size
+}
+
+// readEBMLID - element id keeps its length marker
+func readEBMLID(data []byte) (uint32, int, error) {
+	if len(data) == 0 {
+		return 0, 0, ErrIncorrectLength
+	}
+	length := ebmlVintLength(data[0])
+	if length == 0 || length > 4 || length > len(data) {
+		return 0, 0, ErrIncorrectTag
+	}
+
+	var id uint32
+	for i := 0; i < length; i++ {
+		id = id<<8 | uint32(data[i])
+	}
+	return id, length, nil
+}
+
+// readEBMLSize - size without length marker, -1 if all bits are set (unknown size)
+func readEBMLSize(data []byte) (int, int, error) {
+	if len(data) == 0 {
+		return 0, 0, ErrIncorrectLength
+	}
+	length := ebmlVintLength(data[0])
+	if length == 0 || length > len(data) {
+		return 0, 0, ErrIncorrectTag
+	}
+
+	value := uint64(data[0]) & (0xFF >> length)
+	unknown := value == 0xFF>>length
+	for i := 1; i < length; i++ {
+		value = value<<8 | uint64(data[i])
+		unknown = unknown && data[i] == 0xFF
+	}
+	if unknown {
+		return -1, length, nil
+	}
+	if value > uint64(math.MaxInt) {
+		return 0, 0, ErrIncorrectLength
+	}
+	return int(value), length, nil
+}
+
+// ebmlVintLength - number of leading zero bits plus one
+func ebmlVintLength(first byte) int {
+	for i := 0; i < 8; i++ {
+		if first&(0x80>>i) != 0 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func encodeEBMLID(id uint32) []byte {
+	switch {
+	case id > 0xFFFFFF:
+		return []byte{byte(id >> 24), byte(id >> 16), byte(id >> 8), byte(id)}
+	case id > 0xFFFF:
+		return []byte{byte(id >> 16), byte(id >> 8), byte(id)}
+	case id > 0xFF:
+		return []byte{byte(id >> 8), byte(id)}
+	}
+	return []byte{byte(id)}
+}
+
+// encodeEBMLSize - size with given length, minimal length if length is 0
+func encodeEBMLSize(size int, length int) ([]byte, error) {
+	if length == 0 {
+		length = 1
+		// all ones value is reserved for unknown size
+		for length < 8 && uint64(size) >= 1<<(7*length)-1 {
+			length++
+		}
+	}
+	if length > 8 || uint64(size) >= 1<<(7*length)-1 {
+		return nil, ErrIncorrectLength
+	}
+
+	result := make([]byte, length)
+	value := uint64(size)
+	for i := length - 1; i >= 0; i-- {
+		result[i] = byte(value)
+		value >>= 8
+	}
+	result[0] |= 0x80 >> (length - 1)
+	return result, nil
+}
+
+func readEBMLElement(data []byte, offset int) (*ebmlElement, error) {
+	id, idLength, err := readEBMLID(data[offset:])
+	if err != nil {
+		return nil, err
+	}
+	size, sizeLength, err := readEBMLSize(data[offset+idLength:])
+	if err != nil {
+		return nil, err
+	}
+
+	element := ebmlElement{
+		ID:         id,
+		Offset:     offset,
+		HeaderSize: idLength + sizeLength,
+		Size:       size,
+	}
+	if size >= 0 {
+		if element.end() > len(data) {
+			return nil, ErrIncorrectLength
+		}
+		element.Data = data[offset+element.HeaderSize : element.end()]
+	}
+	return &element, nil
+}
+
+// readEBMLChildren - children of master element with known size
+func readEBMLChildren(data []byte) ([]*ebmlElement, error) {
+	var result []*ebmlElement
+	for offset := 0; offset < len(data); {
+		element, err := readEBMLElement(data, offset)
+		if err != nil {
+			return nil, err
+		}
+		if element.Size < 0 {
+			return nil, ErrIncorrectLength
+		}
+		result = append(result, element)
+		offset = element.end()
+	}
+	return result, nil
+}
+
+func writeEBMLElement(buffer *bytes.Buffer, id uint32, data []byte) {
+	size, err := encodeEBMLSize(len(data), 0)
+	if err != nil {
+		return
+	}
+	buffer.Write(encodeEBMLID(id))
+	buffer.Write(size)
+	buffer.Write(data)
+}
+
+func ebmlUint(data []byte) uint64 {
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+// encodeEBMLUint - minimal big endian representation
+func encodeEBMLUint(value uint64) []byte {
+	result := make([]byte, 8)
+	binary.BigEndian.PutUint64(result, value)
+	for len(result) > 1 && result[0] == 0 {
+		result = result[1:]
+	}
+	return result
+}
+
+// ebmlVoid - void element of exactly size bytes, size must be at least 2
+func ebmlVoid(size int) ([]byte, error) {
+	for length := 1; length <= 8; length++ {
+		header, err := encodeEBMLSize(size-1-length, length)
+		if err != nil || size-1-length < 0 {
+			continue
+		}
+		result := make([]byte, size)
+		result[0] = byte(matroskaVoid)
+		copy(result[1:], header)
+		return result, nil
+	}
+	return nil, ErrIncorrectLength
+}
+
+// fitEBMLElement - element and Void which fill exactly size bytes.
+// One spare byte goes to a longer size field
+func fitEBMLElement(id uint32, payload []byte, size int) ([]byte, []byte, bool) {
+	sizeField, err := encodeEBMLSize(len(payload), 0)
+	if err != nil {
+		return nil, nil, false
+	}
+	header := encodeEBMLID(id)
+	rest := size - len(header) - len(sizeField) - len(payload)
+	if rest == 1 {
+		sizeField, err = encodeEBMLSize(len(payload), len(sizeField)+1)
+		if err != nil {
+			return nil, nil, false
+		}
+		rest = 0
+	}
+	if rest < 0 || rest == 1 {
+		return nil, nil, false
+	}
+
+	element := make([]byte, 0, size)
+	element = append(element, header...)
+	element = append(element, sizeField...)
+	element = append(element, payload...)
+	if rest == 0 {
+		return element, nil, true
+	}
+	void, err := ebmlVoid(rest)
+	if err != nil {
+		return nil, nil, false
+	}
+	return element, void, true
+}
+
+// ebmlString - string without zero padding
+func ebmlString(data []byte) string {
+	return strings.TrimRight(string(data), "\x00")
+}
@@ -18,4 +18,7 @@ var (
 	ErrWriting           = errors.New("writing error")
 	ErrDecodeEvenLength  = errors.New("must have even length byte slice")
 	ErrEncodingFormat    = errors.New("unknown encoding format")
+	ErrIncorrectValue    = errors.New("incorrect value")
+	ErrIncorrectCRC      = errors.New("incorrect CRC")
+	ErrFileChanged       = errors.New("file changed after reading")
 )
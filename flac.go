@@ -7,29 +7,23 @@ import (
 	"image/jpeg"
 	"image/png"
 	"io"
-	"io/ioutil"
 	"os"
-	"strconv"
 	"strings"
-	"time"
 )
 
 type FLAC struct {
 	Blocks []*FlacMetadataBlock
 
-	// Vorbis Comment
-	Vendor string
-	Tags   map[string]string
+	// Vorbis Comment, pictures are read from and written to PICTURE blocks
+	VorbisComments
 
-	Data []byte
-}
+	// Data - audio frames, nil if they are read from the source on demand
+	Data  []byte
+	audio *fileRegion
 
-func (flac *FLAC) GetAllTagNames() []string {
-	result := make([]string, 0, len(flac.Tags))
-	for key := range flac.Tags {
-		result = append(result, key)
-	}
-	return result
+	// Padding - size of padding block written by SaveInPlace if metadata doesn't fit into the file,
+	// DefaultPadding if 0, no padding if negative
+	Padding int
 }
 
 func (flac *FLAC) GetVersion() Version {
@@ -37,103 +31,11 @@ func (flac *FLAC) GetVersion() Version {
 }
 
 func (flac *FLAC) GetFileData() []byte {
-	return flac.Data
-}
-
-func (flac *FLAC) GetTitle() (string, error) {
-	return flac.GetVorbisComment("TITLE")
-}
-
-func (flac *FLAC) GetArtist() (string, error) {
-	return flac.GetVorbisComment("ARTIST")
-}
-
-func (flac *FLAC) GetAlbum() (string, error) {
-	return flac.GetVorbisComment("ALBUM")
-}
-
-func (flac *FLAC) GetYear() (int, error) {
-	return flac.GetVorbisCommentInt("YEAR")
-}
-
-func (flac *FLAC) GetComment() (string, error) {
-	return flac.GetVorbisComment("COMMENT")
-}
-
-func (flac *FLAC) GetGenre() (string, error) {
-	return flac.GetVorbisComment("GENRE")
+	return readSection(audioSection(flac.Data, flac.audio))
 }
 
-func (flac *FLAC) GetAlbumArtist() (string, error) {
-	return flac.GetVorbisComment("ALBUMARTIST")
-}
-
-func (flac *FLAC) GetDate() (time.Time, error) {
-	return flac.GetVorbisCommentTime("DATE")
-}
-
-func (flac *FLAC) GetArranger() (string, error) {
-	return flac.GetVorbisComment("ARRANGER")
-}
-
-func (flac *FLAC) GetAuthor() (string, error) {
-	return flac.GetVorbisComment("AUTHOR")
-}
-
-func (flac *FLAC) GetBPM() (int, error) {
-	return flac.GetVorbisCommentInt("BPM")
-}
-
-func (flac *FLAC) GetCatalogNumber() (string, error) {
-	return flac.GetVorbisComment("CATALOGNUMBER")
-}
-
-func (flac *FLAC) GetCompilation() (string, error) {
-	return flac.GetVorbisComment("COMPILATION")
-}
-
-func (flac *FLAC) GetComposer() (string, error) {
-	return flac.GetVorbisComment("COMPOSER")
-}
-
-func (flac *FLAC) GetConductor() (string, error) {
-	return flac.GetVorbisComment("CONDUCTOR")
-}
-
-func (flac *FLAC) GetCopyright() (string, error) {
-	return flac.GetVorbisComment("COPYRIGHT")
-}
-
-func (flac *FLAC) GetDescription() (string, error) {
-	return flac.GetVorbisComment("DESCRIPTION")
-}
-
-func (flac *FLAC) GetDiscNumber() (int, int, error) {
-	number, err := flac.GetVorbisCommentInt("DISCNUMBER")
-	if err != nil {
-		return 0, 0, err
-	}
-	total, err := flac.GetVorbisCommentInt("DISCTOTAL")
-	if err != nil {
-		return 0, 0, err
-	}
-	return number, total, nil
-}
-
-func (flac *FLAC) GetEncodedBy() (string, error) {
-	return flac.GetVorbisComment("ENCODED-BY")
-}
-
-func (flac *FLAC) GetTrackNumber() (int, int, error) {
-	number, err := flac.GetVorbisCommentInt("TRACKNUMBER")
-	if err != nil {
-		return 0, 0, err
-	}
-	total, err := flac.GetVorbisCommentInt("TRACKTOTAL")
-	if err != nil {
-		return 0, 0, err
-	}
-	return number, total, nil
+func (flac *FLAC) AudioReader() io.Reader {
+	return audioSection(flac.Data, flac.audio)
 }
 
 func (flac *FLAC) GetPicture() (image.Image, error) {
@@ -153,261 +55,109 @@ func (flac *FLAC) GetPicture() (image.Image, error) {
 	return nil, ErrIncorrectTag
 }
 
-func (flac *FLAC) SetTitle(title string) error {
-	flac.Tags["TITLE"] = title
-	return nil
-}
-
-func (flac *FLAC) SetArtist(artist string) error {
-	flac.Tags["ARTIST"] = artist
-	return nil
-}
-
-func (flac *FLAC) SetAlbum(album string) error {
-	flac.Tags["ALBUM"] = album
-	return nil
-}
-
-func (flac *FLAC) SetYear(year int) error {
-	flac.Tags["YEAR"] = strconv.Itoa(year)
-	return nil
-}
-
-func (flac *FLAC) SetComment(comment string) error {
-	flac.Tags["COMMENT"] = comment
-	return nil
-}
-
-func (flac *FLAC) SetGenre(genre string) error {
-	flac.Tags["GENRE"] = genre
-	return nil
-}
-
-func (flac *FLAC) SetAlbumArtist(albumArtist string) error {
-	flac.Tags["ALBUMARTIST"] = albumArtist
-	return nil
-}
-
-func (flac *FLAC) SetDate(date time.Time) error {
-	flac.Tags["DATE"] = date.Format("2006-01-02T15:04:05")
-	return nil
-}
-
-func (flac *FLAC) SetArranger(arranger string) error {
-	flac.Tags["ARRANGER"] = arranger
-	return nil
-}
-
-func (flac *FLAC) SetAuthor(author string) error {
-	flac.Tags["AUTHOR"] = author
-	return nil
-}
-
-func (flac *FLAC) SetBPM(bmp int) error {
-	flac.Tags["BMP"] = strconv.Itoa(bmp)
-	return nil
-}
-
-func (flac *FLAC) SetCatalogNumber(catalogNumber string) error {
-	flac.Tags["CATALOGNUMBER"] = catalogNumber
-	return nil
-}
-
-func (flac *FLAC) SetCompilation(compilation string) error {
-	flac.Tags["COMPILATION"] = compilation
-	return nil
-}
-
-func (flac *FLAC) SetComposer(composer string) error {
-	flac.Tags["COMPOSER"] = composer
-	return nil
-}
-
-func (flac *FLAC) SetConductor(conductor string) error {
-	flac.Tags["CONDUCTOR"] = conductor
-	return nil
-}
-
-func (flac *FLAC) SetCopyright(copyright string) error {
-	flac.Tags["COPYRIGHT"] = copyright
-	return nil
-}
-
-func (flac *FLAC) SetDescription(description string) error {
-	flac.Tags["DESCRIPTION"] = description
-	return nil
-}
-
-func (flac *FLAC) SetDiscNumber(number int, total int) error {
-	flac.Tags["DISCNUMBER"] = strconv.Itoa(number)
-	flac.Tags["DISCTOTAL"] = strconv.Itoa(total)
-	return nil
-}
-
-func (flac *FLAC) SetEncodedBy(encodedBy string) error {
-	flac.Tags["ENCODED-BY"] = encodedBy
-	return nil
-}
-
-func (flac *FLAC) SetTrackNumber(number int, total int) error {
-	flac.Tags["TRACKNUMBER"] = strconv.Itoa(number)
-	flac.Tags["TRACKTOTAL"] = strconv.Itoa(total)
-	return nil
-}
-
+// SetPicture - replace front cover with PNG picture
 func (flac *FLAC) SetPicture(picture image.Image) error {
-	// TODO
-	// bitsPerPixel := colorModelToBitsPerPixel(picture.ColorModel())
-	// pictureBlock := FlacMetadataBlockPicture{
-	//	Type:           3, // Other type
-	//	MIME:           "image/png",
-	//	Description:    "",
-	//	Width:          int32(picture.Bounds().Size().X),
-	//	Height:         int32(picture.Bounds().Size().Y),
-	//	BitsPerPixel:   int32(bitsPerPixel),
-	//	NumberOfColors: 0,
-	//	PictureData:    nil,
-	//}
-	var size int
-	var data []byte
-
-	for _, val := range flac.Blocks {
-		if val.Type == FlacPicture {
-			val.Size = size
-			val.Data = data
-		}
+	buf := new(bytes.Buffer)
+	err := png.Encode(buf, picture)
+	if err != nil {
+		return err
 	}
-	return nil
-}
-
-func (flac *FLAC) DeleteAll() error {
-	flac.Tags = map[string]string{}
-	return nil
-}
-
-func (flac *FLAC) DeleteTitle() error {
-	delete(flac.Tags, "TITLE")
-	return nil
-}
-
-func (flac *FLAC) DeleteArtist() error {
-	delete(flac.Tags, "ARTIST")
-	return nil
-}
-
-func (flac *FLAC) DeleteAlbum() error {
-	delete(flac.Tags, "ALBUM")
-	return nil
-}
-
-func (flac *FLAC) DeleteYear() error {
-	delete(flac.Tags, "YEAR")
-	return nil
-}
-
-func (flac *FLAC) DeleteComment() error {
-	delete(flac.Tags, "COMMENT")
-	return nil
-}
-
-func (flac *FLAC) DeleteGenre() error {
-	delete(flac.Tags, "GENRE")
-	return nil
+	return flac.ReplacePicture(AttachedPicture{
+		MIME:        mimeImagePNG,
+		PictureType: PictureTypeFrontCover,
+		Data:        buf.Bytes(),
+	})
 }
 
-func (flac *FLAC) DeleteAlbumArtist() error {
-	delete(flac.Tags, "ALBUMARTIST")
-	return nil
-}
-
-func (flac *FLAC) DeleteDate() error {
-	delete(flac.Tags, "DATE")
-	return nil
-}
-
-func (flac *FLAC) DeleteArranger() error {
-	delete(flac.Tags, "ARRANGER")
-	return nil
-}
-
-func (flac *FLAC) DeleteAuthor() error {
-	delete(flac.Tags, "AUTHOR")
-	return nil
-}
-
-func (flac *FLAC) DeleteBPM() error {
-	delete(flac.Tags, "BPM")
-	return nil
-}
-
-func (flac *FLAC) DeleteCatalogNumber() error {
-	delete(flac.Tags, "CATALOGNUMBER")
-	return nil
-}
-
-func (flac *FLAC) DeleteCompilation() error {
-	delete(flac.Tags, "COMPILATION")
-	return nil
-}
-
-func (flac *FLAC) DeleteComposer() error {
-	delete(flac.Tags, "COMPOSER")
-	return nil
-}
-
-func (flac *FLAC) DeleteConductor() error {
-	delete(flac.Tags, "CONDUCTOR")
-	return nil
-}
-
-func (flac *FLAC) DeleteCopyright() error {
-	delete(flac.Tags, "COPYRIGHT")
-	return nil
+// Pictures - pictures of all PICTURE blocks, malformed blocks are skipped
+func (flac *FLAC) Pictures() []AttachedPicture {
+	var result []AttachedPicture
+	for _, block := range flac.Blocks {
+		if block.Type != FlacPicture {
+			continue
+		}
+		picture, err := readFlacPicture(bytes.NewReader(block.Data))
+		if err != nil {
+			continue
+		}
+		result = append(result, AttachedPicture{
+			MIME:        picture.MIME,
+			PictureType: byte(picture.Type),
+			Description: picture.Description,
+			Data:        picture.PictureData,
+		})
+	}
+	return result
 }
 
-func (flac *FLAC) DeleteDescription() error {
-	delete(flac.Tags, "DESCRIPTION")
-	return nil
-}
+// AddPicture - add PICTURE block, size and color depth are read from JPEG and PNG data
+func (flac *FLAC) AddPicture(picture AttachedPicture) error {
+	block := FlacMetadataBlockPicture{
+		Type:        int32(picture.PictureType),
+		MIME:        picture.MIME,
+		Description: picture.Description,
+		PictureData: picture.Data,
+	}
+	// 0 if unknown
+	config, _, err := image.DecodeConfig(bytes.NewReader(picture.Data))
+	if err == nil {
+		block.Width = int32(config.Width)
+		block.Height = int32(config.Height)
+		block.BitsPerPixel = int32(colorModelToBitsPerPixel(config.ColorModel))
+	}
 
-func (flac *FLAC) DeleteDiscNumber() error {
-	delete(flac.Tags, "DISCNUMBER")
-	delete(flac.Tags, "DISCTOTAL")
+	data := new(bytes.Buffer)
+	err = writeFlacPicture(data, &block)
+	if err != nil {
+		return err
+	}
+	flac.Blocks = append(flac.Blocks, &FlacMetadataBlock{
+		Type: FlacPicture,
+		Size: data.Len(),
+		Data: data.Bytes(),
+	})
 	return nil
 }
 
-func (flac *FLAC) DeleteEncodedBy() error {
-	delete(flac.Tags, "ENCODED-BY")
+// RemovePicture - delete PICTURE blocks of the picture type
+func (flac *FLAC) RemovePicture(pictureType byte) error {
+	blocks := flac.Blocks[:0]
+	for _, block := range flac.Blocks {
+		if block.Type == FlacPicture {
+			picture, err := readFlacPicture(bytes.NewReader(block.Data))
+			if err == nil && picture.Type == int32(pictureType) {
+				continue
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	flac.Blocks = blocks
 	return nil
 }
 
-func (flac *FLAC) DeleteTrackNumber() error {
-	delete(flac.Tags, "TRACKNUMBER")
-	delete(flac.Tags, "TRACKTOTAL")
-	return nil
+// ReplacePicture - replace PICTURE blocks of the picture type with the picture
+func (flac *FLAC) ReplacePicture(picture AttachedPicture) error {
+	err := flac.RemovePicture(picture.PictureType)
+	if err != nil {
+		return err
+	}
+	return flac.AddPicture(picture)
 }
 
+// DeletePicture - delete all PICTURE blocks
 func (flac *FLAC) DeletePicture() error {
-	index := -1
-	for i, val := range flac.Blocks {
-		if val.Type == FlacPicture {
-			index = i
+	blocks := flac.Blocks[:0]
+	for _, block := range flac.Blocks {
+		if block.Type != FlacPicture {
+			blocks = append(blocks, block)
 		}
 	}
-	if index != -1 {
-		flac.Blocks = append(flac.Blocks[:index], flac.Blocks[index+1:]...)
-	}
+	flac.Blocks = blocks
 	return nil
 }
 
 func (flac *FLAC) SaveFile(path string) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	return flac.Save(file)
+	return saveFile(path, flac.Save, SaveOptions{})
 }
 
 func (flac *FLAC) Save(input io.WriteSeeker) error {
@@ -420,6 +170,64 @@ func (flac *FLAC) Save(input io.WriteSeeker) error {
 	if _, err := input.Write([]byte(FLACIdentifier)); err != nil {
 		return err
 	}
+	flac.syncVorbisComment()
+
+	for i, meta := range flac.Blocks {
+		last := i == len(flac.Blocks)-1
+		if err := meta.Write(input, last); err != nil {
+			return err
+		}
+	}
+	return writeAudio(input, flac.Data, flac.audio)
+}
+
+// SaveInPlace - write metadata blocks over the metadata and padding blocks of file read by ReadFLAC.
+// If they don't fit, audio is moved and padding block of Padding size is written
+func (flac *FLAC) SaveInPlace(file *os.File) error {
+	flac.syncVorbisComment()
+
+	blocks := make([]*FlacMetadataBlock, 0, len(flac.Blocks)+1)
+	size := int64(len(FLACIdentifier))
+	for _, block := range flac.Blocks {
+		if block.Type != FlacPadding {
+			blocks = append(blocks, block)
+			size += 4 + int64(len(block.Data))
+		}
+	}
+
+	padding := inPlacePadding(flac.Padding)
+	if padding > 0 {
+		// padding block header
+		padding += 4
+	}
+	length, err := saveInPlace(file, flac.Data, flac.audio, size, 4, padding,
+		func(length int64) ([]byte, error) {
+			output := new(bytes.Buffer)
+			output.WriteString(FLACIdentifier)
+			withPadding := blocks
+			if length > size {
+				withPadding = append(withPadding, &FlacMetadataBlock{Type: FlacPadding, Data: make([]byte, length-size-4)})
+			}
+			for i, block := range withPadding {
+				if err := block.Write(output, i == len(withPadding)-1); err != nil {
+					return nil, err
+				}
+			}
+			return output.Bytes(), nil
+		})
+	if err != nil {
+		return err
+	}
+
+	if length > size {
+		blocks = append(blocks, &FlacMetadataBlock{Type: FlacPadding, Data: make([]byte, length-size-4)})
+	}
+	flac.Blocks = blocks
+	return nil
+}
+
+// syncVorbisComment - serialize tags to Vorbis comment block, insert it after STREAMINFO if there is no such block
+func (flac *FLAC) syncVorbisComment() {
 	metadataWritten := false
 	for i, meta := range flac.Blocks {
 		if meta.Type == FlacVorbisComment {
@@ -431,7 +239,7 @@ func (flac *FLAC) Save(input io.WriteSeeker) error {
 		//
 	}
 	if !metadataWritten {
-		// Append a metadata block to the list
+		// Insert a metadata block after STREAMINFO, it must be the first block
 		data := serializeVorbisComments(flac.Tags, flac.Vendor)
 		block := &FlacMetadataBlock{
 			IsLast: false,
@@ -439,19 +247,12 @@ func (flac *FLAC) Save(input io.WriteSeeker) error {
 			Size:   len(data),
 			Data:   data,
 		}
-		flac.Blocks = append(flac.Blocks, block)
-	}
-
-	for i, meta := range flac.Blocks {
-		last := i == len(flac.Blocks)-1
-		if err := meta.Write(input, last); err != nil {
-			return err
+		index := 0
+		if len(flac.Blocks) > 0 && flac.Blocks[0].Type == FlacStreamInfo {
+			index = 1
 		}
+		flac.Blocks = append(flac.Blocks[:index], append([]*FlacMetadataBlock{block}, flac.Blocks[index:]...)...)
 	}
-	if _, err := input.Write(flac.Data); err != nil {
-		return err
-	}
-	return nil
 }
 
 func checkFLAC(input io.ReadSeeker) bool {
@@ -471,6 +272,7 @@ type FlacMetadataBlockType byte
 
 /*
 BLOCK_TYPE:
+
 	0 : STREAMINFO
 	1 : PADDING
 	2 : APPLICATION
@@ -501,9 +303,27 @@ type FlacMetadataBlock struct {
 	Data   []byte
 }
 
+// NewFLACTags - FLAC stream with empty Vorbis comments and without pictures
+func NewFLACTags(input io.ReadSeeker) (*FLAC, error) {
+	flac, err := ReadFLAC(input)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := flac.Blocks[:0]
+	for _, block := range flac.Blocks {
+		if block.Type != FlacPicture {
+			blocks = append(blocks, block)
+		}
+	}
+	flac.Blocks = blocks
+	flac.Tags = map[string][]string{}
+	return flac, nil
+}
+
 func ReadFLAC(input io.ReadSeeker) (*FLAC, error) {
 	flac := FLAC{
-		Tags: map[string]string{},
+		VorbisComments: VorbisComments{Tags: map[string][]string{}},
 	}
 
 	// FLAC identifier
@@ -537,7 +357,7 @@ func ReadFLAC(input io.ReadSeeker) (*FLAC, error) {
 			for i := range comments {
 				// case insensitive
 				field := strings.ToUpper(comments[i].Name)
-				flac.Tags[field] = comments[i].Value
+				flac.Tags[field] = append(flac.Tags[field], comments[i].Value)
 			}
 		} else {
 			flac.Blocks = append(flac.Blocks, block)
@@ -549,8 +369,12 @@ func ReadFLAC(input io.ReadSeeker) (*FLAC, error) {
 		}
 	}
 
-	// Read all remaining file data into the Data slice
-	flac.Data, err = ioutil.ReadAll(input)
+	// Remaining file data is read from the source on demand
+	offset, err := input.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	flac.audio, err = readRegion(input, offset, -1)
 	if err != nil {
 		return nil, err
 	}
@@ -613,127 +437,6 @@ func (block *FlacMetadataBlock) Write(w io.Writer, isLast bool) error {
 	return nil
 }
 
-type VorbisComment struct {
-	Name  string
-	Value string
-}
-
-func (flac *FLAC) GetVorbisComment(key string) (string, error) {
-	val, ok := flac.Tags[key]
-	if !ok {
-		return "", ErrTagNotFound
-	}
-	return val, nil
-}
-
-// The comment header is decoded as follows:
-//
-//	1) [vendor_length] = read an unsigned integer of 32 bits
-//	2) [vendor_string] = read a UTF-8 vector as [vendor_length] octets
-//	3) [user_comment_list_length] = read an unsigned integer of 32 bits
-//	4) iterate [user_comment_list_length] times {
-//
-//		5) [length] = read an unsigned integer of 32 bits
-//		6) this iteration's user comment = read a UTF-8 vector as [length] octets
-//
-//	}
-//
-//	7) [framing_bit] = read a single bit as boolean
-func readVorbisComments(input io.Reader) ([]VorbisComment, string, error) {
-	result := []VorbisComment{}
-
-	// vendor
-	vendorByte, err := readLengthData(input, binary.LittleEndian)
-	if err != nil {
-		return nil, "", err
-	}
-
-	// user_comment_list_length
-	var length uint32
-	err = binary.Read(input, binary.LittleEndian, &length)
-	if err != nil {
-		return nil, "", err
-	}
-
-	// iterate
-	for i := 0; i < int(length); i++ {
-		data, err := readLengthData(input, binary.LittleEndian)
-		if err != nil {
-			return nil, "", err
-		}
-
-		// Parse data
-		vorbis := strings.SplitN(string(data), "=", 2)
-		if len(vorbis) != 2 {
-			return nil, "", ErrIncorrectTag
-		}
-
-		comment := VorbisComment{
-			Name:  vorbis[0],
-			Value: vorbis[1],
-		}
-		result = append(result, comment)
-	}
-	return result, string(vendorByte), nil
-}
-
-func serializeVorbisComments(comments map[string]string, vendorHeader string) []byte {
-	// Serialize out the vorbis comments as a metadata block payload
-	// Spawn out all the tag blobs first
-	output := bytes.NewBuffer([]byte{})
-	for key, value := range comments {
-		line := key + "=" + value
-		if err := writeLengthData(output, binary.LittleEndian, []byte(line)); err != nil {
-			return []byte{}
-		}
-	}
-
-	// Now that we have the payload content figured out, we can reconstruct its headers
-	dataPayload, err := ioutil.ReadAll(output)
-	if err != nil {
-		return []byte{}
-	}
-
-	output.Grow(len(vendorHeader) + 4)
-	output.Reset() // clear our scratchpad
-	if err = writeLengthData(output, binary.LittleEndian, []byte(vendorHeader)); err != nil {
-		return []byte{}
-	}
-
-	userCommentLength := uint32(len(comments))
-	if err = binary.Write(output, binary.LittleEndian, userCommentLength); err != nil {
-		return []byte{}
-	}
-	output.Write(dataPayload)
-
-	payload, err := ioutil.ReadAll(output)
-	if err != nil {
-		return []byte{}
-	}
-
-	return payload
-}
-func (flac *FLAC) GetVorbisCommentInt(key string) (int, error) {
-	comment, err := flac.GetVorbisComment(key)
-	if err != nil {
-		return 0, err
-	}
-
-	return strconv.Atoi(comment)
-}
-
-func (flac *FLAC) GetVorbisCommentTime(key string) (time.Time, error) {
-	comment, err := flac.GetVorbisComment(key)
-	if err != nil {
-		return time.Now(), err
-	}
-	result, err := time.Parse("2006-01-02T15:04:05", comment)
-	if err != nil {
-		return time.Now(), err
-	}
-	return result, nil
-}
-
 type FlacMetadataBlockPicture struct {
 	Type           int32
 	MIME           string
@@ -810,3 +513,34 @@ func readFlacPicture(input io.Reader) (*FlacMetadataBlockPicture, error) {
 
 	return &picture, nil
 }
+
+func writeFlacPicture(output io.Writer, picture *FlacMetadataBlockPicture) error {
+	// Picture type
+	err := binary.Write(output, binary.BigEndian, picture.Type)
+	if err != nil {
+		return err
+	}
+
+	// MIME
+	err = writeLengthData(output, binary.BigEndian, []byte(picture.MIME))
+	if err != nil {
+		return err
+	}
+
+	// Description
+	err = writeLengthData(output, binary.BigEndian, []byte(picture.Description))
+	if err != nil {
+		return err
+	}
+
+	// Width, height, bits per pixel, number of colors
+	for _, value := range []int32{picture.Width, picture.Height, picture.BitsPerPixel, picture.NumberOfColors} {
+		err = binary.Write(output, binary.BigEndian, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Picture data
+	return writeLengthData(output, binary.BigEndian, picture.PictureData)
+}
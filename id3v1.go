@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"image"
 	"io"
-	"io/ioutil"
-	"os"
 	"strconv"
 	"time"
 )
@@ -23,12 +21,17 @@ type ID3v1 struct {
 	Track    byte   // length 1. The number of the track on the album, or 0. Invalid, if previous byte is not a binary 0.
 	Genre    Genre  // length 1. Index in a list of genres, or 255
 
-	// another file data
-	Data []byte
+	// Data - another file data, nil if it is read from the source on demand
+	Data  []byte
+	audio *fileRegion
 }
 
 func (id3v1 *ID3v1) GetFileData() []byte {
-	return id3v1.Data
+	return readSection(audioSection(id3v1.Data, id3v1.audio))
+}
+
+func (id3v1 *ID3v1) AudioReader() io.Reader {
+	return audioSection(id3v1.Data, id3v1.audio)
 }
 
 func (id3v1 *ID3v1) String() string {
@@ -103,18 +106,15 @@ func ReadID3v1(input io.ReadSeeker) (*ID3v1, error) {
 	// Index in a list of genres, or 255
 	header.Genre = Genre(headerByte[127])
 
-	// Read another file data
-	_, err = input.Seek(0, io.SeekStart)
+	// Read another file data without header
+	end, err := input.Seek(0, io.SeekEnd)
 	if err != nil {
 		return nil, err
 	}
-
-	data, err := ioutil.ReadAll(input)
+	header.audio, err = readRegion(input, 0, end-id3v1SizeHeader)
 	if err != nil {
 		return nil, err
 	}
-	// without header
-	header.Data = data[:len(data)-128]
 
 	return &header, nil
 }
@@ -129,17 +129,11 @@ func stringBeforeZero(data []byte) string {
 }
 
 func (id3v1 *ID3v1) SaveFile(path string) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	return id3v1.Save(file)
+	return saveFile(path, id3v1.Save, SaveOptions{})
 }
 
 func (id3v1 *ID3v1) Save(input io.WriteSeeker) error {
-	_, err := input.Write(id3v1.Data)
+	err := writeAudio(input, id3v1.Data, id3v1.audio)
 	if err != nil {
 		return err
 	}
@@ -331,6 +325,10 @@ func (id3v1 *ID3v1) GetPicture() (image.Image, error) {
 	return nil, ErrUnsupportedTag
 }
 
+func (id3v1 *ID3v1) GetLyrics(language string) (string, error) {
+	return "", ErrUnsupportedTag
+}
+
 func (id3v1 *ID3v1) SetTitle(title string) error {
 	if len(title) > 30 {
 		return ErrIncorrectLength
@@ -445,6 +443,10 @@ func (id3v1 *ID3v1) SetPicture(picture image.Image) error {
 	return ErrUnsupportedTag
 }
 
+func (id3v1 *ID3v1) SetLyrics(language string, lyrics string) error {
+	return ErrUnsupportedTag
+}
+
 func (id3v1 *ID3v1) DeleteAll() error {
 	id3v1.Title = ""
 	id3v1.Artist = ""
@@ -548,3 +550,7 @@ func (id3v1 *ID3v1) DeleteTrackNumber() error {
 func (id3v1 *ID3v1) DeletePicture() error {
 	return ErrUnsupportedTag
 }
+
+func (id3v1 *ID3v1) DeleteLyrics(language string) error {
+	return ErrUnsupportedTag
+}
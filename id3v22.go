@@ -3,29 +3,67 @@ package tag
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"image"
 	"image/jpeg"
 	"image/png"
 	"io"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 )
 
+type id3v22Flags byte
+
+func (flags id3v22Flags) String() string {
+	return strconv.Itoa(int(flags))
+}
+
+func (flags id3v22Flags) IsUnsynchronisation() bool {
+	return GetBit(byte(flags), 7) == 1
+}
+
+func (flags *id3v22Flags) SetUnsynchronisation(data bool) {
+	SetBit((*byte)(flags), data, 7)
+}
+
+func (flags id3v22Flags) IsCompression() bool {
+	return GetBit(byte(flags), 6) == 1
+}
+
 type ID3v22Frame struct {
 	Key   string
 	Value []byte
 }
 
 type ID3v22 struct {
-	Marker string // Always
-	Length int
-	Frames []ID3v22Frame
+	Marker     string // Always 'ID3'
+	SubVersion int
+	Flags      id3v22Flags
+	Length     int
+	Frames     []ID3v22Frame
+
+	// Data - audio data after tag, nil if audio is read from the source on demand
+	Data  []byte
+	audio *fileRegion
+
+	// Padding - padding written by SaveInPlace if tag doesn't fit into the file,
+	// DefaultPadding if 0, no padding if negative
+	Padding int
+
+	// Encoding - text encoding of frames set by setters: EncodingISO88591 or EncodingUTF16.
+	// If empty, ISO-8859-1 is used if text is representable, UTF-16 otherwise
+	Encoding string
 }
 
 func (id3v2 *ID3v22) GetAllTagNames() []string {
-	panic("implement me")
+	var result []string
+	for i := range id3v2.Frames {
+		result = append(result, id3v2.Frames[i].Key)
+	}
+	return result
 }
 
 func (id3v2 *ID3v22) GetVersion() Version {
@@ -33,7 +71,11 @@ func (id3v2 *ID3v22) GetVersion() Version {
 }
 
 func (id3v2 *ID3v22) GetFileData() []byte {
-	panic("implement me")
+	return readSection(audioSection(id3v2.Data, id3v2.audio))
+}
+
+func (id3v2 *ID3v22) AudioReader() io.Reader {
+	return audioSection(id3v2.Data, id3v2.audio)
 }
 
 func (id3v2 *ID3v22) GetTitle() (string, error) {
@@ -49,15 +91,12 @@ func (id3v2 *ID3v22) GetAlbum() (string, error) {
 }
 
 func (id3v2 *ID3v22) GetYear() (int, error) {
-	year, err := id3v2.GetString("TYE")
-	if err != nil {
-		return 0, nil
-	}
-	return strconv.Atoi(year)
+	return id3v2.GetInt("TYE")
 }
 
+// GetComment - comment without short content description of any language
 func (id3v2 *ID3v22) GetComment() (string, error) {
-	panic("implement me")
+	return id3v2.GetCommentByKey("", "")
 }
 
 func (id3v2 *ID3v22) GetGenre() (string, error) {
@@ -74,55 +113,62 @@ func (id3v2 *ID3v22) GetGenre() (string, error) {
 		}
 		return genres[Genre(code)], nil
 	}
-	return "", nil
+	return genre, nil
 }
 
 func (id3v2 *ID3v22) GetAlbumArtist() (string, error) {
-	panic("implement me")
+	return id3v2.GetString("TP2")
 }
 
+// GetDate - combine year (TYE), day and month (TDA - DDMM) and time (TIM - HHMM).
 func (id3v2 *ID3v22) GetDate() (time.Time, error) {
-	panic("implement me")
+	year, err := id3v2.GetString("TYE")
+	if err != nil {
+		return time.Now(), err
+	}
+	date, _ := id3v2.GetString("TDA")
+	clock, _ := id3v2.GetString("TIM")
+	return parseID3v23Date(year, date, clock)
 }
 
 func (id3v2 *ID3v22) GetArranger() (string, error) {
-	panic("implement me")
+	return id3v2.GetString("IPL")
 }
 
 func (id3v2 *ID3v22) GetAuthor() (string, error) {
-	panic("implement me")
+	return id3v2.GetString("TOL")
 }
 
 func (id3v2 *ID3v22) GetBPM() (int, error) {
-	panic("implement me")
+	return id3v2.GetInt("TBP")
 }
 
 func (id3v2 *ID3v22) GetCatalogNumber() (string, error) {
-	panic("implement me")
+	return id3v2.GetStringTXX("CATALOGNUMBER")
 }
 
 func (id3v2 *ID3v22) GetCompilation() (string, error) {
-	panic("implement me")
+	return id3v2.GetString("TCP")
 }
 
 func (id3v2 *ID3v22) GetComposer() (string, error) {
-	panic("implement me")
+	return id3v2.GetString("TCM")
 }
 
 func (id3v2 *ID3v22) GetConductor() (string, error) {
-	panic("implement me")
+	return id3v2.GetString("TP3")
 }
 
 func (id3v2 *ID3v22) GetCopyright() (string, error) {
-	panic("implement me")
+	return id3v2.GetString("TCR")
 }
 
 func (id3v2 *ID3v22) GetDescription() (string, error) {
-	panic("implement me")
+	return id3v2.GetString("TT3")
 }
 
 func (id3v2 *ID3v22) GetDiscNumber() (int, int, error) {
-	panic("implement me")
+	return id3v2.GetNumberTotal("TPA")
 }
 
 func (id3v2 *ID3v22) GetEncodedBy() (string, error) {
@@ -130,30 +176,7 @@ func (id3v2 *ID3v22) GetEncodedBy() (string, error) {
 }
 
 func (id3v2 *ID3v22) GetTrackNumber() (int, int, error) {
-	track, err := id3v2.GetString("TRK")
-	if err != nil {
-		return 0, 0, err
-	}
-	parts := strings.Split(track, "/")
-	if len(parts) == 1 {
-		number, err := strconv.Atoi(parts[0])
-		if err != nil {
-			return 0, 0, err
-		}
-		return number, number, nil
-	} else if len(parts) == 2 {
-		number1, err := strconv.Atoi(parts[0])
-		if err != nil {
-			return 0, 0, err
-		}
-		number2, err := strconv.Atoi(parts[1])
-		if err != nil {
-			return 0, 0, err
-		}
-		return number1, number2, nil
-	}
-
-	return 0, 0, ErrIncorrectTag
+	return id3v2.GetNumberTotal("TRK")
 }
 
 func (id3v2 *ID3v22) GetPicture() (image.Image, error) {
@@ -166,30 +189,57 @@ func (id3v2 *ID3v22) GetPicture() (image.Image, error) {
 		return jpeg.Decode(bytes.NewReader(pic.Data))
 	case mimeImagePNG:
 		return png.Decode(bytes.NewReader(pic.Data))
+	case mimeImageLink:
+		return downloadImage(string(pic.Data))
 	default:
 		return nil, ErrIncorrectTag
 	}
 }
 
-func (id3v2 *ID3v22) GetAttachedPicture() (*AttachedPicture, error) {
-	var picture AttachedPicture
+// GetLyrics - text of ULT frame with language, of any language if it's empty
+func (id3v2 *ID3v22) GetLyrics(language string) (string, error) {
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "ULT" {
+			continue
+		}
+		// malformed frames are skipped
+		lyrics, err := parseComment(id3v2.Frames[i].Value)
+		if err != nil {
+			continue
+		}
+		if language == "" || strings.EqualFold(lyrics.Language, language) {
+			return lyrics.Text, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
 
-	bytes, err := id3v2.GetBytes("PIC")
+// GetAttachedPicture - read PIC frame
+func (id3v2 *ID3v22) GetAttachedPicture() (*AttachedPicture, error) {
+	value, err := id3v2.GetBytes("PIC")
 	if err != nil {
 		return nil, err
 	}
+	return parseID3v22Picture(value)
+}
 
-	textEncoding := bytes[0]
-	mimeText := string(bytes[1:4])
-	if mimeText == "JPG" {
-		picture.MIME = mimeImageJPEG
-	} else if mimeText == "PNG" {
-		picture.MIME = mimeImagePNG
+// parseID3v22Picture - read PIC frame
+// Text encoding      $xx
+// Image format       $xx xx xx
+// Picture type       $xx
+// Description        <textstring> $00 (00)
+// Picture data       <binary data>.
+func parseID3v22Picture(value []byte) (*AttachedPicture, error) {
+	var picture AttachedPicture
+	if len(value) < 5 {
+		return nil, ErrIncorrectLength
 	}
 
-	picture.PictureType = bytes[4]
+	textEncoding := value[0]
+	picture.MIME = id3v22ImageFormatToMIME(string(value[1:4]))
+	picture.PictureType = value[4]
 
-	values := SplitBytesWithTextDescription(bytes[5:], GetEncoding(textEncoding))
+	values := SplitBytesWithTextDescription(value[5:], GetEncoding(textEncoding))
 	if len(values) != 2 {
 		return nil, ErrIncorrectTag
 	}
@@ -205,187 +255,598 @@ func (id3v2 *ID3v22) GetAttachedPicture() (*AttachedPicture, error) {
 	return &picture, nil
 }
 
+// nolint:gocritic
+func (id3v2 *ID3v22) SetAttachedPicture(picture *AttachedPicture) error {
+	value, err := id3v2.encodePicture(picture)
+	if err != nil {
+		return err
+	}
+	return id3v2.SetBytes("PIC", value)
+}
+
+// encodePicture - PIC frame with description in Encoding
+func (id3v2 *ID3v22) encodePicture(picture *AttachedPicture) ([]byte, error) {
+	description, err := id3v2.encodeText(picture.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []byte{description[0]}
+	// Image format
+	result = append(result, id3v22MIMEToImageFormat(picture.MIME)...)
+	// Picture type
+	result = append(result, picture.PictureType)
+	// Picture description
+	result = append(result, description[1:]...)
+	result = append(result, textTerminator(description[0])...)
+	// Picture data
+	return append(result, picture.Data...), nil
+}
+
+// Pictures - pictures of all PIC frames, malformed frames are skipped
+func (id3v2 *ID3v22) Pictures() []AttachedPicture {
+	var result []AttachedPicture
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "PIC" {
+			continue
+		}
+		picture, err := parseID3v22Picture(id3v2.Frames[i].Value)
+		if err == nil {
+			result = append(result, *picture)
+		}
+	}
+	return result
+}
+
+// AddPicture - add PIC frame. Pictures of the same type must have different descriptions
+func (id3v2 *ID3v22) AddPicture(picture AttachedPicture) error {
+	value, err := id3v2.encodePicture(&picture)
+	if err != nil {
+		return err
+	}
+	id3v2.Frames = append(id3v2.Frames, ID3v22Frame{
+		Key:   "PIC",
+		Value: value,
+	})
+	return nil
+}
+
+// RemovePicture - delete PIC frames of the picture type
+func (id3v2 *ID3v22) RemovePicture(pictureType byte) error {
+	frames := id3v2.Frames[:0]
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == "PIC" {
+			picture, err := parseID3v22Picture(id3v2.Frames[i].Value)
+			if err == nil && picture.PictureType == pictureType {
+				continue
+			}
+		}
+		frames = append(frames, id3v2.Frames[i])
+	}
+	id3v2.Frames = frames
+	return nil
+}
+
+// ReplacePicture - replace PIC frames of the picture type with the picture
+func (id3v2 *ID3v22) ReplacePicture(picture AttachedPicture) error {
+	err := id3v2.RemovePicture(picture.PictureType)
+	if err != nil {
+		return err
+	}
+	return id3v2.AddPicture(picture)
+}
+
+func id3v22ImageFormatToMIME(format string) string {
+	switch strings.ToUpper(format) {
+	case "JPG":
+		return mimeImageJPEG
+	case "PNG":
+		return mimeImagePNG
+	case mimeImageLink:
+		return mimeImageLink
+	}
+	return "image/" + strings.ToLower(format)
+}
+
+// Image format is 3 characters. E.g. 'PNG' or 'JPG'.
+func id3v22MIMEToImageFormat(mime string) string {
+	switch mime {
+	case mimeImageJPEG:
+		return "JPG"
+	case mimeImagePNG:
+		return "PNG"
+	case mimeImageLink:
+		return mimeImageLink
+	}
+	format := strings.ToUpper(strings.TrimPrefix(mime, "image/")) + "   "
+	return format[:3]
+}
+
 func (id3v2 *ID3v22) SetTitle(title string) error {
-	panic("implement me")
+	return id3v2.SetString("TT2", title)
 }
 
 func (id3v2 *ID3v22) SetArtist(artist string) error {
-	panic("implement me")
+	return id3v2.SetString("TP1", artist)
 }
 
 func (id3v2 *ID3v22) SetAlbum(album string) error {
-	panic("implement me")
+	return id3v2.SetString("TAL", album)
 }
 
 func (id3v2 *ID3v22) SetYear(year int) error {
-	panic("implement me")
+	return id3v2.SetInt("TYE", year)
 }
 
+// SetComment - set comment without short content description, keep its language
 func (id3v2 *ID3v22) SetComment(comment string) error {
-	panic("implement me")
+	language := "eng"
+	for _, found := range id3v2.Comments() {
+		if found.Description == "" {
+			language = found.Language
+			break
+		}
+	}
+	return id3v2.SetCommentByKey(Comment{Language: language, Text: comment})
 }
 
 func (id3v2 *ID3v22) SetGenre(genre string) error {
-	panic("implement me")
+	return id3v2.SetString("TCO", genre)
 }
 
 func (id3v2 *ID3v22) SetAlbumArtist(albumArtist string) error {
-	panic("implement me")
+	return id3v2.SetString("TP2", albumArtist)
 }
 
 func (id3v2 *ID3v22) SetDate(date time.Time) error {
-	panic("implement me")
+	err := id3v2.SetInt("TYE", date.Year())
+	if err != nil {
+		return err
+	}
+	err = id3v2.SetString("TDA", date.Format("0201"))
+	if err != nil {
+		return err
+	}
+	return id3v2.SetString("TIM", date.Format("1504"))
 }
 
 func (id3v2 *ID3v22) SetArranger(arranger string) error {
-	panic("implement me")
+	return id3v2.SetString("IPL", arranger)
 }
 
 func (id3v2 *ID3v22) SetAuthor(author string) error {
-	panic("implement me")
+	return id3v2.SetString("TOL", author)
 }
 
 func (id3v2 *ID3v22) SetBPM(bmp int) error {
-	panic("implement me")
+	return id3v2.SetInt("TBP", bmp)
 }
 
 func (id3v2 *ID3v22) SetCatalogNumber(catalogNumber string) error {
-	panic("implement me")
+	return id3v2.SetStringTXX("CATALOGNUMBER", catalogNumber)
 }
 
 func (id3v2 *ID3v22) SetCompilation(compilation string) error {
-	panic("implement me")
+	return id3v2.SetString("TCP", compilation)
 }
 
 func (id3v2 *ID3v22) SetComposer(composer string) error {
-	panic("implement me")
+	return id3v2.SetString("TCM", composer)
 }
 
 func (id3v2 *ID3v22) SetConductor(conductor string) error {
-	panic("implement me")
+	return id3v2.SetString("TP3", conductor)
 }
 
 func (id3v2 *ID3v22) SetCopyright(copyright string) error {
-	panic("implement me")
+	return id3v2.SetString("TCR", copyright)
 }
 
 func (id3v2 *ID3v22) SetDescription(description string) error {
-	panic("implement me")
+	return id3v2.SetString("TT3", description)
 }
 
 func (id3v2 *ID3v22) SetDiscNumber(number int, total int) error {
-	panic("implement me")
+	return id3v2.SetString("TPA", fmt.Sprintf("%d/%d", number, total))
 }
 
 func (id3v2 *ID3v22) SetEncodedBy(encodedBy string) error {
-	panic("implement me")
+	return id3v2.SetString("TEN", encodedBy)
 }
 
 func (id3v2 *ID3v22) SetTrackNumber(number int, total int) error {
-	panic("implement me")
+	return id3v2.SetString("TRK", fmt.Sprintf("%d/%d", number, total))
 }
 
 func (id3v2 *ID3v22) SetPicture(picture image.Image) error {
-	panic("implement me")
+	// Only PNG
+	buf := new(bytes.Buffer)
+	err := png.Encode(buf, picture)
+	if err != nil {
+		return err
+	}
+
+	attacheched, err := id3v2.GetAttachedPicture()
+	if err != nil {
+		// Set default params
+		newPicture := AttachedPicture{
+			MIME:        mimeImagePNG,
+			PictureType: PictureTypeFrontCover,
+			Description: "",
+			Data:        buf.Bytes(),
+		}
+		return id3v2.SetAttachedPicture(&newPicture)
+	}
+	// save metainfo
+	attacheched.MIME = mimeImagePNG
+	attacheched.Data = buf.Bytes()
+
+	return id3v2.SetAttachedPicture(attacheched)
+}
+
+// SetLyrics - replace text of ULT frame with language or add frame without content descriptor
+func (id3v2 *ID3v22) SetLyrics(language string, lyrics string) error {
+	language, err := commentLanguage(language)
+	if err != nil {
+		return err
+	}
+
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "ULT" {
+			continue
+		}
+		found, err := parseComment(id3v2.Frames[i].Value)
+		if err != nil || !strings.EqualFold(found.Language, language) {
+			continue
+		}
+		text, err := id3v2.encodeText(found.Description + "\x00" + lyrics)
+		if err != nil {
+			return err
+		}
+		id3v2.Frames[i].Value = encodeComment(found.Language, text)
+		return nil
+	}
+
+	text, err := id3v2.encodeText("\x00" + lyrics)
+	if err != nil {
+		return err
+	}
+	id3v2.Frames = append(id3v2.Frames, ID3v22Frame{Key: "ULT", Value: encodeComment(language, text)})
+	return nil
 }
 
 func (id3v2 *ID3v22) DeleteAll() error {
-	panic("implement me")
+	id3v2.Frames = []ID3v22Frame{}
+	return nil
 }
 
 func (id3v2 *ID3v22) DeleteTitle() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TT2")
 }
 
 func (id3v2 *ID3v22) DeleteArtist() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TP1")
 }
 
 func (id3v2 *ID3v22) DeleteAlbum() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TAL")
 }
 
 func (id3v2 *ID3v22) DeleteYear() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TYE")
 }
 
+// DeleteComment - delete comments without short content description
 func (id3v2 *ID3v22) DeleteComment() error {
-	panic("implement me")
+	return id3v2.DeleteCommentByKey("", "")
+}
+
+// Comments - comments of all COM frames, malformed frames are skipped
+func (id3v2 *ID3v22) Comments() []Comment {
+	var result []Comment
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "COM" {
+			continue
+		}
+		comment, err := parseComment(id3v2.Frames[i].Value)
+		if err == nil {
+			result = append(result, *comment)
+		}
+	}
+	return result
+}
+
+// GetCommentByKey - text of comment with language and description, any language if it's empty
+func (id3v2 *ID3v22) GetCommentByKey(language string, description string) (string, error) {
+	for _, comment := range id3v2.Comments() {
+		if (language == "" || strings.EqualFold(comment.Language, language)) && comment.Description == description {
+			return comment.Text, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+// SetCommentByKey - replace comment with the same language and description or add it
+func (id3v2 *ID3v22) SetCommentByKey(comment Comment) error {
+	language, err := commentLanguage(comment.Language)
+	if err != nil {
+		return err
+	}
+	text, err := id3v2.encodeText(comment.Description + "\x00" + comment.Text)
+	if err != nil {
+		return err
+	}
+	value := encodeComment(language, text)
+
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "COM" {
+			continue
+		}
+		found, err := parseComment(id3v2.Frames[i].Value)
+		if err == nil && strings.EqualFold(found.Language, language) && found.Description == comment.Description {
+			id3v2.Frames[i].Value = value
+			return nil
+		}
+	}
+
+	id3v2.Frames = append(id3v2.Frames, ID3v22Frame{Key: "COM", Value: value})
+	return nil
+}
+
+// DeleteCommentByKey - delete comments with language and description, of any language if it's empty
+func (id3v2 *ID3v22) DeleteCommentByKey(language string, description string) error {
+	frames := id3v2.Frames[:0]
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == "COM" {
+			comment, err := parseComment(id3v2.Frames[i].Value)
+			if err == nil && (language == "" || strings.EqualFold(comment.Language, language)) &&
+				comment.Description == description {
+				continue
+			}
+		}
+		frames = append(frames, id3v2.Frames[i])
+	}
+	id3v2.Frames = frames
+	return nil
 }
 
 func (id3v2 *ID3v22) DeleteGenre() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TCO")
 }
 
 func (id3v2 *ID3v22) DeleteAlbumArtist() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TP2")
 }
 
 func (id3v2 *ID3v22) DeleteDate() error {
-	panic("implement me")
+	for _, name := range []string{"TYE", "TDA", "TIM"} {
+		err := id3v2.DeleteTag(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (id3v2 *ID3v22) DeleteArranger() error {
-	panic("implement me")
+	return id3v2.DeleteTag("IPL")
 }
 
 func (id3v2 *ID3v22) DeleteAuthor() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TOL")
 }
 
 func (id3v2 *ID3v22) DeleteBPM() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TBP")
 }
 
 func (id3v2 *ID3v22) DeleteCatalogNumber() error {
-	panic("implement me")
+	return id3v2.DeleteTagTXX("CATALOGNUMBER")
 }
 
 func (id3v2 *ID3v22) DeleteCompilation() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TCP")
 }
 
 func (id3v2 *ID3v22) DeleteComposer() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TCM")
 }
 
 func (id3v2 *ID3v22) DeleteConductor() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TP3")
 }
 
 func (id3v2 *ID3v22) DeleteCopyright() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TCR")
 }
 
 func (id3v2 *ID3v22) DeleteDescription() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TT3")
 }
 
 func (id3v2 *ID3v22) DeleteDiscNumber() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TPA")
 }
 
 func (id3v2 *ID3v22) DeleteEncodedBy() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TEN")
 }
 
 func (id3v2 *ID3v22) DeleteTrackNumber() error {
-	panic("implement me")
+	return id3v2.DeleteTag("TRK")
 }
 
+// DeletePicture - delete all PIC frames
 func (id3v2 *ID3v22) DeletePicture() error {
-	panic("implement me")
+	frames := id3v2.Frames[:0]
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "PIC" {
+			frames = append(frames, id3v2.Frames[i])
+		}
+	}
+	id3v2.Frames = frames
+	return nil
+}
+
+// DeleteLyrics - delete ULT frames with language, all if it's empty
+func (id3v2 *ID3v22) DeleteLyrics(language string) error {
+	frames := id3v2.Frames[:0]
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == "ULT" {
+			lyrics, err := parseComment(id3v2.Frames[i].Value)
+			if language == "" || (err == nil && strings.EqualFold(lyrics.Language, language)) {
+				continue
+			}
+		}
+		frames = append(frames, id3v2.Frames[i])
+	}
+	id3v2.Frames = frames
+	return nil
 }
 
 func (id3v2 *ID3v22) SaveFile(path string) error {
-	panic("implement me")
+	return saveFile(path, id3v2.Save, SaveOptions{})
+}
+
+// SaveInPlace - write tag over the tag and padding of file read by ReadID3v22.
+// If tag doesn't fit, audio is moved and tag is written with Padding
+func (id3v2 *ID3v22) SaveInPlace(file *os.File) error {
+	frames, err := id3v2.encodeFrames()
+	if err != nil {
+		return err
+	}
+	size := int64(10 + len(frames))
+	length, err := saveInPlace(file, id3v2.Data, id3v2.audio, size, 0, inPlacePadding(id3v2.Padding),
+		func(length int64) ([]byte, error) {
+			padding := int(length - size)
+			output := new(bytes.Buffer)
+			err := id3v2.writeHeaderID3v22(output, len(frames)+padding)
+			if err != nil {
+				return nil, err
+			}
+			output.Write(frames)
+			output.Write(make([]byte, padding))
+			return output.Bytes(), nil
+		})
+	if err != nil {
+		return err
+	}
+	id3v2.Length = int(length) - 10
+	return nil
 }
 
 func (id3v2 *ID3v22) Save(input io.WriteSeeker) error {
-	panic("implement me")
+	frames, err := id3v2.encodeFrames()
+	if err != nil {
+		return err
+	}
+
+	// write header
+	err = id3v2.writeHeaderID3v22(input, len(frames))
+	if err != nil {
+		return err
+	}
+
+	// write tags
+	err = writeTag(input, frames)
+	if err != nil {
+		return err
+	}
+
+	// write data
+	err = writeAudio(input, id3v2.Data, id3v2.audio)
+	if err != nil {
+		return err
+	}
+	return nil
 }
 
-// nolint:gocyclo
+// writeHeaderID3v22 - header of tag with length bytes of frames and padding
+func (id3v2 *ID3v22) writeHeaderID3v22(writer io.Writer, length int) error {
+	headerByte := make([]byte, 10)
+
+	// ID3
+	copy(headerByte[0:3], id3MarkerValue)
+
+	// Version, Subversion, Flags. Compression scheme isn't defined, tag is never compressed
+	var flags id3v22Flags
+	flags.SetUnsynchronisation(id3v2.Flags.IsUnsynchronisation())
+	copy(headerByte[3:6], []byte{2, 0, byte(flags)})
+
+	// Length
+	lengthByte := IntToByteSynchsafe(length)
+	copy(headerByte[6:10], lengthByte)
+
+	nWritten, err := writer.Write(headerByte)
+	if err != nil {
+		return err
+	}
+	if nWritten != 10 {
+		return ErrWriting
+	}
+	return nil
+}
+
+func (id3v2 *ID3v22) writeFramesID3v22(writer io.Writer) error {
+	for i := range id3v2.Frames {
+		header := make([]byte, id3v22FrameHeaderSize)
+
+		// Frame id
+		copy(header, id3v2.Frames[i].Key)
+
+		// Frame size
+		length := len(id3v2.Frames[i].Value)
+		if length > 0xFFFFFF {
+			return ErrIncorrectLength
+		}
+		header[3] = byte(length >> 16)
+		header[4] = byte(length >> 8)
+		header[5] = byte(length)
+
+		// write header
+		_, err := writer.Write(header)
+		if err != nil {
+			return err
+		}
+
+		// write data
+		_, err = writer.Write(id3v2.Frames[i].Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeFrames - frames of the tag, unsynchronised if the tag is unsynchronised
+func (id3v2 *ID3v22) encodeFrames() ([]byte, error) {
+	output := new(bytes.Buffer)
+	err := id3v2.writeFramesID3v22(output)
+	if err != nil {
+		return nil, err
+	}
+	if id3v2.Flags.IsUnsynchronisation() {
+		return unsynchronise(output.Bytes()), nil
+	}
+	return output.Bytes(), nil
+}
+
+func (id3v2 *ID3v22) String() string {
+	result := "Marker: " + id3v2.Marker + "\n" +
+		"Version: " + VersionID3v22.String() + "\n" +
+		"Subversion: " + strconv.Itoa(id3v2.SubVersion) + "\n" +
+		"Flags: " + id3v2.Flags.String() + "\n" +
+		"Length: " + strconv.Itoa(id3v2.Length) + "\n"
+
+	for i := range id3v2.Frames {
+		result += id3v2.Frames[i].Key + ": " + string(id3v2.Frames[i].Value) + "\n"
+	}
+
+	return result
+}
+
+// nolint:gocyclo,funlen
 func ReadID3v22(input io.ReadSeeker) (*ID3v22, error) {
 	header := ID3v22{}
 
@@ -427,54 +888,65 @@ func ReadID3v22(input io.ReadSeeker) (*ID3v22, error) {
 		return nil, ErrUnsupportedFormat
 	}
 
+	// Sub version
+	header.SubVersion = int(headerByte[4])
+
+	// Flags
+	header.Flags = id3v22Flags(headerByte[5])
+
 	// Length
 	length := ByteToIntSynchsafe(headerByte[6:10])
 	header.Length = length
 
+	// compression scheme isn't defined, such tag should be ignored
+	if header.Flags.IsCompression() {
+		return nil, ErrUnsupportedFormat
+	}
+
+	// Tag data, unsynchronisation is applied to the whole tag
+	data := make([]byte, length)
+	_, err = io.ReadFull(input, data)
+	if err != nil {
+		return nil, err
+	}
+	if header.Flags.IsUnsynchronisation() {
+		data = resynchronise(data)
+	}
+
+	header.Frames = []ID3v22Frame{}
 	curRead := 0
-	for curRead < length {
-		bytesExtendedHeader := make([]byte, id3v22FrameHeaderSize)
-		nReaded, err = input.Read(bytesExtendedHeader)
-		if err != nil {
-			return nil, err
-		}
-		if nReaded != 6 {
-			return nil, errors.New("error extended header length")
+	for curRead+id3v22FrameHeaderSize <= len(data) {
+		bytesExtendedHeader := data[curRead : curRead+id3v22FrameHeaderSize]
+
+		// Padding
+		if bytesExtendedHeader[0] == 0 {
+			break
 		}
+
 		// Frame identifier
 		key := string(bytesExtendedHeader[0:3])
 
 		// Frame data size
 		size := ByteToInt(bytesExtendedHeader[3:id3v22FrameHeaderSize])
-
-		bytesExtendedValue := make([]byte, size)
-		nReaded, err = input.Read(bytesExtendedValue)
-		if err != nil {
-			return nil, err
-		}
-		if nReaded != size {
+		start := curRead + id3v22FrameHeaderSize
+		if start+size > len(data) {
 			return nil, errors.New("error extended value length")
 		}
 
-		if key[0:1] == "T" {
-			pos := -1
-			for i, v := range bytesExtendedValue {
-				if v == 0 && i > 0 {
-					pos = i
-				}
-			}
-			if pos != -1 {
-				bytesExtendedValue = bytesExtendedValue[0:pos]
-			}
-		}
-
 		header.Frames = append(header.Frames, ID3v22Frame{
 			key,
-			bytesExtendedValue,
+			data[start : start+size],
 		})
 
-		curRead += id3v22FrameHeaderSize + size
+		curRead = start + size
 	}
+
+	// file data after padding
+	header.audio, err = readRegion(input, int64(10+length), -1)
+	if err != nil {
+		return nil, err
+	}
+
 	return &header, nil
 }
 
@@ -499,15 +971,36 @@ func checkID3v22(input io.ReadSeeker) bool {
 	return versionByte == 2
 }
 
+// GetString - text frame value without terminating zero characters.
 func (id3v2 *ID3v22) GetString(name string) (string, error) {
 	for i := range id3v2.Frames {
 		if id3v2.Frames[i].Key == name {
-			return GetString(id3v2.Frames[i].Value)
+			str, err := GetString(id3v2.Frames[i].Value)
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimRight(str, "\x00"), nil
 		}
 	}
 	return "", ErrTagNotFound
 }
 
+// encodeText - text with encoding byte by Encoding, ID3v2.2 supports only ISO-8859-1 and UTF-16
+func (id3v2 *ID3v22) encodeText(value string) ([]byte, error) {
+	if id3v2.Encoding != "" && id3v2.Encoding != EncodingISO88591 && id3v2.Encoding != EncodingUTF16 {
+		return nil, ErrEncodingFormat
+	}
+	return EncodeString(value, chooseEncoding(value, id3v2.Encoding, false))
+}
+
+func (id3v2 *ID3v22) SetString(name string, value string) error {
+	text, err := id3v2.encodeText(value)
+	if err != nil {
+		return err
+	}
+	return id3v2.SetBytes(name, text)
+}
+
 func (id3v2 *ID3v22) GetBytes(name string) ([]byte, error) {
 	for i := range id3v2.Frames {
 		if id3v2.Frames[i].Key == name {
@@ -516,3 +1009,144 @@ func (id3v2 *ID3v22) GetBytes(name string) ([]byte, error) {
 	}
 	return nil, ErrTagNotFound
 }
+
+func (id3v2 *ID3v22) SetBytes(name string, value []byte) error {
+	// if found set new frame value
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == name {
+			id3v2.Frames[i].Value = value
+			return nil
+		}
+	}
+
+	// if not found add new frame to frames
+	id3v2.Frames = append(id3v2.Frames, ID3v22Frame{
+		Key:   name,
+		Value: value,
+	})
+	return nil
+}
+
+func (id3v2 *ID3v22) GetInt(name string) (int, error) {
+	intStr, err := id3v2.GetString(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(intStr)
+}
+
+func (id3v2 *ID3v22) SetInt(name string, value int) error {
+	return id3v2.SetString(name, strconv.Itoa(value))
+}
+
+// GetNumberTotal - read frames with format 'number/total' or 'number'.
+func (id3v2 *ID3v22) GetNumberTotal(name string) (int, int, error) {
+	value, err := id3v2.GetString(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	parts := strings.Split(value, "/")
+	if len(parts) == 1 {
+		number, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return 0, 0, err
+		}
+		return number, number, nil
+	} else if len(parts) == 2 {
+		number1, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return 0, 0, err
+		}
+		number2, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return 0, 0, err
+		}
+		return number1, number2, nil
+	}
+
+	return 0, 0, ErrIncorrectTag
+}
+
+func (id3v2 *ID3v22) DeleteTag(name string) error {
+	index := -1
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == name {
+			index = i
+			break
+		}
+	}
+
+	// already deleted or not exist
+	if index == -1 {
+		return nil
+	}
+
+	id3v2.Frames = append(id3v2.Frames[:index], id3v2.Frames[index+1:]...)
+	return nil
+}
+
+// GetStringTXX - read TXX frame
+// Header for 'User defined text information frame'
+// Text encoding     $xx
+// Description       <textstring> $00 (00)
+// Value             <textstring>.
+func (id3v2 *ID3v22) GetStringTXX(name string) (string, error) {
+	index := id3v2.findTXX(name)
+	if index == -1 {
+		return "", ErrTagNotFound
+	}
+	str, err := GetString(id3v2.Frames[index].Value)
+	if err != nil {
+		return "", err
+	}
+	info := strings.SplitN(str, "\x00", 2)
+	return strings.TrimRight(info[1], "\x00"), nil
+}
+
+func (id3v2 *ID3v22) SetStringTXX(name string, value string) error {
+	text, err := id3v2.encodeText(name + "\x00" + value)
+	if err != nil {
+		return err
+	}
+	frame := ID3v22Frame{
+		Key:   "TXX",
+		Value: text,
+	}
+
+	index := id3v2.findTXX(name)
+	if index == -1 {
+		id3v2.Frames = append(id3v2.Frames, frame)
+		return nil
+	}
+	id3v2.Frames[index] = frame
+	return nil
+}
+
+func (id3v2 *ID3v22) DeleteTagTXX(name string) error {
+	index := id3v2.findTXX(name)
+
+	// already deleted or not exist
+	if index == -1 {
+		return nil
+	}
+
+	id3v2.Frames = append(id3v2.Frames[:index], id3v2.Frames[index+1:]...)
+	return nil
+}
+
+func (id3v2 *ID3v22) findTXX(name string) int {
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "TXX" {
+			continue
+		}
+		str, err := GetString(id3v2.Frames[i].Value)
+		if err != nil {
+			continue
+		}
+		info := strings.SplitN(str, "\x00", 2)
+		if len(info) == 2 && info[0] == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -4,11 +4,11 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"image"
 	"image/jpeg"
 	"image/png"
 	"io"
-	"io/ioutil"
 	"os"
 	"strconv"
 	"strings"
@@ -25,29 +25,207 @@ func (flags id3v23Flags) IsUnsynchronisation() bool {
 	return GetBit(byte(flags), 7) == 1
 }
 
-func (flags id3v23Flags) SetUnsynchronisation(data bool) {
-	SetBit((*byte)(&flags), data, 7)
+func (flags *id3v23Flags) SetUnsynchronisation(data bool) {
+	SetBit((*byte)(flags), data, 7)
 }
 
 func (flags id3v23Flags) HasExtendedHeader() bool {
 	return GetBit(byte(flags), 6) == 1
 }
 
-func (flags id3v23Flags) SetExtendedHeader(data bool) {
-	SetBit((*byte)(&flags), data, 7)
+func (flags *id3v23Flags) SetExtendedHeader(data bool) {
+	SetBit((*byte)(flags), data, 6)
 }
 
 func (flags id3v23Flags) IsExperimentalIndicator() bool {
 	return GetBit(byte(flags), 5) == 1
 }
 
-func (flags id3v23Flags) SetExperimentalIndicator(data bool) {
-	SetBit((*byte)(&flags), data, 7)
+func (flags *id3v23Flags) SetExperimentalIndicator(data bool) {
+	SetBit((*byte)(flags), data, 5)
+}
+
+// ID3v23ExtendedHeader - extended header of the tag
+type ID3v23ExtendedHeader struct {
+	Padding int    // size of padding, set on save
+	HasCRC  bool   // CRC-32 of frames is written on save
+	CRC     uint32 // CRC-32 of frames, read or written on save
+}
+
+// readID3v23ExtendedHeader - parse extended header at the start of tag data, size of the header is returned
+func readID3v23ExtendedHeader(data []byte) (*ID3v23ExtendedHeader, int, error) {
+	if len(data) < 10 {
+		return nil, 0, ErrIncorrectLength
+	}
+
+	// size without size bytes: 6 or 10 with CRC
+	size := ByteToInt(data[0:4])
+	if size < 6 || 4+size > len(data) {
+		return nil, 0, ErrIncorrectLength
+	}
+
+	result := &ID3v23ExtendedHeader{
+		Padding: ByteToInt(data[6:10]),
+	}
+	if data[4]&id3v23ExtendedCRC != 0 {
+		if size < 10 {
+			return nil, 0, ErrIncorrectLength
+		}
+		result.HasCRC = true
+		result.CRC = uint32(ByteToInt(data[10:14]))
+	}
+	return result, 4 + size, nil
+}
+
+// encode - extended header, CRC is set to CRC-32 of frames
+func (header *ID3v23ExtendedHeader) encode(frames []byte, padding int) []byte {
+	header.Padding = padding
+	result := []byte{0, 0, 0, 6, 0, 0, byte(padding >> 24), byte(padding >> 16), byte(padding >> 8), byte(padding)}
+	if header.HasCRC {
+		header.CRC = crc32.ChecksumIEEE(frames)
+		result[3] = 10
+		result[4] = id3v23ExtendedCRC
+		result = append(result, byte(header.CRC>>24), byte(header.CRC>>16), byte(header.CRC>>8), byte(header.CRC))
+	}
+	return result
+}
+
+// id3v23FrameFlags - frame status (high byte) and format (low byte) flags
+type id3v23FrameFlags uint16
+
+func (flags id3v23FrameFlags) String() string {
+	return strconv.Itoa(int(flags))
+}
+
+func (flags id3v23FrameFlags) IsCompressed() bool {
+	return flags&id3v23FrameCompression != 0
+}
+
+func (flags *id3v23FrameFlags) SetCompression(data bool) {
+	flags.set(id3v23FrameCompression, data)
+}
+
+func (flags id3v23FrameFlags) IsEncrypted() bool {
+	return flags&id3v23FrameEncryption != 0
+}
+
+func (flags id3v23FrameFlags) HasGroup() bool {
+	return flags&id3v23FrameGrouping != 0
+}
+
+func (flags *id3v23FrameFlags) SetGroup(data bool) {
+	flags.set(id3v23FrameGrouping, data)
+}
+
+func (flags *id3v23FrameFlags) set(flag id3v23FrameFlags, data bool) {
+	if data {
+		*flags |= flag
+	} else {
+		*flags &^= flag
+	}
+}
+
+// id3v24 - flags in ID3v2.4 layout
+func (flags id3v23FrameFlags) id3v24() id3v24FrameFlags {
+	result := id3v24FrameFlags(flags>>1) & id3v24FrameStatus
+	if flags.IsCompressed() {
+		result |= id3v24FrameCompression | id3v24FrameDataLength
+	}
+	if flags.IsEncrypted() {
+		result |= id3v24FrameEncryption
+	}
+	if flags.HasGroup() {
+		result |= id3v24FrameGrouping
+	}
+	return result
 }
 
 type ID3v23Frame struct {
 	Key   string
 	Value []byte
+
+	// Flags - frame flags. Value is decoded on read and encoded by flags on save,
+	// except encrypted frames: their Value is kept as is
+	Flags id3v23FrameFlags
+	Group byte // group identifier if Flags.HasGroup()
+}
+
+// decode - set Value from frame data: decompressed size, group identifier and compression are removed
+func (frame *ID3v23Frame) decode(data []byte) error {
+	if frame.Flags.IsEncrypted() {
+		frame.Value = data
+		return nil
+	}
+
+	if frame.Flags.IsCompressed() {
+		if len(data) < 4 {
+			return ErrIncorrectLength
+		}
+		data = data[4:]
+	}
+	if frame.Flags.HasGroup() {
+		if len(data) < 1 {
+			return ErrIncorrectLength
+		}
+		frame.Group = data[0]
+		data = data[1:]
+	}
+	if frame.Flags.IsCompressed() {
+		var err error
+		data, err = decompressData(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	frame.Value = data
+	return nil
+}
+
+// encode - frame header and data encoded by flags, compression is applied if set
+func (frame *ID3v23Frame) encode(compression bool) ([]byte, error) {
+	flags := frame.Flags
+	data := frame.Value
+	if !flags.IsEncrypted() {
+		if compression {
+			flags.SetCompression(true)
+		}
+
+		var prefix []byte
+		if flags.IsCompressed() {
+			length := len(data)
+			prefix = append(prefix, byte(length>>24), byte(length>>16), byte(length>>8), byte(length))
+		}
+		if flags.HasGroup() {
+			prefix = append(prefix, frame.Group)
+		}
+		if flags.IsCompressed() {
+			var err error
+			data, err = compressData(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+		data = append(prefix, data...)
+	}
+
+	result := make([]byte, id3v2FrameHeaderSize, id3v2FrameHeaderSize+len(data))
+
+	// Frame id
+	copy(result, frame.Key)
+
+	// Frame size
+	length := len(data)
+	result[4] = byte(length >> 24)
+	result[5] = byte(length >> 16)
+	result[6] = byte(length >> 8)
+	result[7] = byte(length)
+
+	// Frame flags
+	result[8] = byte(flags >> 8)
+	result[9] = byte(flags)
+
+	return append(result, data...), nil
 }
 
 type ID3v23 struct {
@@ -58,7 +236,26 @@ type ID3v23 struct {
 	Length     int
 	Frames     []ID3v23Frame
 
-	Data []byte
+	// ExtendedHeader - nil if tag has no extended header, written on save if set
+	ExtendedHeader *ID3v23ExtendedHeader
+	crc            uint32 // CRC-32 of frames read or saved
+
+	// Data - audio data after tag, nil if audio is read from the source on demand
+	Data  []byte
+	audio *fileRegion
+
+	// Padding - padding written by SaveInPlace if tag doesn't fit into the file,
+	// DefaultPadding if 0, no padding if negative
+	Padding int
+
+	// Encoding - text encoding of frames set by setters: EncodingISO88591 or EncodingUTF16.
+	// If empty, ISO-8859-1 is used if text is representable, UTF-16 otherwise
+	Encoding string
+
+	// Unsynchronise, Compress - unsynchronise the tag or compress all frames on save,
+	// frames are encoded by their flags otherwise
+	Unsynchronise bool
+	Compress      bool
 }
 
 func (id3v2 *ID3v23) GetAllTagNames() []string {
@@ -74,7 +271,11 @@ func (id3v2 *ID3v23) GetVersion() Version {
 }
 
 func (id3v2 *ID3v23) GetFileData() []byte {
-	return id3v2.Data
+	return readSection(audioSection(id3v2.Data, id3v2.audio))
+}
+
+func (id3v2 *ID3v23) AudioReader() io.Reader {
+	return audioSection(id3v2.Data, id3v2.audio)
 }
 
 func (id3v2 *ID3v23) GetTitle() (string, error) {
@@ -90,27 +291,20 @@ func (id3v2 *ID3v23) GetAlbum() (string, error) {
 }
 
 func (id3v2 *ID3v23) GetYear() (int, error) {
-	date, err := id3v2.GetTimestamp("TYEAR")
-	return date.Year(), err
-}
-
-func (id3v2 *ID3v23) GetComment() (string, error) {
-	// id3v2
-	// Comment struct must be greater than 4
-	// [lang \x00 text] - comment format
-	// lang - 3 symbols
-	// \x00 - const, delimeter
-	// text - all after
-	commentStr, err := id3v2.GetString("COMM")
+	year, err := id3v2.GetString("TYER")
 	if err != nil {
-		return "", err
+		return 0, err
 	}
-
-	if len(commentStr) < 4 {
-		return "", ErrIncorrectLength
+	// TYER is always four characters long
+	if len(year) > 4 {
+		year = year[:4]
 	}
+	return strconv.Atoi(year)
+}
 
-	return commentStr[4:], nil
+// GetComment - comment without short content description of any language
+func (id3v2 *ID3v23) GetComment() (string, error) {
+	return id3v2.GetCommentByKey("", "")
 }
 
 func (id3v2 *ID3v23) GetGenre() (string, error) {
@@ -121,8 +315,15 @@ func (id3v2 *ID3v23) GetAlbumArtist() (string, error) {
 	return id3v2.GetString("TPE2")
 }
 
+// GetDate - combine year (TYER), day and month (TDAT - DDMM) and time (TIME - HHMM).
 func (id3v2 *ID3v23) GetDate() (time.Time, error) {
-	return id3v2.GetTimestamp("TYEAR")
+	year, err := id3v2.GetString("TYER")
+	if err != nil {
+		return time.Now(), err
+	}
+	date, _ := id3v2.GetString("TDAT")
+	clock, _ := id3v2.GetString("TIME")
+	return parseID3v23Date(year, date, clock)
 }
 
 func (id3v2 *ID3v23) GetArranger() (string, error) {
@@ -205,6 +406,24 @@ func (id3v2 *ID3v23) GetPicture() (image.Image, error) {
 	}
 }
 
+// GetLyrics - text of USLT frame with language, of any language if it's empty
+func (id3v2 *ID3v23) GetLyrics(language string) (string, error) {
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "USLT" {
+			continue
+		}
+		// malformed frames are skipped
+		lyrics, err := parseComment(id3v2.Frames[i].Value)
+		if err != nil {
+			continue
+		}
+		if language == "" || strings.EqualFold(lyrics.Language, language) {
+			return lyrics.Text, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
 func (id3v2 *ID3v23) SetTitle(title string) error {
 	return id3v2.SetString("TIT2", title)
 }
@@ -218,32 +437,19 @@ func (id3v2 *ID3v23) SetAlbum(album string) error {
 }
 
 func (id3v2 *ID3v23) SetYear(year int) error {
-	curDate, err := id3v2.GetTimestamp("TYER")
-	if err != nil {
-		// set only year
-		return id3v2.SetTimestamp(
-			"TYER",
-			time.Date(year, 0, 0, 0, 0, 0, 0, time.Local),
-		)
-	}
-
-	return id3v2.SetTimestamp(
-		"TYER",
-		time.Date(
-			year,
-			curDate.Month(),
-			curDate.Day(),
-			curDate.Hour(),
-			curDate.Minute(),
-			curDate.Second(),
-			curDate.Nanosecond(),
-			curDate.Location(),
-		),
-	)
+	return id3v2.SetInt("TYER", year)
 }
 
+// SetComment - set comment without short content description, keep its language
 func (id3v2 *ID3v23) SetComment(comment string) error {
-	return id3v2.SetString("COMM", comment)
+	language := "eng"
+	for _, found := range id3v2.Comments() {
+		if found.Description == "" {
+			language = found.Language
+			break
+		}
+	}
+	return id3v2.SetCommentByKey(Comment{Language: language, Text: comment})
 }
 
 func (id3v2 *ID3v23) SetGenre(genre string) error {
@@ -255,7 +461,15 @@ func (id3v2 *ID3v23) SetAlbumArtist(albumArtist string) error {
 }
 
 func (id3v2 *ID3v23) SetDate(date time.Time) error {
-	return id3v2.SetTimestamp("TYER", date)
+	err := id3v2.SetInt("TYER", date.Year())
+	if err != nil {
+		return err
+	}
+	err = id3v2.SetString("TDAT", date.Format("0201"))
+	if err != nil {
+		return err
+	}
+	return id3v2.SetString("TIME", date.Format("1504"))
 }
 
 func (id3v2 *ID3v23) SetArranger(arranger string) error {
@@ -267,7 +481,7 @@ func (id3v2 *ID3v23) SetAuthor(author string) error {
 }
 
 func (id3v2 *ID3v23) SetBPM(bmp int) error {
-	return id3v2.SetInt("TBMP", bmp)
+	return id3v2.SetInt("TBPM", bmp)
 }
 
 func (id3v2 *ID3v23) SetCatalogNumber(catalogNumber string) error {
@@ -320,7 +534,7 @@ func (id3v2 *ID3v23) SetPicture(picture image.Image) error {
 		// Set default params
 		newPicture := AttachedPicture{
 			MIME:        "image/png",
-			PictureType: 2, // Other file info
+			PictureType: PictureTypeFrontCover,
 			Description: "",
 			Data:        buf.Bytes(),
 		}
@@ -333,6 +547,37 @@ func (id3v2 *ID3v23) SetPicture(picture image.Image) error {
 	return id3v2.SetAttachedPicture(attacheched)
 }
 
+// SetLyrics - replace text of USLT frame with language or add frame without content descriptor
+func (id3v2 *ID3v23) SetLyrics(language string, lyrics string) error {
+	language, err := commentLanguage(language)
+	if err != nil {
+		return err
+	}
+
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "USLT" {
+			continue
+		}
+		found, err := parseComment(id3v2.Frames[i].Value)
+		if err != nil || !strings.EqualFold(found.Language, language) {
+			continue
+		}
+		text, err := id3v2.encodeText(found.Description + "\x00" + lyrics)
+		if err != nil {
+			return err
+		}
+		id3v2.Frames[i].Value = encodeComment(found.Language, text)
+		return nil
+	}
+
+	text, err := id3v2.encodeText("\x00" + lyrics)
+	if err != nil {
+		return err
+	}
+	id3v2.Frames = append(id3v2.Frames, ID3v23Frame{Key: "USLT", Value: encodeComment(language, text)})
+	return nil
+}
+
 func (id3v2 *ID3v23) DeleteAll() error {
 	id3v2.Frames = []ID3v23Frame{}
 	return nil
@@ -354,8 +599,78 @@ func (id3v2 *ID3v23) DeleteYear() error {
 	return id3v2.DeleteTag("TYER")
 }
 
+// DeleteComment - delete comments without short content description
 func (id3v2 *ID3v23) DeleteComment() error {
-	return id3v2.DeleteTag("COMM")
+	return id3v2.DeleteCommentByKey("", "")
+}
+
+// Comments - comments of all COMM frames, malformed frames are skipped
+func (id3v2 *ID3v23) Comments() []Comment {
+	var result []Comment
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "COMM" {
+			continue
+		}
+		comment, err := parseComment(id3v2.Frames[i].Value)
+		if err == nil {
+			result = append(result, *comment)
+		}
+	}
+	return result
+}
+
+// GetCommentByKey - text of comment with language and description, any language if it's empty
+func (id3v2 *ID3v23) GetCommentByKey(language string, description string) (string, error) {
+	for _, comment := range id3v2.Comments() {
+		if (language == "" || strings.EqualFold(comment.Language, language)) && comment.Description == description {
+			return comment.Text, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+// SetCommentByKey - replace comment with the same language and description or add it
+func (id3v2 *ID3v23) SetCommentByKey(comment Comment) error {
+	language, err := commentLanguage(comment.Language)
+	if err != nil {
+		return err
+	}
+	text, err := id3v2.encodeText(comment.Description + "\x00" + comment.Text)
+	if err != nil {
+		return err
+	}
+	value := encodeComment(language, text)
+
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "COMM" {
+			continue
+		}
+		found, err := parseComment(id3v2.Frames[i].Value)
+		if err == nil && strings.EqualFold(found.Language, language) && found.Description == comment.Description {
+			id3v2.Frames[i].Value = value
+			return nil
+		}
+	}
+
+	id3v2.Frames = append(id3v2.Frames, ID3v23Frame{Key: "COMM", Value: value})
+	return nil
+}
+
+// DeleteCommentByKey - delete comments with language and description, of any language if it's empty
+func (id3v2 *ID3v23) DeleteCommentByKey(language string, description string) error {
+	frames := id3v2.Frames[:0]
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == "COMM" {
+			comment, err := parseComment(id3v2.Frames[i].Value)
+			if err == nil && (language == "" || strings.EqualFold(comment.Language, language)) &&
+				comment.Description == description {
+				continue
+			}
+		}
+		frames = append(frames, id3v2.Frames[i])
+	}
+	id3v2.Frames = frames
+	return nil
 }
 
 func (id3v2 *ID3v23) DeleteGenre() error {
@@ -367,7 +682,13 @@ func (id3v2 *ID3v23) DeleteAlbumArtist() error {
 }
 
 func (id3v2 *ID3v23) DeleteDate() error {
-	return id3v2.DeleteTag("TYER")
+	for _, name := range []string{"TYER", "TDAT", "TIME"} {
+		err := id3v2.DeleteTag(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (id3v2 *ID3v23) DeleteArranger() error {
@@ -379,7 +700,7 @@ func (id3v2 *ID3v23) DeleteAuthor() error {
 }
 
 func (id3v2 *ID3v23) DeleteBPM() error {
-	return id3v2.DeleteTag("TBMP")
+	return id3v2.DeleteTag("TBPM")
 }
 
 func (id3v2 *ID3v23) DeleteCatalogNumber() error {
@@ -418,102 +739,149 @@ func (id3v2 *ID3v23) DeleteTrackNumber() error {
 	return id3v2.DeleteTag("TRCK")
 }
 
+// DeletePicture - delete all APIC frames
 func (id3v2 *ID3v23) DeletePicture() error {
-	return id3v2.DeleteTag("APIC")
+	frames := id3v2.Frames[:0]
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "APIC" {
+			frames = append(frames, id3v2.Frames[i])
+		}
+	}
+	id3v2.Frames = frames
+	return nil
+}
+
+// DeleteLyrics - delete USLT frames with language, all if it's empty
+func (id3v2 *ID3v23) DeleteLyrics(language string) error {
+	frames := id3v2.Frames[:0]
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == "USLT" {
+			lyrics, err := parseComment(id3v2.Frames[i].Value)
+			if language == "" || (err == nil && strings.EqualFold(lyrics.Language, language)) {
+				continue
+			}
+		}
+		frames = append(frames, id3v2.Frames[i])
+	}
+	id3v2.Frames = frames
+	return nil
 }
 
 func (id3v2 *ID3v23) SaveFile(path string) error {
-	file, err := os.Create(path)
+	return saveFile(path, id3v2.Save, SaveOptions{})
+}
+
+// SaveInPlace - write tag over the tag and padding of file read by ReadID3v23.
+// If tag doesn't fit, audio is moved and tag is written with Padding
+func (id3v2 *ID3v23) SaveInPlace(file *os.File) error {
+	frames, err := id3v2.encodeFrames()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	return id3v2.Save(file)
+	size := int64(len(id3v2.encodeTag(frames, 0)))
+	length, err := saveInPlace(file, id3v2.Data, id3v2.audio, size, 0, inPlacePadding(id3v2.Padding),
+		func(length int64) ([]byte, error) {
+			// unsynchronised padding size of the extended header may take more bytes
+			padding := int(length - size)
+			data := id3v2.encodeTag(frames, padding)
+			for int64(len(data)) > length && padding > 0 {
+				padding--
+				data = id3v2.encodeTag(frames, padding)
+			}
+			return append(data, make([]byte, int(length)-len(data))...), nil
+		})
+	if err != nil {
+		return err
+	}
+	id3v2.Length = int(length) - 10
+	return nil
 }
 
 func (id3v2 *ID3v23) Save(input io.WriteSeeker) error {
-	// write header
-	err := id3v2.writeHeaderID3v23(input)
+	frames, err := id3v2.encodeFrames()
 	if err != nil {
 		return err
 	}
 
-	// write tags
-	err = id3v2.writeFramesID3v23(input)
+	// write tag
+	data := id3v2.encodeTag(frames, 0)
+	nWritten, err := input.Write(data)
 	if err != nil {
 		return err
 	}
+	if nWritten != len(data) {
+		return ErrWriting
+	}
 
 	// write data
-	_, err = input.Write(id3v2.Data)
+	err = writeAudio(input, id3v2.Data, id3v2.audio)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (id3v2 *ID3v23) writeHeaderID3v23(writer io.Writer) error {
+// encodeTag - header, extended header, frames and padding.
+// Extended header and frames are unsynchronised if the tag is unsynchronised
+func (id3v2 *ID3v23) encodeTag(frames []byte, padding int) []byte {
+	var body []byte
+	if id3v2.ExtendedHeader != nil {
+		body = id3v2.ExtendedHeader.encode(frames, padding)
+		id3v2.crc = crc32.ChecksumIEEE(frames)
+	}
+	body = append(body, frames...)
+
+	// Flags
+	flags := id3v2.Flags
+	flags.SetExtendedHeader(id3v2.ExtendedHeader != nil)
+	if id3v2.Unsynchronise {
+		flags.SetUnsynchronisation(true)
+	}
+	if flags.IsUnsynchronisation() {
+		body = unsynchronise(body)
+	}
+
 	headerByte := make([]byte, 10)
 
 	// ID3
 	copy(headerByte[0:3], id3MarkerValue)
 
 	// Version, Subversion, Flags
-	copy(headerByte[3:6], []byte{3, 0, 0})
+	copy(headerByte[3:6], []byte{3, 0, byte(flags)})
 
 	// Length
-	length := id3v2.getFramesLength()
-	lengthByte := IntToByteSynchsafe(length)
-	copy(headerByte[6:10], lengthByte)
+	length := len(body) + padding
+	copy(headerByte[6:10], IntToByteSynchsafe(length))
 
-	nWritten, err := writer.Write(headerByte)
-	if err != nil {
-		return err
-	}
-	if nWritten != 10 {
-		return ErrWriting
-	}
-	return nil
+	result := make([]byte, 0, 10+length)
+	result = append(result, headerByte...)
+	result = append(result, body...)
+	return append(result, make([]byte, padding)...)
 }
 
-func (id3v2 *ID3v23) writeFramesID3v23(writer io.Writer) error {
+// encodeFrames - frames encoded by their flags
+func (id3v2 *ID3v23) encodeFrames() ([]byte, error) {
+	var result []byte
 	for i := range id3v2.Frames {
-		header := make([]byte, 10)
-
-		// Frame id
-		copy(header, id3v2.Frames[i].Key)
-
-		// Frame size
-		length := len(id3v2.Frames[i].Value)
-		header[4] = byte(length >> 24)
-		header[5] = byte(length >> 16)
-		header[6] = byte(length >> 8)
-		header[7] = byte(length)
-
-		// write header
-		_, err := writer.Write(header)
+		frame, err := id3v2.Frames[i].encode(id3v2.Compress)
 		if err != nil {
-			return err
-		}
-
-		// write data
-		_, err = writer.Write(id3v2.Frames[i].Value)
-		if err != nil {
-			return err
+			return nil, err
 		}
+		result = append(result, frame...)
 	}
-
-	return nil
+	return result, nil
 }
 
-func (id3v2 *ID3v23) getFramesLength() int {
-	result := 0
-	for i := range id3v2.Frames {
-		// 10 - size of tag header
-		result += 10 + len(id3v2.Frames[i].Value)
+// VerifyCRC - check CRC-32 of the extended header against frames read or saved.
+// ErrTagNotFound if tag has no CRC
+func (id3v2 *ID3v23) VerifyCRC() error {
+	if id3v2.ExtendedHeader == nil || !id3v2.ExtendedHeader.HasCRC {
+		return ErrTagNotFound
 	}
-	return result
+	if id3v2.ExtendedHeader.CRC != id3v2.crc {
+		return ErrIncorrectCRC
+	}
+	return nil
 }
 
 func (id3v2 *ID3v23) String() string {
@@ -551,6 +919,20 @@ func checkID3v23(input io.ReadSeeker) bool {
 	return versionByte == 3
 }
 
+// NewID3v23 - empty tag before audio data
+func NewID3v23(audio io.Reader) (*ID3v23, error) {
+	region, err := readerRegion(audio)
+	if err != nil {
+		return nil, err
+	}
+	return &ID3v23{
+		Marker:  id3MarkerValue,
+		Version: VersionID3v23,
+		Frames:  []ID3v23Frame{},
+		audio:   region,
+	}, nil
+}
+
 // nolint:funlen
 func ReadID3v23(input io.ReadSeeker) (*ID3v23, error) {
 	header := ID3v23{}
@@ -590,6 +972,7 @@ func ReadID3v23(input io.ReadSeeker) (*ID3v23, error) {
 	if versionByte != 3 {
 		return nil, ErrUnsupportedFormat
 	}
+	header.Version = VersionID3v23
 
 	// Sub version
 	subVersionByte := headerByte[4]
@@ -602,48 +985,63 @@ func ReadID3v23(input io.ReadSeeker) (*ID3v23, error) {
 	length := ByteToIntSynchsafe(headerByte[6:10])
 	header.Length = length
 
-	// Extended headers
-	header.Frames = []ID3v23Frame{}
+	// Tag data, unsynchronisation is applied to the whole tag
+	data := make([]byte, length)
+	_, err = io.ReadFull(input, data)
+	if err != nil {
+		return nil, err
+	}
+	if header.Flags.IsUnsynchronisation() {
+		data = resynchronise(data)
+	}
+
+	// Extended header
 	curRead := 0
-	for curRead < length {
-		bytesExtendedHeader := make([]byte, 10)
-		nReaded, err = input.Read(bytesExtendedHeader)
+	if header.Flags.HasExtendedHeader() {
+		header.ExtendedHeader, curRead, err = readID3v23ExtendedHeader(data)
 		if err != nil {
 			return nil, err
 		}
-		if nReaded != 10 {
-			return nil, errors.New("error extended header length")
+	}
+	framesStart := curRead
+
+	// Frames
+	header.Frames = []ID3v23Frame{}
+	for curRead+10 <= len(data) {
+		bytesExtendedHeader := data[curRead : curRead+10]
+
+		// Padding
+		if bytesExtendedHeader[0] == 0 {
+			break
 		}
+
 		// Frame identifier
 		key := string(bytesExtendedHeader[0:4])
 
 		// Frame data size
 		size := ByteToInt(bytesExtendedHeader[4:8])
+		if curRead+10+size > len(data) {
+			return nil, errors.New("error extended value length")
+		}
 
-		bytesExtendedValue := make([]byte, size)
-		nReaded, err = input.Read(bytesExtendedValue)
+		frame := ID3v23Frame{
+			Key:   key,
+			Flags: id3v23FrameFlags(ByteToInt(bytesExtendedHeader[8:10])),
+		}
+		err = frame.decode(data[curRead+10 : curRead+10+size])
 		if err != nil {
 			return nil, err
 		}
-		if nReaded != size {
-			return nil, errors.New("error extended value length")
-		}
-
-		header.Frames = append(header.Frames, ID3v23Frame{
-			key,
-			bytesExtendedValue,
-		})
+		header.Frames = append(header.Frames, frame)
 
 		curRead += 10 + size
 	}
 
-	// TODO
-	if curRead != length {
-		return nil, errors.New("error extended frames")
-	}
+	// CRC of frames between extended header and padding
+	header.crc = crc32.ChecksumIEEE(data[framesStart:curRead])
 
-	// file data
-	header.Data, err = ioutil.ReadAll(input)
+	// file data after padding
+	header.audio, err = readRegion(input, int64(10+length), -1)
 	if err != nil {
 		return nil, err
 	}
@@ -660,10 +1058,22 @@ func (id3v2 *ID3v23) GetString(name string) (string, error) {
 	return "", ErrTagNotFound
 }
 
+// encodeText - text with encoding byte by Encoding, ID3v2.3 supports only ISO-8859-1 and UTF-16
+func (id3v2 *ID3v23) encodeText(value string) ([]byte, error) {
+	if id3v2.Encoding != "" && id3v2.Encoding != EncodingISO88591 && id3v2.Encoding != EncodingUTF16 {
+		return nil, ErrEncodingFormat
+	}
+	return EncodeString(value, chooseEncoding(value, id3v2.Encoding, false))
+}
+
 func (id3v2 *ID3v23) SetString(name string, value string) error {
+	text, err := id3v2.encodeText(value)
+	if err != nil {
+		return err
+	}
 	frame := ID3v23Frame{
 		Key:   name,
-		Value: SetString(value),
+		Value: text,
 	}
 
 	// if found set new frame value
@@ -679,6 +1089,30 @@ func (id3v2 *ID3v23) SetString(name string, value string) error {
 	return nil
 }
 
+func (id3v2 *ID3v23) GetBytes(name string) ([]byte, error) {
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == name {
+			return id3v2.Frames[i].Value, nil
+		}
+	}
+	return nil, ErrTagNotFound
+}
+
+func (id3v2 *ID3v23) SetBytes(name string, value []byte) error {
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == name {
+			id3v2.Frames[i].Value = value
+			return nil
+		}
+	}
+
+	id3v2.Frames = append(id3v2.Frames, ID3v23Frame{
+		Key:   name,
+		Value: value,
+	})
+	return nil
+}
+
 func (id3v2 *ID3v23) GetTimestamp(name string) (time.Time, error) {
 	str, err := id3v2.GetString(name)
 	if err != nil {
@@ -709,55 +1143,82 @@ func (id3v2 *ID3v23) SetInt(name string, value int) error {
 }
 
 func (id3v2 *ID3v23) GetAttachedPicture() (*AttachedPicture, error) {
-	var picture AttachedPicture
-
-	picStr, err := id3v2.GetString("APIC")
+	value, err := id3v2.GetBytes("APIC")
 	if err != nil {
 		return nil, err
 	}
-	values := strings.SplitN(picStr, "\x00", 3)
-	if len(values) != 3 {
-		return nil, ErrIncorrectTag
-	}
-
-	// MIME
-	picture.MIME = values[0]
-
-	// Type
-	if len(values[1]) == 0 {
-		return nil, ErrIncorrectTag
-	}
-	picture.PictureType = values[1][0]
-
-	// Description
-	picture.Description = values[1][1:]
-
-	// Image data
-	picture.Data = []byte(values[2])
-
-	return &picture, nil
+	return parseAttachedPicture(value)
 }
 
 // nolint:gocritic
 func (id3v2 *ID3v23) SetAttachedPicture(picture *AttachedPicture) error {
-	// set UTF-8
-	result := []byte{0}
+	value, err := id3v2.encodePicture(picture)
+	if err != nil {
+		return err
+	}
+	return id3v2.SetBytes("APIC", value)
+}
 
-	// MIME type
-	result = append(result, []byte(picture.MIME)...)
-	result = append(result, 0x00)
+// encodePicture - APIC frame with description in Encoding
+func (id3v2 *ID3v23) encodePicture(picture *AttachedPicture) ([]byte, error) {
+	description, err := id3v2.encodeText(picture.Description)
+	if err != nil {
+		return nil, err
+	}
+	return encodeAttachedPicture(picture, description), nil
+}
 
-	// Picture type
-	result = append(result, picture.PictureType)
+// Pictures - pictures of all APIC frames, malformed frames are skipped
+func (id3v2 *ID3v23) Pictures() []AttachedPicture {
+	var result []AttachedPicture
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "APIC" {
+			continue
+		}
+		picture, err := parseAttachedPicture(id3v2.Frames[i].Value)
+		if err == nil {
+			result = append(result, *picture)
+		}
+	}
+	return result
+}
 
-	// Picture description
-	result = append(result, []byte(picture.Description)...)
-	result = append(result, 0x00)
+// AddPicture - add APIC frame. Pictures of the same type must have different descriptions
+func (id3v2 *ID3v23) AddPicture(picture AttachedPicture) error {
+	value, err := id3v2.encodePicture(&picture)
+	if err != nil {
+		return err
+	}
+	id3v2.Frames = append(id3v2.Frames, ID3v23Frame{
+		Key:   "APIC",
+		Value: value,
+	})
+	return nil
+}
 
-	// Picture data
-	result = append(result, picture.Data...)
+// RemovePicture - delete APIC frames of the picture type
+func (id3v2 *ID3v23) RemovePicture(pictureType byte) error {
+	frames := id3v2.Frames[:0]
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == "APIC" {
+			picture, err := parseAttachedPicture(id3v2.Frames[i].Value)
+			if err == nil && picture.PictureType == pictureType {
+				continue
+			}
+		}
+		frames = append(frames, id3v2.Frames[i])
+	}
+	id3v2.Frames = frames
+	return nil
+}
 
-	return id3v2.SetString("APIC", string(result))
+// ReplacePicture - replace APIC frames of the picture type with the picture
+func (id3v2 *ID3v23) ReplacePicture(picture AttachedPicture) error {
+	err := id3v2.RemovePicture(picture.PictureType)
+	if err != nil {
+		return err
+	}
+	return id3v2.AddPicture(picture)
 }
 
 func (id3v2 *ID3v23) DeleteTag(name string) error {
@@ -831,9 +1292,13 @@ func (id3v2 *ID3v23) GetStringTXXX(name string) (string, error) {
 }
 
 func (id3v2 *ID3v23) SetStringTXXX(name string, value string) error {
+	text, err := id3v2.encodeText(name + "\x00" + value)
+	if err != nil {
+		return err
+	}
 	result := ID3v23Frame{
 		Key:   "TXXX",
-		Value: SetString(name + "\x00" + value),
+		Value: text,
 	}
 
 	// find tag
@@ -865,3 +1330,41 @@ func (id3v2 *ID3v23) GetIntTXXX(name string) (int, error) {
 	}
 	return strconv.Atoi(str)
 }
+
+// parseID3v23Date - combine ID3v2.2/ID3v2.3 date frames:
+// year - 'YYYY', date - 'DDMM', clock - 'HHMM'. Date and clock are optional.
+func parseID3v23Date(year string, date string, clock string) (time.Time, error) {
+	if len(year) > 4 {
+		year = year[:4]
+	}
+	yearNumber, err := strconv.Atoi(year)
+	if err != nil {
+		return time.Now(), err
+	}
+
+	month, day := 1, 1
+	if len(date) == 4 {
+		day, err = strconv.Atoi(date[0:2])
+		if err != nil {
+			return time.Now(), err
+		}
+		month, err = strconv.Atoi(date[2:4])
+		if err != nil {
+			return time.Now(), err
+		}
+	}
+
+	hour, minute := 0, 0
+	if len(clock) == 4 {
+		hour, err = strconv.Atoi(clock[0:2])
+		if err != nil {
+			return time.Now(), err
+		}
+		minute, err = strconv.Atoi(clock[2:4])
+		if err != nil {
+			return time.Now(), err
+		}
+	}
+
+	return time.Date(yearNumber, time.Month(month), day, hour, minute, 0, 0, time.UTC), nil
+}
@@ -4,11 +4,11 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"image"
 	"image/jpeg"
 	"image/png"
 	"io"
-	"io/ioutil"
 	"os"
 	"strconv"
 	"strings"
@@ -25,29 +25,286 @@ func (flags id3v24Flags) IsUnsynchronisation() bool {
 	return GetBit(byte(flags), 7) == 1
 }
 
-func (flags id3v24Flags) SetUnsynchronisation(data bool) {
-	SetBit((*byte)(&flags), data, 7)
+func (flags *id3v24Flags) SetUnsynchronisation(data bool) {
+	SetBit((*byte)(flags), data, 7)
 }
 
 func (flags id3v24Flags) HasExtendedHeader() bool {
 	return GetBit(byte(flags), 6) == 1
 }
 
-func (flags id3v24Flags) SetExtendedHeader(data bool) {
-	SetBit((*byte)(&flags), data, 7)
+func (flags *id3v24Flags) SetExtendedHeader(data bool) {
+	SetBit((*byte)(flags), data, 6)
 }
 
 func (flags id3v24Flags) IsExperimentalIndicator() bool {
 	return GetBit(byte(flags), 5) == 1
 }
 
-func (flags id3v24Flags) SetExperimentalIndicator(data bool) {
-	SetBit((*byte)(&flags), data, 7)
+func (flags *id3v24Flags) SetExperimentalIndicator(data bool) {
+	SetBit((*byte)(flags), data, 5)
+}
+
+func (flags id3v24Flags) HasFooter() bool {
+	return GetBit(byte(flags), 4) == 1
+}
+
+func (flags *id3v24Flags) SetFooter(data bool) {
+	SetBit((*byte)(flags), data, 4)
+}
+
+// ID3v24ExtendedHeader - extended header of the tag
+type ID3v24ExtendedHeader struct {
+	Update          bool   // tag is an update of a tag found earlier in the file
+	HasCRC          bool   // CRC-32 of frames and padding is written on save
+	CRC             uint32 // CRC-32 of frames and padding, read or written on save
+	HasRestrictions bool   // Restrictions are written on save
+	Restrictions    byte   // tag restrictions %ppqrrstt: tag size, text encoding, text size, image encoding and size
+}
+
+// readID3v24ExtendedHeader - parse extended header at the start of tag data, size of the header is returned
+func readID3v24ExtendedHeader(data []byte) (*ID3v24ExtendedHeader, int, error) {
+	if len(data) < 6 {
+		return nil, 0, ErrIncorrectLength
+	}
+	size := ByteToIntSynchsafe(data[0:4])
+	flagBytes := int(data[4])
+	if size < 6 || size > len(data) || flagBytes < 1 || 5+flagBytes > size {
+		return nil, 0, ErrIncorrectLength
+	}
+
+	// [length][data] of each set flag, in order of flags
+	result := &ID3v24ExtendedHeader{}
+	flags := data[5]
+	position := 5 + flagBytes
+	for _, flag := range []byte{id3v24ExtendedUpdate, id3v24ExtendedCRC, id3v24ExtendedRestrictions} {
+		if flags&flag == 0 {
+			continue
+		}
+		if position >= size || position+1+int(data[position]) > size {
+			return nil, 0, ErrIncorrectLength
+		}
+		value := data[position+1 : position+1+int(data[position])]
+		position += 1 + len(value)
+
+		switch flag {
+		case id3v24ExtendedUpdate:
+			result.Update = true
+		case id3v24ExtendedCRC:
+			result.HasCRC = true
+			result.CRC = uint32(ByteToIntSynchsafe(value))
+		case id3v24ExtendedRestrictions:
+			result.HasRestrictions = true
+			if len(value) > 0 {
+				result.Restrictions = value[0]
+			}
+		}
+	}
+	return result, size, nil
+}
+
+// encode - extended header, CRC is set to CRC-32 of data after the extended header: frames and padding
+func (header *ID3v24ExtendedHeader) encode(data []byte) []byte {
+	var flags byte
+	var values []byte
+	if header.Update {
+		flags |= id3v24ExtendedUpdate
+		values = append(values, 0)
+	}
+	if header.HasCRC {
+		flags |= id3v24ExtendedCRC
+		header.CRC = crc32.ChecksumIEEE(data)
+		// 35 bit synchsafe integer
+		values = append(values, 5, byte(header.CRC>>28)&0x7F)
+		values = append(values, IntToByteSynchsafe(int(header.CRC))...)
+	}
+	if header.HasRestrictions {
+		flags |= id3v24ExtendedRestrictions
+		values = append(values, 1, header.Restrictions)
+	}
+
+	result := IntToByteSynchsafe(6 + len(values))
+	result = append(result, 1, flags)
+	return append(result, values...)
+}
+
+var id3v24TimestampLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02T15",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// id3v24FrameFlags - frame status (high byte) and format (low byte) flags
+type id3v24FrameFlags uint16
+
+func (flags id3v24FrameFlags) String() string {
+	return strconv.Itoa(int(flags))
+}
+
+func (flags id3v24FrameFlags) HasGroup() bool {
+	return flags&id3v24FrameGrouping != 0
+}
+
+func (flags *id3v24FrameFlags) SetGroup(data bool) {
+	flags.set(id3v24FrameGrouping, data)
+}
+
+func (flags id3v24FrameFlags) IsCompressed() bool {
+	return flags&id3v24FrameCompression != 0
+}
+
+func (flags *id3v24FrameFlags) SetCompression(data bool) {
+	flags.set(id3v24FrameCompression, data)
+}
+
+func (flags id3v24FrameFlags) IsEncrypted() bool {
+	return flags&id3v24FrameEncryption != 0
+}
+
+func (flags id3v24FrameFlags) IsUnsynchronised() bool {
+	return flags&id3v24FrameUnsync != 0
+}
+
+func (flags *id3v24FrameFlags) SetUnsynchronisation(data bool) {
+	flags.set(id3v24FrameUnsync, data)
+}
+
+func (flags id3v24FrameFlags) HasDataLength() bool {
+	return flags&id3v24FrameDataLength != 0
+}
+
+func (flags *id3v24FrameFlags) SetDataLength(data bool) {
+	flags.set(id3v24FrameDataLength, data)
+}
+
+func (flags *id3v24FrameFlags) set(flag id3v24FrameFlags, data bool) {
+	if data {
+		*flags |= flag
+	} else {
+		*flags &^= flag
+	}
+}
+
+// id3v23 - flags in ID3v2.3 layout, unsynchronisation and data length indicator are dropped
+func (flags id3v24FrameFlags) id3v23() id3v23FrameFlags {
+	result := id3v23FrameFlags(flags&id3v24FrameStatus) << 1
+	if flags.IsCompressed() {
+		result |= id3v23FrameCompression
+	}
+	if flags.IsEncrypted() {
+		result |= id3v23FrameEncryption
+	}
+	if flags.HasGroup() {
+		result |= id3v23FrameGrouping
+	}
+	return result
 }
 
 type ID3v24Frame struct {
 	Key   string
 	Value []byte
+
+	// Flags - frame flags. Value is decoded on read and encoded by flags on save,
+	// except encrypted frames: their Value is kept as is
+	Flags id3v24FrameFlags
+	Group byte // group identifier if Flags.HasGroup()
+}
+
+// decode - set Value from frame data: group identifier, data length indicator,
+// unsynchronisation and compression are removed
+func (frame *ID3v24Frame) decode(data []byte, unsynchronisation bool) error {
+	if frame.Flags.IsEncrypted() {
+		frame.Value = data
+		return nil
+	}
+	if unsynchronisation {
+		// all frames of unsynchronised tag are unsynchronised
+		frame.Flags.SetUnsynchronisation(true)
+	}
+
+	if frame.Flags.HasGroup() {
+		if len(data) < 1 {
+			return ErrIncorrectLength
+		}
+		frame.Group = data[0]
+		data = data[1:]
+	}
+	if frame.Flags.HasDataLength() {
+		if len(data) < 4 {
+			return ErrIncorrectLength
+		}
+		data = data[4:]
+	}
+	if frame.Flags.IsUnsynchronised() {
+		data = resynchronise(data)
+	}
+	if frame.Flags.IsCompressed() {
+		var err error
+		data, err = decompressData(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	frame.Value = data
+	return nil
+}
+
+// encode - frame header and data encoded by flags, unsynchronisation and compression
+// are applied if set
+func (frame *ID3v24Frame) encode(unsynchronisation bool, compression bool) ([]byte, error) {
+	flags := frame.Flags
+	data := frame.Value
+	if !flags.IsEncrypted() {
+		if unsynchronisation {
+			flags.SetUnsynchronisation(true)
+		}
+		if compression {
+			flags.SetCompression(true)
+		}
+		if flags.IsUnsynchronised() || flags.IsCompressed() {
+			flags.SetDataLength(true)
+		}
+
+		var prefix []byte
+		if flags.HasGroup() {
+			prefix = append(prefix, frame.Group)
+		}
+		if flags.HasDataLength() {
+			prefix = append(prefix, IntToByteSynchsafe(len(data))...)
+		}
+		if flags.IsCompressed() {
+			var err error
+			data, err = compressData(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if flags.IsUnsynchronised() {
+			data = unsynchronise(data)
+		}
+		data = append(prefix, data...)
+	}
+
+	result := make([]byte, id3v2FrameHeaderSize, id3v2FrameHeaderSize+len(data))
+
+	// Frame id
+	copy(result, frame.Key)
+
+	// Frame size, synchsafe
+	if len(data) >= 1<<28 {
+		return nil, ErrIncorrectLength
+	}
+	copy(result[4:8], IntToByteSynchsafe(len(data)))
+
+	// Frame flags
+	result[8] = byte(flags >> 8)
+	result[9] = byte(flags)
+
+	return append(result, data...), nil
 }
 
 type ID3v24 struct {
@@ -58,7 +315,35 @@ type ID3v24 struct {
 	Length     int
 	Frames     []ID3v24Frame
 
-	Data []byte
+	// ExtendedHeader - nil if tag has no extended header, written on save if set
+	ExtendedHeader *ID3v24ExtendedHeader
+	crc            uint32 // CRC-32 of frames and padding read or saved
+
+	// Data - audio data after tag, nil if audio is read from the source on demand
+	Data  []byte
+	audio *fileRegion
+
+	// Padding - padding written by SaveInPlace if tag doesn't fit into the file,
+	// DefaultPadding if 0, no padding if negative
+	Padding int
+
+	// Encoding - text encoding of frames set by setters: EncodingISO88591, EncodingUTF16,
+	// EncodingUTF16BE or EncodingUTF8. If empty, ISO-8859-1 is used if text is representable, UTF-8 otherwise
+	Encoding string
+
+	// Unsynchronise, Compress - unsynchronise or compress all frames on save,
+	// frames are encoded by their flags otherwise
+	Unsynchronise bool
+	Compress      bool
+
+	// PlainFrameSizes - frame sizes were read as plain integers instead of synchsafe ones,
+	// as written by broken taggers. Sizes are always written synchsafe
+	PlainFrameSizes bool
+
+	// AtEnd - write tag with footer after audio. Set if the tag is read from the end of the file
+	AtEnd   bool
+	trailer []byte // data after the tag at the end of the file, e.g. ID3v1 tag
+	chained bool   // tags chained by SEEK frames are merged, they are left in audio data
 }
 
 type AttachedPicture struct {
@@ -68,6 +353,14 @@ type AttachedPicture struct {
 	Data        []byte
 }
 
+// Comment - comment of COMM frame. Comments are unique by language (ISO-639-2, 3 characters)
+// and short content description. Players keep service data (iTunNORM, iTunSMPB, ...) in comments with description
+type Comment struct {
+	Language    string
+	Description string
+	Text        string
+}
+
 func (id3v2 *ID3v24) GetAllTagNames() []string {
 	var result []string
 	for i := range id3v2.Frames {
@@ -81,7 +374,11 @@ func (id3v2 *ID3v24) GetVersion() Version {
 }
 
 func (id3v2 *ID3v24) GetFileData() []byte {
-	return id3v2.Data
+	return readSection(audioSection(id3v2.Data, id3v2.audio))
+}
+
+func (id3v2 *ID3v24) AudioReader() io.Reader {
+	return audioSection(id3v2.Data, id3v2.audio)
 }
 
 func (id3v2 *ID3v24) GetTitle() (string, error) {
@@ -101,23 +398,9 @@ func (id3v2 *ID3v24) GetYear() (int, error) {
 	return date.Year(), err
 }
 
+// GetComment - comment without short content description of any language
 func (id3v2 *ID3v24) GetComment() (string, error) {
-	// id3v2
-	// Comment struct must be greater than 4
-	// [lang \x00 text] - comment format
-	// lang - 3 symbols
-	// \x00 - const, delimeter
-	// text - all after
-	commentStr, err := id3v2.GetString("COMM")
-	if err != nil {
-		return "", err
-	}
-
-	if len(commentStr) < 4 {
-		return "", ErrIncorrectLength
-	}
-
-	return commentStr[4:], nil
+	return id3v2.GetCommentByKey("", "")
 }
 
 func (id3v2 *ID3v24) GetGenre() (string, error) {
@@ -214,6 +497,24 @@ func (id3v2 *ID3v24) GetPicture() (image.Image, error) {
 	}
 }
 
+// GetLyrics - text of USLT frame with language, of any language if it's empty
+func (id3v2 *ID3v24) GetLyrics(language string) (string, error) {
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "USLT" {
+			continue
+		}
+		// malformed frames are skipped
+		lyrics, err := parseComment(id3v2.Frames[i].Value)
+		if err != nil {
+			continue
+		}
+		if language == "" || strings.EqualFold(lyrics.Language, language) {
+			return lyrics.Text, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
 func (id3v2 *ID3v24) SetTitle(title string) error {
 	return id3v2.SetString("TIT2", title)
 }
@@ -250,8 +551,16 @@ func (id3v2 *ID3v24) SetYear(year int) error {
 	)
 }
 
+// SetComment - set comment without short content description, keep its language
 func (id3v2 *ID3v24) SetComment(comment string) error {
-	return id3v2.SetString("COMM", comment)
+	language := "eng"
+	for _, found := range id3v2.Comments() {
+		if found.Description == "" {
+			language = found.Language
+			break
+		}
+	}
+	return id3v2.SetCommentByKey(Comment{Language: language, Text: comment})
 }
 
 func (id3v2 *ID3v24) SetGenre(genre string) error {
@@ -267,7 +576,7 @@ func (id3v2 *ID3v24) SetDate(date time.Time) error {
 }
 
 func (id3v2 *ID3v24) SetArranger(arranger string) error {
-	return id3v2.SetString("IPLS", arranger)
+	return id3v2.SetString("TIPL", arranger)
 }
 
 func (id3v2 *ID3v24) SetAuthor(author string) error {
@@ -275,7 +584,7 @@ func (id3v2 *ID3v24) SetAuthor(author string) error {
 }
 
 func (id3v2 *ID3v24) SetBPM(bmp int) error {
-	return id3v2.SetInt("TBMP", bmp)
+	return id3v2.SetInt("TBPM", bmp)
 }
 
 func (id3v2 *ID3v24) SetCatalogNumber(catalogNumber string) error {
@@ -328,7 +637,7 @@ func (id3v2 *ID3v24) SetPicture(picture image.Image) error {
 		// Set default params
 		newPicture := AttachedPicture{
 			MIME:        "image/png",
-			PictureType: 2, // Other file info
+			PictureType: PictureTypeFrontCover,
 			Description: "",
 			Data:        buf.Bytes(),
 		}
@@ -341,6 +650,37 @@ func (id3v2 *ID3v24) SetPicture(picture image.Image) error {
 	return id3v2.SetAttachedPicture(attacheched)
 }
 
+// SetLyrics - replace text of USLT frame with language or add frame without content descriptor
+func (id3v2 *ID3v24) SetLyrics(language string, lyrics string) error {
+	language, err := commentLanguage(language)
+	if err != nil {
+		return err
+	}
+
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "USLT" {
+			continue
+		}
+		found, err := parseComment(id3v2.Frames[i].Value)
+		if err != nil || !strings.EqualFold(found.Language, language) {
+			continue
+		}
+		text, err := id3v2.encodeText(found.Description + "\x00" + lyrics)
+		if err != nil {
+			return err
+		}
+		id3v2.Frames[i].Value = encodeComment(found.Language, text)
+		return nil
+	}
+
+	text, err := id3v2.encodeText("\x00" + lyrics)
+	if err != nil {
+		return err
+	}
+	id3v2.Frames = append(id3v2.Frames, ID3v24Frame{Key: "USLT", Value: encodeComment(language, text)})
+	return nil
+}
+
 func (id3v2 *ID3v24) DeleteAll() error {
 	id3v2.Frames = []ID3v24Frame{}
 	return nil
@@ -362,8 +702,78 @@ func (id3v2 *ID3v24) DeleteYear() error {
 	return id3v2.DeleteTag("TDOR")
 }
 
+// DeleteComment - delete comments without short content description
 func (id3v2 *ID3v24) DeleteComment() error {
-	return id3v2.DeleteTag("COMM")
+	return id3v2.DeleteCommentByKey("", "")
+}
+
+// Comments - comments of all COMM frames, malformed frames are skipped
+func (id3v2 *ID3v24) Comments() []Comment {
+	var result []Comment
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "COMM" {
+			continue
+		}
+		comment, err := parseComment(id3v2.Frames[i].Value)
+		if err == nil {
+			result = append(result, *comment)
+		}
+	}
+	return result
+}
+
+// GetCommentByKey - text of comment with language and description, any language if it's empty
+func (id3v2 *ID3v24) GetCommentByKey(language string, description string) (string, error) {
+	for _, comment := range id3v2.Comments() {
+		if (language == "" || strings.EqualFold(comment.Language, language)) && comment.Description == description {
+			return comment.Text, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+// SetCommentByKey - replace comment with the same language and description or add it
+func (id3v2 *ID3v24) SetCommentByKey(comment Comment) error {
+	language, err := commentLanguage(comment.Language)
+	if err != nil {
+		return err
+	}
+	text, err := id3v2.encodeText(comment.Description + "\x00" + comment.Text)
+	if err != nil {
+		return err
+	}
+	value := encodeComment(language, text)
+
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "COMM" {
+			continue
+		}
+		found, err := parseComment(id3v2.Frames[i].Value)
+		if err == nil && strings.EqualFold(found.Language, language) && found.Description == comment.Description {
+			id3v2.Frames[i].Value = value
+			return nil
+		}
+	}
+
+	id3v2.Frames = append(id3v2.Frames, ID3v24Frame{Key: "COMM", Value: value})
+	return nil
+}
+
+// DeleteCommentByKey - delete comments with language and description, of any language if it's empty
+func (id3v2 *ID3v24) DeleteCommentByKey(language string, description string) error {
+	frames := id3v2.Frames[:0]
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == "COMM" {
+			comment, err := parseComment(id3v2.Frames[i].Value)
+			if err == nil && (language == "" || strings.EqualFold(comment.Language, language)) &&
+				comment.Description == description {
+				continue
+			}
+		}
+		frames = append(frames, id3v2.Frames[i])
+	}
+	id3v2.Frames = frames
+	return nil
 }
 
 func (id3v2 *ID3v24) DeleteGenre() error {
@@ -379,7 +789,7 @@ func (id3v2 *ID3v24) DeleteDate() error {
 }
 
 func (id3v2 *ID3v24) DeleteArranger() error {
-	return id3v2.DeleteTag("IPLS")
+	return id3v2.DeleteTag("TIPL")
 }
 
 func (id3v2 *ID3v24) DeleteAuthor() error {
@@ -387,7 +797,7 @@ func (id3v2 *ID3v24) DeleteAuthor() error {
 }
 
 func (id3v2 *ID3v24) DeleteBPM() error {
-	return id3v2.DeleteTag("TBMP")
+	return id3v2.DeleteTag("TBPM")
 }
 
 func (id3v2 *ID3v24) DeleteCatalogNumber() error {
@@ -426,102 +836,233 @@ func (id3v2 *ID3v24) DeleteTrackNumber() error {
 	return id3v2.DeleteTag("TRCK")
 }
 
+// DeletePicture - delete all APIC frames
 func (id3v2 *ID3v24) DeletePicture() error {
-	return id3v2.DeleteTag("APIC")
+	frames := id3v2.Frames[:0]
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "APIC" {
+			frames = append(frames, id3v2.Frames[i])
+		}
+	}
+	id3v2.Frames = frames
+	return nil
+}
+
+// DeleteLyrics - delete USLT frames with language, all if it's empty
+func (id3v2 *ID3v24) DeleteLyrics(language string) error {
+	frames := id3v2.Frames[:0]
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == "USLT" {
+			lyrics, err := parseComment(id3v2.Frames[i].Value)
+			if language == "" || (err == nil && strings.EqualFold(lyrics.Language, language)) {
+				continue
+			}
+		}
+		frames = append(frames, id3v2.Frames[i])
+	}
+	id3v2.Frames = frames
+	return nil
 }
 
 func (id3v2 *ID3v24) SaveFile(path string) error {
-	file, err := os.Create(path)
+	return saveFile(path, id3v2.Save, SaveOptions{})
+}
+
+// SaveInPlace - write tag over the tag and padding of file read by ReadID3v24.
+// If tag doesn't fit, audio is moved and tag is written with Padding.
+// Tag with footer has no padding, it's written in place only if it fits exactly.
+// Tag AtEnd is written after audio
+func (id3v2 *ID3v24) SaveInPlace(file *os.File) error {
+	frames, err := id3v2.encodeFrames()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	if id3v2.AtEnd {
+		return id3v2.saveAtEndInPlace(file, frames)
+	}
 
-	return id3v2.Save(file)
-}
+	minPadding, padding := int64(0), inPlacePadding(id3v2.Padding)
+	if id3v2.Flags.HasFooter() {
+		minPadding, padding = 1<<62, 0
+	}
+	size := int64(len(id3v2.encodeTag(frames, 0)))
+	length, err := saveInPlace(file, id3v2.Data, id3v2.audio, size, minPadding, padding,
+		func(length int64) ([]byte, error) {
+			return id3v2.encodeTag(frames, int(length-size)), nil
+		})
+	if err != nil {
+		return err
+	}
+	id3v2.Length = int(length) - 10
+	if id3v2.Flags.HasFooter() {
+		id3v2.Length -= 10
+	}
 
-func (id3v2 *ID3v24) Save(input io.WriteSeeker) error {
-	// write header
-	err := id3v2.writeHeaderID3v24(input)
+	// tag read from the end of the file is replaced by the trailer
+	end := length + audioSection(id3v2.Data, id3v2.audio).Size()
+	_, err = file.WriteAt(id3v2.trailer, end)
+	if err != nil {
+		return err
+	}
+	err = file.Truncate(end + int64(len(id3v2.trailer)))
 	if err != nil {
 		return err
 	}
+	return inPlaceWritten(file, id3v2.Data, id3v2.audio)
+}
 
-	// write tags
-	err = id3v2.writeFramesID3v24(input)
+// saveAtEndInPlace - move audio to the start of file, write tag and trailer after it
+func (id3v2 *ID3v24) saveAtEndInPlace(file *os.File, frames []byte) error {
+	info, err := file.Stat()
 	if err != nil {
 		return err
 	}
 
-	// write data
-	_, err = input.Write(id3v2.Data)
+	audio := audioSection(id3v2.Data, id3v2.audio)
+	if id3v2.Data == nil && id3v2.audio != nil && id3v2.audio.sameFile(info) {
+		err = moveFileData(file, id3v2.audio.offset, 0, id3v2.audio.size)
+		if err != nil {
+			return err
+		}
+		id3v2.audio.offset = 0
+	} else {
+		_, err = file.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+		err = writeSection(file, audio)
+		if err != nil {
+			return err
+		}
+	}
+
+	data := id3v2.encodeTag(frames, 0)
+	id3v2.Length = len(data) - 20
+	data = append(data, id3v2.trailer...)
+	_, err = file.WriteAt(data, audio.Size())
 	if err != nil {
 		return err
 	}
-	return nil
+	err = file.Truncate(audio.Size() + int64(len(data)))
+	if err != nil {
+		return err
+	}
+	return inPlaceWritten(file, id3v2.Data, id3v2.audio)
 }
 
-func (id3v2 *ID3v24) writeHeaderID3v24(writer io.Writer) error {
-	headerByte := make([]byte, 10)
+func (id3v2 *ID3v24) Save(input io.WriteSeeker) error {
+	frames, err := id3v2.encodeFrames()
+	if err != nil {
+		return err
+	}
 
-	// ID3
-	copy(headerByte[0:3], id3MarkerValue)
+	// write tag before or after audio
+	data := id3v2.encodeTag(frames, 0)
+	if !id3v2.AtEnd {
+		err = writeTag(input, data)
+		if err != nil {
+			return err
+		}
+	}
 
-	// Version, Subversion, Flags
-	copy(headerByte[3:6], []byte{4, 0, 0})
+	// write data
+	err = writeAudio(input, id3v2.Data, id3v2.audio)
+	if err != nil {
+		return err
+	}
 
-	// Length
-	length := id3v2.getFramesLength()
-	lengthByte := IntToByteSynchsafe(length)
-	copy(headerByte[6:10], lengthByte)
+	if id3v2.AtEnd {
+		err = writeTag(input, data)
+		if err != nil {
+			return err
+		}
+	}
+	return writeTag(input, id3v2.trailer)
+}
 
-	nWriten, err := writer.Write(headerByte)
+func writeTag(output io.Writer, data []byte) error {
+	nWriten, err := output.Write(data)
 	if err != nil {
 		return err
 	}
-	if nWriten != 10 {
+	if nWriten != len(data) {
 		return ErrWriting
 	}
 	return nil
 }
 
-func (id3v2 *ID3v24) writeFramesID3v24(writer io.Writer) error {
-	for i := range id3v2.Frames {
-		header := make([]byte, 10)
+// encodeTag - header, extended header, frames, padding and footer
+func (id3v2 *ID3v24) encodeTag(frames []byte, padding int) []byte {
+	var extendedHeader []byte
+	if id3v2.ExtendedHeader != nil {
+		// CRC covers padding too
+		data := append(append([]byte{}, frames...), make([]byte, padding)...)
+		extendedHeader = id3v2.ExtendedHeader.encode(data)
+		id3v2.crc = crc32.ChecksumIEEE(data)
+	}
 
-		// Frame id
-		copy(header, id3v2.Frames[i].Key)
+	headerByte := make([]byte, 10)
 
-		// Frame size
-		length := len(id3v2.Frames[i].Value)
-		header[4] = byte(length >> 24)
-		header[5] = byte(length >> 16)
-		header[6] = byte(length >> 8)
-		header[7] = byte(length)
+	// ID3
+	copy(headerByte[0:3], id3MarkerValue)
 
-		// write header
-		_, err := writer.Write(header)
-		if err != nil {
-			return err
-		}
+	// Version, Subversion
+	copy(headerByte[3:5], []byte{4, 0})
 
-		// write data
-		_, err = writer.Write(id3v2.Frames[i].Value)
-		if err != nil {
-			return err
-		}
+	// Flags
+	flags := id3v2.Flags
+	flags.SetExtendedHeader(id3v2.ExtendedHeader != nil)
+	if id3v2.Unsynchronise {
+		flags.SetUnsynchronisation(true)
+	}
+	if id3v2.AtEnd {
+		// tag at the end is found by its footer
+		flags.SetFooter(true)
+	}
+	headerByte[5] = byte(flags)
+
+	// Length
+	length := len(extendedHeader) + len(frames) + padding
+	copy(headerByte[6:10], IntToByteSynchsafe(length))
+
+	result := make([]byte, 0, 20+length)
+	result = append(result, headerByte...)
+	result = append(result, extendedHeader...)
+	result = append(result, frames...)
+	result = append(result, make([]byte, padding)...)
+
+	// footer is a copy of header with '3DI' marker
+	if flags.HasFooter() {
+		result = append(result, id3v24FooterMarker...)
+		result = append(result, headerByte[3:]...)
 	}
+	return result
+}
 
+// VerifyCRC - check CRC-32 of the extended header against frames and padding read or saved.
+// ErrTagNotFound if tag has no CRC
+func (id3v2 *ID3v24) VerifyCRC() error {
+	if id3v2.ExtendedHeader == nil || !id3v2.ExtendedHeader.HasCRC {
+		return ErrTagNotFound
+	}
+	if id3v2.ExtendedHeader.CRC != id3v2.crc {
+		return ErrIncorrectCRC
+	}
 	return nil
 }
 
-func (id3v2 *ID3v24) getFramesLength() int {
-	result := 0
+// encodeFrames - frames encoded by their flags, all frames of unsynchronised tag are unsynchronised
+func (id3v2 *ID3v24) encodeFrames() ([]byte, error) {
+	unsynchronisation := id3v2.Unsynchronise || id3v2.Flags.IsUnsynchronisation()
+	var result []byte
 	for i := range id3v2.Frames {
-		// 10 - size of tag header
-		result += 10 + len(id3v2.Frames[i].Value)
+		frame, err := id3v2.Frames[i].encode(unsynchronisation, id3v2.Compress)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, frame...)
 	}
-	return result
+	return result, nil
 }
 
 func (id3v2 *ID3v24) String() string {
@@ -550,38 +1091,145 @@ func checkID3v24(input io.ReadSeeker) bool {
 	}
 	marker := string(data[0:3])
 
-	// id3v2
+	// id3v2 at the end of the file
 	if marker != id3MarkerValue {
-		return false
+		return checkAppendedID3v24(input)
 	}
 
 	versionByte := data[3]
 	return versionByte == 4
 }
 
+// NewID3v24 - empty tag before audio data
+func NewID3v24(audio io.Reader) (*ID3v24, error) {
+	region, err := readerRegion(audio)
+	if err != nil {
+		return nil, err
+	}
+	return &ID3v24{
+		Marker:  id3MarkerValue,
+		Version: VersionID3v24,
+		Frames:  []ID3v24Frame{},
+		audio:   region,
+	}, nil
+}
+
+// ReadID3v24 - tag at the start of the file, tags chained by SEEK frames and tag appended
+// at the end of the file (before ID3v1 tag). Later tags are merged into the first one.
+// Chained tags inside audio are left in audio data
+// nolint:gocyclo
 func ReadID3v24(input io.ReadSeeker) (*ID3v24, error) {
-	header := ID3v24{}
 	if input == nil {
 		return nil, ErrEmptyFile
 	}
+	size, err := input.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
 
-	// Header size
-	headerByte, err := seekAndRead(input, 0, io.SeekStart, 10)
+	// tag at the start of the file
+	var tags []*ID3v24
+	var start int64
+	marker, err := seekAndRead(input, 0, io.SeekStart, 3)
+	if err == nil && string(marker) == id3MarkerValue {
+		var tag *ID3v24
+		tag, start, err = readID3v24Tag(input, 0)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	// tag at the end of the file
+	end := size
+	appendedStart, appendedEnd := appendedID3v24(input)
+	if appendedStart < start {
+		appendedStart = -1
+	}
+
+	// tags chained by SEEK frames
+	for tagEnd := start; len(tags) > 0; {
+		seek, err := tags[len(tags)-1].GetBytes(id3v24FrameSEEK)
+		if err != nil || len(seek) < 4 {
+			break
+		}
+		next := tagEnd + int64(ByteToInt(seek[0:4]))
+		if next == appendedStart || !checkID3v24At(input, next) {
+			break
+		}
+		tag, nextEnd, err := readID3v24Tag(input, next)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+		tagEnd = nextEnd
+	}
+	chained := len(tags) > 1
+
+	var trailer []byte
+	if appendedStart >= 0 {
+		tag, _, err := readID3v24Tag(input, appendedStart)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+		end = appendedStart
+
+		// data after the tag, e.g. ID3v1 tag
+		if appendedEnd < size {
+			trailer, err = seekAndRead(input, appendedEnd, io.SeekStart, int(size-appendedEnd))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(tags) == 0 {
+		return nil, errors.New("error file marker")
+	}
+
+	header := tags[0]
+	for _, tag := range tags[1:] {
+		header.merge(tag)
+	}
+	if len(tags) > 1 {
+		// chain isn't written back
+		header.deleteFrames(id3v24FrameSEEK)
+	}
+	// tag without tag at the start of the file stays at the end
+	header.AtEnd = start == 0
+	header.trailer = trailer
+	header.chained = chained
+
+	// file data after the first tag
+	header.audio, err = readRegion(input, start, end-start)
 	if err != nil {
 		return nil, err
 	}
+	return header, nil
+}
+
+// readID3v24Tag - tag at offset without audio, end of the tag with footer is returned
+// nolint:funlen,gocyclo
+func readID3v24Tag(input io.ReadSeeker, offset int64) (*ID3v24, int64, error) {
+	header := ID3v24{}
+
+	// Header size
+	headerByte, err := seekAndRead(input, offset, io.SeekStart, 10)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	// Marker
 	marker := string(headerByte[0:3])
 	if marker != "ID3" {
-		return nil, errors.New("error file marker")
+		return nil, 0, errors.New("error file marker")
 	}
 	header.Marker = marker
 
 	// Version
 	versionByte := headerByte[3]
 	if versionByte != 4 {
-		return nil, ErrUnsupportedFormat
+		return nil, 0, ErrUnsupportedFormat
 	}
 	header.Version = VersionID3v24
 
@@ -596,70 +1244,233 @@ func ReadID3v24(input io.ReadSeeker) (*ID3v24, error) {
 	length := ByteToIntSynchsafe(headerByte[6:10])
 	header.Length = length
 
-	// Extended headers
-	header.Frames = []ID3v24Frame{}
+	// Tag data
+	data := make([]byte, length)
+	_, err = io.ReadFull(input, data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Extended header
 	curRead := 0
-	for curRead < length {
-		var bytesExtendedHeader []byte
-		bytesExtendedHeader, err = readBytes(input, 10)
+	if header.Flags.HasExtendedHeader() {
+		header.ExtendedHeader, curRead, err = readID3v24ExtendedHeader(data)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
+	}
+	framesStart := curRead
 
-		// Frame identifier
-		key := string(bytesExtendedHeader[0:4])
+	// Frames
+	header.Frames = []ID3v24Frame{}
+	for curRead+10 <= length {
+		bytesExtendedHeader := data[curRead : curRead+10]
 
-		/*if bytesExtendedHeader[0] == 0 &&
-		bytesExtendedHeader[1] == 0 &&
-		bytesExtendedHeader[2] == 0 &&
-		bytesExtendedHeader[3] == 0 {
+		// Padding
+		if bytesExtendedHeader[0] == 0 {
 			break
-		}*/
+		}
 
-		// Frame data size
-		size := ByteToInt(bytesExtendedHeader[4:8])
+		// Frame identifier
+		key := string(bytesExtendedHeader[0:4])
 
-		var bytesExtendedValue []byte
-		bytesExtendedValue, err = readBytes(input, size)
-		if err != nil {
-			return nil, err
+		// Frame data size, plain integer if written by broken tagger
+		size, plain := id3v24FrameSize(data, curRead)
+		if curRead+10+size > length {
+			return nil, 0, errors.New("error extended value length")
 		}
+		header.PlainFrameSizes = header.PlainFrameSizes || plain
 
-		header.Frames = append(header.Frames, ID3v24Frame{
-			key,
-			bytesExtendedValue,
-		})
+		frame := ID3v24Frame{
+			Key:   key,
+			Flags: id3v24FrameFlags(ByteToInt(bytesExtendedHeader[8:10])),
+		}
+		err = frame.decode(data[curRead+10:curRead+10+size], header.Flags.IsUnsynchronisation())
+		if err != nil {
+			return nil, 0, err
+		}
+		header.Frames = append(header.Frames, frame)
 
 		curRead += 10 + size
 	}
 
-	// TODO
-	if curRead != length {
-		return nil, errors.New("error extended frames")
+	// CRC of frames and padding between extended header and footer
+	header.crc = crc32.ChecksumIEEE(data[framesStart:])
+
+	// Footer
+	end := offset + int64(10+length)
+	if header.Flags.HasFooter() {
+		footer, err := seekAndRead(input, end, io.SeekStart, 10)
+		if err == nil && string(footer[0:3]) == id3v24FooterMarker {
+			end += 10
+		}
+	}
+
+	return &header, end, nil
+}
+
+// id3v24FrameSize - size of frame at offset of tag data. Synchsafe size is used if the frame ends
+// at the next frame, padding or end of data, plain size otherwise if it does (true is returned)
+func id3v24FrameSize(data []byte, offset int) (int, bool) {
+	sizeBytes := data[offset+4 : offset+8]
+	synchsafe := ByteToIntSynchsafe(sizeBytes)
+	plain := ByteToInt(sizeBytes)
+	valid := sizeBytes[0]|sizeBytes[1]|sizeBytes[2]|sizeBytes[3] < 0x80
+	if synchsafe == plain || (valid && id3v24FrameEnd(data, offset+10+synchsafe)) {
+		return synchsafe, false
+	}
+	if id3v24FrameEnd(data, offset+10+plain) {
+		return plain, true
 	}
+	return synchsafe, false
+}
 
-	// file data
-	header.Data, err = ioutil.ReadAll(input)
+// id3v24FrameEnd - end of frame is the next frame header, padding or end of data
+func id3v24FrameEnd(data []byte, end int) bool {
+	switch {
+	case end > len(data):
+		return false
+	case end == len(data) || data[end] == 0:
+		return true
+	case end+4 > len(data):
+		return false
+	}
+	for _, b := range data[end : end+4] {
+		if (b < 'A' || b > 'Z') && (b < '0' || b > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// appendedID3v24 - start and end of ID3v2.4 tag with footer at the end of input or before ID3v1 tag,
+// -1 if there is no tag
+func appendedID3v24(input io.ReadSeeker) (int64, int64) {
+	size, err := input.Seek(0, io.SeekEnd)
 	if err != nil {
-		return nil, err
+		return -1, -1
 	}
+	for _, end := range []int64{size, size - id3v1SizeHeader} {
+		if end < 20 {
+			continue
+		}
+		footer, err := seekAndRead(input, end-10, io.SeekStart, 10)
+		if err != nil || string(footer[0:3]) != id3v24FooterMarker || footer[3] != 4 {
+			continue
+		}
+		start := end - 20 - int64(ByteToIntSynchsafe(footer[6:10]))
+		if checkID3v24At(input, start) {
+			return start, end
+		}
+	}
+	return -1, -1
+}
 
-	return &header, nil
+// checkAppendedID3v24 - ID3v2.4 tag at the end of input
+func checkAppendedID3v24(input io.ReadSeeker) bool {
+	start, _ := appendedID3v24(input)
+	return start >= 0
 }
 
+// checkID3v24At - ID3v2.4 header at offset
+func checkID3v24At(input io.ReadSeeker, offset int64) bool {
+	if offset < 0 {
+		return false
+	}
+	data, err := seekAndRead(input, offset, io.SeekStart, 4)
+	return err == nil && string(data[0:3]) == id3MarkerValue && data[3] == 4
+}
+
+// merge - frames of update tag found later in the file replace frames with the same identifier,
+// tag which isn't an update replaces all frames
+func (id3v2 *ID3v24) merge(later *ID3v24) {
+	id3v2.PlainFrameSizes = id3v2.PlainFrameSizes || later.PlainFrameSizes
+	if later.ExtendedHeader == nil || !later.ExtendedHeader.Update {
+		id3v2.Frames = later.Frames
+		return
+	}
+	for i := range later.Frames {
+		id3v2.deleteFrames(later.Frames[i].Key)
+	}
+	id3v2.Frames = append(id3v2.Frames, later.Frames...)
+}
+
+// deleteFrames - delete all frames with identifier
+func (id3v2 *ID3v24) deleteFrames(name string) {
+	frames := id3v2.Frames[:0]
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != name {
+			frames = append(frames, id3v2.Frames[i])
+		}
+	}
+	id3v2.Frames = frames
+}
+
+// GetString - text of the frame, the first value for text information frames
 func (id3v2 *ID3v24) GetString(name string) (string, error) {
 	for i := range id3v2.Frames {
 		if id3v2.Frames[i].Key == name {
-			return GetString(id3v2.Frames[i].Value)
+			str, err := GetString(id3v2.Frames[i].Value)
+			if err != nil || !isID3v24TextFrame(name) {
+				return str, err
+			}
+			return strings.SplitN(str, "\x00", 2)[0], nil
 		}
 	}
 	return "", ErrTagNotFound
 }
 
+// GetValues - all values of text information frame separated by $00 (00)
+func (id3v2 *ID3v24) GetValues(name string) ([]string, error) {
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == name {
+			str, err := GetString(id3v2.Frames[i].Value)
+			if err != nil {
+				return nil, err
+			}
+			values := strings.Split(str, "\x00")
+			// terminated string
+			if len(values) > 1 && values[len(values)-1] == "" {
+				values = values[:len(values)-1]
+			}
+			return values, nil
+		}
+	}
+	return nil, ErrTagNotFound
+}
+
+// SetValues - write values to text information frame, empty values delete it
+func (id3v2 *ID3v24) SetValues(name string, values []string) error {
+	if len(values) == 0 {
+		id3v2.deleteFrames(name)
+		return nil
+	}
+	return id3v2.SetString(name, strings.Join(values, "\x00"))
+}
+
+// isID3v24TextFrame - text information frames T000-TZZZ with list of values,
+// except user defined TXXX and involved people lists TIPL, TMCL of role and name pairs
+func isID3v24TextFrame(name string) bool {
+	switch name {
+	case id3v2FrameTXXX, "TIPL", "TMCL":
+		return false
+	}
+	return strings.HasPrefix(name, "T")
+}
+
+// encodeText - text with encoding byte by Encoding
+func (id3v2 *ID3v24) encodeText(value string) ([]byte, error) {
+	return EncodeString(value, chooseEncoding(value, id3v2.Encoding, true))
+}
+
 func (id3v2 *ID3v24) SetString(name string, value string) error {
+	text, err := id3v2.encodeText(value)
+	if err != nil {
+		return err
+	}
 	frame := ID3v24Frame{
 		Key:   name,
-		Value: SetString(value),
+		Value: text,
 	}
 
 	for i := range id3v2.Frames {
@@ -673,16 +1484,46 @@ func (id3v2 *ID3v24) SetString(name string, value string) error {
 	return nil
 }
 
+func (id3v2 *ID3v24) GetBytes(name string) ([]byte, error) {
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == name {
+			return id3v2.Frames[i].Value, nil
+		}
+	}
+	return nil, ErrTagNotFound
+}
+
+func (id3v2 *ID3v24) SetBytes(name string, value []byte) error {
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == name {
+			id3v2.Frames[i].Value = value
+			return nil
+		}
+	}
+
+	id3v2.Frames = append(id3v2.Frames, ID3v24Frame{
+		Key:   name,
+		Value: value,
+	})
+	return nil
+}
+
+// GetTimestamp - read timestamp frame.
+// Timestamp precision may be reduced: yyyy, yyyy-MM, yyyy-MM-dd,
+// yyyy-MM-ddTHH, yyyy-MM-ddTHH:mm and yyyy-MM-ddTHH:mm:ss.
 func (id3v2 *ID3v24) GetTimestamp(name string) (time.Time, error) {
 	str, err := id3v2.GetString(name)
 	if err != nil {
 		return time.Now(), err
 	}
-	result, err := time.Parse("2006-01-02T15:04:05", str)
-	if err != nil {
-		return time.Now(), err
+	for _, layout := range id3v24TimestampLayouts {
+		result, errParse := time.Parse(layout, str)
+		if errParse == nil {
+			return result, nil
+		}
 	}
-	return result, nil
+	_, err = time.Parse(id3v24TimestampLayouts[0], str)
+	return time.Now(), err
 }
 
 func (id3v2 *ID3v24) SetTimestamp(name string, value time.Time) error {
@@ -703,55 +1544,82 @@ func (id3v2 *ID3v24) SetInt(name string, value int) error {
 }
 
 func (id3v2 *ID3v24) GetAttachedPicture() (*AttachedPicture, error) {
-	var picture AttachedPicture
-
-	picStr, err := id3v2.GetString("APIC")
+	value, err := id3v2.GetBytes("APIC")
 	if err != nil {
 		return nil, err
 	}
-	values := strings.SplitN(picStr, "\x00", 3)
-	if len(values) != 3 {
-		return nil, ErrIncorrectTag
-	}
-
-	// MIME
-	picture.MIME = values[0]
-
-	// Type
-	if len(values[1]) == 0 {
-		return nil, ErrIncorrectTag
-	}
-	picture.PictureType = values[1][0]
-
-	// Description
-	picture.Description = values[1][1:]
-
-	// Image data
-	picture.Data = []byte(values[2])
-
-	return &picture, nil
+	return parseAttachedPicture(value)
 }
 
 // nolint:gocritic
 func (id3v2 *ID3v24) SetAttachedPicture(picture *AttachedPicture) error {
-	// set UTF-8
-	result := []byte{0}
+	value, err := id3v2.encodePicture(picture)
+	if err != nil {
+		return err
+	}
+	return id3v2.SetBytes("APIC", value)
+}
 
-	// MIME type
-	result = append(result, []byte(picture.MIME)...)
-	result = append(result, 0x00)
+// encodePicture - APIC frame with description in Encoding
+func (id3v2 *ID3v24) encodePicture(picture *AttachedPicture) ([]byte, error) {
+	description, err := id3v2.encodeText(picture.Description)
+	if err != nil {
+		return nil, err
+	}
+	return encodeAttachedPicture(picture, description), nil
+}
 
-	// Picture type
-	result = append(result, picture.PictureType)
+// Pictures - pictures of all APIC frames, malformed frames are skipped
+func (id3v2 *ID3v24) Pictures() []AttachedPicture {
+	var result []AttachedPicture
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key != "APIC" {
+			continue
+		}
+		picture, err := parseAttachedPicture(id3v2.Frames[i].Value)
+		if err == nil {
+			result = append(result, *picture)
+		}
+	}
+	return result
+}
 
-	// Picture description
-	result = append(result, []byte(picture.Description)...)
-	result = append(result, 0x00)
+// AddPicture - add APIC frame. Pictures of the same type must have different descriptions
+func (id3v2 *ID3v24) AddPicture(picture AttachedPicture) error {
+	value, err := id3v2.encodePicture(&picture)
+	if err != nil {
+		return err
+	}
+	id3v2.Frames = append(id3v2.Frames, ID3v24Frame{
+		Key:   "APIC",
+		Value: value,
+	})
+	return nil
+}
 
-	// Picture data
-	result = append(result, picture.Data...)
+// RemovePicture - delete APIC frames of the picture type
+func (id3v2 *ID3v24) RemovePicture(pictureType byte) error {
+	frames := id3v2.Frames[:0]
+	for i := range id3v2.Frames {
+		if id3v2.Frames[i].Key == "APIC" {
+			picture, err := parseAttachedPicture(id3v2.Frames[i].Value)
+			if err == nil && picture.PictureType == pictureType {
+				continue
+			}
+		}
+		frames = append(frames, id3v2.Frames[i])
+	}
+	id3v2.Frames = frames
+	return nil
+}
 
-	return id3v2.SetString("APIC", string(result))
+// ReplacePicture - replace APIC frames of the picture type with the picture
+func (id3v2 *ID3v24) ReplacePicture(picture AttachedPicture) error {
+	err := id3v2.RemovePicture(picture.PictureType)
+	if err != nil {
+		return err
+	}
+	return id3v2.AddPicture(picture)
 }
 
 func (id3v2 *ID3v24) DeleteTag(name string) error {
@@ -822,9 +1690,13 @@ func (id3v2 *ID3v24) GetStringTXXX(name string) (string, error) {
 }
 
 func (id3v2 *ID3v24) SetStringTXXX(name string, value string) error {
+	text, err := id3v2.encodeText(name + "\x00" + value)
+	if err != nil {
+		return err
+	}
 	result := ID3v24Frame{
 		Key:   id3v2FrameTXXX,
-		Value: SetString(name + "\x00" + value),
+		Value: text,
 	}
 
 	// find tag
@@ -858,3 +1730,103 @@ func (id3v2 *ID3v24) GetIntTXXX(name string) (int, error) {
 	}
 	return strconv.Atoi(str)
 }
+
+// parseComment - read COMM frame
+// Text encoding           $xx
+// Language                $xx xx xx
+// Short content descrip.  <text string according to encoding> $00 (00)
+// The actual text         <full text string according to encoding>.
+func parseComment(value []byte) (*Comment, error) {
+	if len(value) < 4 {
+		return nil, ErrIncorrectLength
+	}
+	encoding := GetEncoding(value[0])
+	values := SplitBytesWithTextDescription(value[4:], encoding)
+	if len(values) != 2 {
+		return nil, ErrIncorrectTag
+	}
+
+	description, err := DecodeString(values[0], encoding)
+	if err != nil {
+		return nil, err
+	}
+	text, err := DecodeString(values[1], encoding)
+	if err != nil {
+		return nil, err
+	}
+	return &Comment{
+		Language:    string(value[1:4]),
+		Description: description,
+		Text:        strings.TrimRight(text, "\x00"),
+	}, nil
+}
+
+// encodeComment - COMM frame, text is description and text separated by $00 with encoding byte
+func encodeComment(language string, text []byte) []byte {
+	result := append([]byte{text[0]}, language...)
+	return append(result, text[1:]...)
+}
+
+// commentLanguage - language of COMM frame, XXX if unknown
+func commentLanguage(language string) (string, error) {
+	switch len(language) {
+	case 0:
+		return "XXX", nil
+	case 3:
+		return language, nil
+	}
+	return "", ErrIncorrectLength
+}
+
+// encodeAttachedPicture - APIC frame, description is text with encoding byte
+// Text encoding      $xx
+// MIME type          <text string> $00
+// Picture type       $xx
+// Description        <text string according to encoding> $00 (00)
+// Picture data       <binary data>.
+func encodeAttachedPicture(picture *AttachedPicture, description []byte) []byte {
+	result := []byte{description[0]}
+	result = append(result, picture.MIME...)
+	result = append(result, 0x00, picture.PictureType)
+	result = append(result, description[1:]...)
+	result = append(result, textTerminator(description[0])...)
+	return append(result, picture.Data...)
+}
+
+// parseAttachedPicture - read APIC frame
+// Text encoding      $xx
+// MIME type          <text string> $00
+// Picture type       $xx
+// Description        <text string according to encoding> $00 (00)
+// Picture data       <binary data>.
+func parseAttachedPicture(value []byte) (*AttachedPicture, error) {
+	if len(value) < 2 {
+		return nil, ErrIncorrectLength
+	}
+	encoding := GetEncoding(value[0])
+
+	// MIME is always ISO-8859-1
+	parts := bytes.SplitN(value[1:], []byte{0}, 2)
+	if len(parts) != 2 || len(parts[1]) == 0 {
+		return nil, ErrIncorrectTag
+	}
+
+	picture := AttachedPicture{
+		MIME:        string(parts[0]),
+		PictureType: parts[1][0],
+	}
+
+	values := SplitBytesWithTextDescription(parts[1][1:], encoding)
+	if len(values) != 2 {
+		return nil, ErrIncorrectTag
+	}
+
+	description, err := DecodeString(values[0], encoding)
+	if err != nil {
+		return nil, err
+	}
+	picture.Description = description
+	picture.Data = values[1]
+
+	return &picture, nil
+}
@@ -0,0 +1,213 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// IFFChunk - chunk of RIFF (little endian) or AIFF (big endian) file, data without pad byte.
+// Data of sound data chunk is nil, it is read from the source on demand
+type IFFChunk struct {
+	ID     string
+	Data   []byte
+	region *fileRegion
+}
+
+// Reader - chunk data
+func (chunk *IFFChunk) Reader() *io.SectionReader {
+	return audioSection(chunk.Data, chunk.region)
+}
+
+// Write - chunk header, data and pad byte for word alignment
+func (chunk *IFFChunk) Write(w io.Writer, order binary.ByteOrder) error {
+	data := chunk.Reader()
+	header := make([]byte, 8)
+	copy(header[0:4], chunk.ID)
+	order.PutUint32(header[4:8], uint32(data.Size()))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if err := writeAudio(w, chunk.Data, chunk.region); err != nil {
+		return err
+	}
+	if data.Size()%2 == 1 {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readIFFChunks - chunks are word aligned, pad byte is not included in chunk size
+func readIFFChunks(data []byte, order binary.ByteOrder) []*IFFChunk {
+	var chunks []*IFFChunk
+	for len(data) >= 8 {
+		size := int(order.Uint32(data[4:8]))
+		if size > len(data)-8 {
+			// truncated file
+			size = len(data) - 8
+		}
+
+		chunks = append(chunks, &IFFChunk{
+			ID:   string(data[0:4]),
+			Data: data[8 : 8+size],
+		})
+
+		size += size % 2
+		if size > len(data)-8 {
+			break
+		}
+		data = data[8+size:]
+	}
+	return chunks
+}
+
+// readIFFFileChunks - chunks of the form after its header, data of audio chunk is read from the source on demand.
+// Data after the form, e.g. ID3v1 tag, is returned as region
+func readIFFFileChunks(input io.ReadSeeker, order binary.ByteOrder, audio string) ([]*IFFChunk, *fileRegion, error) {
+	size, err := input.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, nil, err
+	}
+	header, err := seekAndRead(input, 0, io.SeekStart, 12)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// form size includes form type
+	end := 8 + int64(order.Uint32(header[4:8]))
+	if end%2 == 1 && end < size {
+		// pad byte of the last chunk isn't counted in form size
+		end++
+	}
+	if end > size {
+		// truncated file
+		end = size
+	}
+
+	var trailer *fileRegion
+	if end < size {
+		trailer, err = readRegion(input, end, size-end)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	offset := int64(12)
+	var chunks []*IFFChunk
+	for end-offset >= 8 {
+		header, err := seekAndRead(input, offset, io.SeekStart, 8)
+		if err != nil {
+			return nil, nil, err
+		}
+		size := int64(order.Uint32(header[4:8]))
+		if size > end-offset-8 {
+			// truncated file
+			size = end - offset - 8
+		}
+
+		chunk := &IFFChunk{ID: string(header[0:4]), Data: []byte{}}
+		switch {
+		case chunk.ID == audio:
+			chunk.Data = nil
+			chunk.region, err = readRegion(input, offset+8, size)
+		case size > 0:
+			chunk.Data, err = seekAndRead(input, offset+8, io.SeekStart, int(size))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		chunks = append(chunks, chunk)
+
+		size += size % 2
+		if size > end-offset-8 {
+			break
+		}
+		offset += 8 + size
+	}
+	return chunks, trailer, nil
+}
+
+// findIFFChunk - first chunk with id, nil if there is no such chunk
+func findIFFChunk(chunks []*IFFChunk, id string) *IFFChunk {
+	for _, chunk := range chunks {
+		if chunk.ID == id {
+			return chunk
+		}
+	}
+	return nil
+}
+
+// iffRegions - chunk data read from the source on demand
+func iffRegions(chunks []*IFFChunk) []*fileRegion {
+	regions := make([]*fileRegion, 0, len(chunks))
+	for _, chunk := range chunks {
+		regions = append(regions, chunk.region)
+	}
+	return regions
+}
+
+// writeIFF - file header with size of form type and all chunks, then chunks
+func writeIFF(w io.Writer, order binary.ByteOrder, identifier string, formType string, chunks []*IFFChunk) error {
+	size := 4
+	for _, chunk := range chunks {
+		data := chunk.Reader()
+		size += 8 + int(data.Size()+data.Size()%2)
+	}
+
+	header := make([]byte, 12)
+	copy(header[0:4], identifier)
+	order.PutUint32(header[4:8], uint32(size))
+	copy(header[8:12], formType)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, chunk := range chunks {
+		if err := chunk.Write(w, order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readIFFID3 - ID3v2.2, ID3v2.3 or ID3v2.4 tag from 'id3 ' chunk, nil for other versions
+func readIFFID3(data []byte) (Metadata, error) {
+	reader := bytes.NewReader(data)
+	switch {
+	case checkID3v24(reader):
+		id3, err := ReadID3v24(reader)
+		if err != nil {
+			return nil, err
+		}
+		return id3, nil
+	case checkID3v23(reader):
+		id3, err := ReadID3v23(reader)
+		if err != nil {
+			return nil, err
+		}
+		return id3, nil
+	case checkID3v22(reader):
+		id3, err := ReadID3v22(reader)
+		if err != nil {
+			return nil, err
+		}
+		return id3, nil
+	}
+	return nil, nil
+}
+
+// isIFFID3 - chunk with ID3v2 tag handled by readIFFID3, other chunks are saved as is
+func isIFFID3(chunk *IFFChunk) bool {
+	if !strings.EqualFold(chunk.ID, wavID3Chunk) {
+		return false
+	}
+	reader := bytes.NewReader(chunk.Data)
+	if checkID3v22(reader) {
+		// compressed ID3v2.2 tag should be ignored
+		return len(chunk.Data) >= 10 && !id3v22Flags(chunk.Data[5]).IsCompression()
+	}
+	return checkID3v24(reader) || checkID3v23(reader)
+}
@@ -0,0 +1,128 @@
+package tag
+
+import (
+	"io"
+	"os"
+)
+
+// saveInPlace - write tag of size bytes at the start of file, before audio.
+// If audio is read from the file and tag fits into the space before it (exactly or
+// with at least minPadding bytes left), only this space is overwritten. Otherwise audio
+// is moved or copied after tag with padding. build returns tag of given length with padding
+func saveInPlace(file *os.File, data []byte, region *fileRegion, size int64, minPadding int64, padding int64,
+	build func(length int64) ([]byte, error)) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	// audio offset in file
+	offset := int64(-1)
+	if data == nil && region != nil && region.sameFile(info) {
+		offset = region.offset
+	}
+
+	length := offset
+	if offset < size || (offset > size && offset < size+minPadding) {
+		length = size + padding
+	}
+	content, err := build(length)
+	if err != nil {
+		return 0, err
+	}
+
+	if length != offset {
+		audio := audioSection(data, region)
+		if offset >= 0 {
+			err = moveFileData(file, offset, length, region.size)
+			if err != nil {
+				return 0, err
+			}
+			region.offset = length
+		} else {
+			_, err = file.Seek(length, io.SeekStart)
+			if err != nil {
+				return 0, err
+			}
+			err = writeSection(file, audio)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		err = file.Truncate(length + audio.Size())
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = file.WriteAt(content, 0)
+	if err != nil {
+		return 0, err
+	}
+	err = inPlaceWritten(file, data, region)
+	if err != nil {
+		return 0, err
+	}
+	return length, nil
+}
+
+// inPlaceWritten - region read by ReadFile from the file written in place
+// is read from its new state, it isn't reported as changed
+func inPlaceWritten(file *os.File, data []byte, region *fileRegion) error {
+	if data != nil || region == nil {
+		return nil
+	}
+	source, ok := region.source.(*fileSource)
+	if !ok {
+		return nil
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if !region.sameFile(info) {
+		return nil
+	}
+	return source.update()
+}
+
+// moveFileData - move size bytes of file from offset to new offset.
+// Data is copied from the end if it moves forward
+func moveFileData(file *os.File, from int64, to int64, size int64) error {
+	buffer := make([]byte, audioCopyBufferSize)
+	for done := int64(0); done < size; {
+		n := size - done
+		if n > int64(len(buffer)) {
+			n = int64(len(buffer))
+		}
+
+		// position of the next block
+		position := done
+		if to > from {
+			position = size - done - n
+		}
+
+		_, err := file.ReadAt(buffer[:n], from+position)
+		if err != nil {
+			return err
+		}
+		_, err = file.WriteAt(buffer[:n], to+position)
+		if err != nil {
+			return err
+		}
+		done += n
+	}
+	return nil
+}
+
+// inPlacePadding - padding of tag written by SaveInPlace if it doesn't fit into the file
+func inPlacePadding(padding int) int64 {
+	switch {
+	case padding == 0:
+		return DefaultPadding
+	case padding < 0:
+		return 0
+	}
+	return int64(padding)
+}
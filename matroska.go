@@ -0,0 +1,1507 @@
+package tag
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// matroskaDateLayouts - DATE_RELEASED precisions, fractional seconds are accepted by the first one
+var matroskaDateLayouts = []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02", "2006-01", "2006"}
+
+// MatroskaSimpleTag - SimpleTag element, nested tags are kept as children
+type MatroskaSimpleTag struct {
+	Name     string
+	Language string // empty for default "und"
+	Default  bool
+	String   string
+	Binary   []byte
+	Children []*MatroskaSimpleTag
+	other    [][]byte // unknown elements written back as is
+}
+
+// MatroskaTag - Tag element. Targets with track, edition, chapter or attachment
+// UIDs limit the tag to a part of the segment, such tags are kept as is
+type MatroskaTag struct {
+	TargetTypeValue int
+	TargetType      string
+	SimpleTags      []*MatroskaSimpleTag
+	targets         [][]byte // UID elements of Targets
+}
+
+// MatroskaAttachment - AttachedFile element
+type MatroskaAttachment struct {
+	Name        string
+	MediaType   string
+	Description string
+	Data        []byte
+	UID         uint64
+	other       [][]byte
+}
+
+// Matroska - Tags and Attachments elements of Matroska and WebM segment.
+// Metadata methods use tags for the whole segment: track level (TargetTypeValue 30)
+// and album level (TargetTypeValue 50) values, picture is the cover attachment
+type Matroska struct {
+	DocType     string // matroska or webm
+	Tags        []*MatroskaTag
+	Attachments []*MatroskaAttachment
+	file        *matroskaFile     // file with clusters read from the source on demand
+	saved       map[uint32][]byte // elements payload written in file
+}
+
+func (mkv *Matroska) GetAllTagNames() []string {
+	var result []string
+	for _, mkvTag := range mkv.Tags {
+		if !mkvTag.Global() {
+			continue
+		}
+		for _, simple := range mkvTag.SimpleTags {
+			result = append(result, simple.Name)
+		}
+	}
+	return result
+}
+
+func (mkv *Matroska) GetVersion() Version {
+	return VersionMatroska
+}
+
+// GetFileData - cluster elements
+func (mkv *Matroska) GetFileData() []byte {
+	data, err := ioutil.ReadAll(mkv.AudioReader())
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// AudioReader - cluster elements read from the source
+func (mkv *Matroska) AudioReader() io.Reader {
+	_, children, err := readMatroskaSegment(mkv.file)
+	if err != nil {
+		return bytes.NewReader(nil)
+	}
+
+	var readers []io.Reader
+	for _, child := range children {
+		if child.ID != matroskaCluster {
+			continue
+		}
+		if child.Size < 0 {
+			readers = append(readers, mkv.file.reader(child.Offset, mkv.file.size()))
+			break
+		}
+		readers = append(readers, mkv.file.reader(child.Offset, child.end()))
+	}
+	return io.MultiReader(readers...)
+}
+
+// GetTitle - track title, album or movie title is GetAlbum
+func (mkv *Matroska) GetTitle() (string, error) {
+	return mkv.GetSimpleTag(MatroskaTargetTrack, "TITLE")
+}
+
+func (mkv *Matroska) GetArtist() (string, error) {
+	return mkv.GetSimpleTag(MatroskaTargetTrack, "ARTIST")
+}
+
+func (mkv *Matroska) GetAlbum() (string, error) {
+	return mkv.GetSimpleTag(MatroskaTargetAlbum, "TITLE")
+}
+
+func (mkv *Matroska) GetYear() (int, error) {
+	date, err := mkv.GetDate()
+	if err != nil {
+		return 0, err
+	}
+	return date.Year(), nil
+}
+
+func (mkv *Matroska) GetComment() (string, error) {
+	return mkv.getSimpleTag("COMMENT", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) GetGenre() (string, error) {
+	return mkv.getSimpleTag("GENRE", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) GetAlbumArtist() (string, error) {
+	return mkv.GetSimpleTag(MatroskaTargetAlbum, "ARTIST")
+}
+
+func (mkv *Matroska) GetDate() (time.Time, error) {
+	value, err := mkv.getSimpleTag("DATE_RELEASED", MatroskaTargetAlbum, MatroskaTargetTrack)
+	if err != nil {
+		return time.Now(), err
+	}
+	for _, layout := range matroskaDateLayouts {
+		result, err := time.Parse(layout, value)
+		if err == nil {
+			return result, nil
+		}
+	}
+	return time.Now(), ErrIncorrectTag
+}
+
+func (mkv *Matroska) GetArranger() (string, error) {
+	return mkv.getSimpleTag("ARRANGER", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) GetAuthor() (string, error) {
+	return mkv.getSimpleTag("LYRICIST", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) GetBPM() (int, error) {
+	return mkv.getSimpleTagInt("BPM", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) GetCatalogNumber() (string, error) {
+	return mkv.getSimpleTag("CATALOG_NUMBER", MatroskaTargetAlbum, MatroskaTargetTrack)
+}
+
+func (mkv *Matroska) GetCompilation() (string, error) {
+	return mkv.getSimpleTag("COMPILATION", MatroskaTargetAlbum, MatroskaTargetTrack)
+}
+
+func (mkv *Matroska) GetComposer() (string, error) {
+	return mkv.getSimpleTag("COMPOSER", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) GetConductor() (string, error) {
+	return mkv.getSimpleTag("CONDUCTOR", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) GetCopyright() (string, error) {
+	return mkv.getSimpleTag("COPYRIGHT", MatroskaTargetAlbum, MatroskaTargetTrack)
+}
+
+func (mkv *Matroska) GetDescription() (string, error) {
+	return mkv.getSimpleTag("DESCRIPTION", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+// GetDiscNumber - album part number and parts of volume, total is 0 if not set
+func (mkv *Matroska) GetDiscNumber() (int, int, error) {
+	return mkv.getPartNumber(MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) GetEncodedBy() (string, error) {
+	return mkv.getSimpleTag("ENCODED_BY", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+// GetTrackNumber - track part number and parts of album, total is 0 if not set
+func (mkv *Matroska) GetTrackNumber() (int, int, error) {
+	return mkv.getPartNumber(MatroskaTargetTrack)
+}
+
+func (mkv *Matroska) GetPicture() (image.Image, error) {
+	index := mkv.coverAttachment()
+	if index < 0 {
+		return nil, ErrTagNotFound
+	}
+
+	attachment := mkv.Attachments[index]
+	switch attachment.MediaType {
+	case mimeImageJPEG:
+		return jpeg.Decode(bytes.NewReader(attachment.Data))
+	case mimeImagePNG:
+		return png.Decode(bytes.NewReader(attachment.Data))
+	}
+
+	return nil, ErrIncorrectTag
+}
+
+// GetLyrics - LYRICS simple tag, language of simple tag isn't checked
+func (mkv *Matroska) GetLyrics(language string) (string, error) {
+	return mkv.getSimpleTag("LYRICS", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) SetTitle(title string) error {
+	return mkv.SetSimpleTag(MatroskaTargetTrack, "TITLE", title)
+}
+
+func (mkv *Matroska) SetArtist(artist string) error {
+	return mkv.SetSimpleTag(MatroskaTargetTrack, "ARTIST", artist)
+}
+
+func (mkv *Matroska) SetAlbum(album string) error {
+	return mkv.SetSimpleTag(MatroskaTargetAlbum, "TITLE", album)
+}
+
+// SetYear - DATE_RELEASED with year precision
+func (mkv *Matroska) SetYear(year int) error {
+	return mkv.SetSimpleTag(MatroskaTargetAlbum, "DATE_RELEASED", strconv.Itoa(year))
+}
+
+func (mkv *Matroska) SetComment(comment string) error {
+	return mkv.SetSimpleTag(MatroskaTargetTrack, "COMMENT", comment)
+}
+
+func (mkv *Matroska) SetGenre(genre string) error {
+	return mkv.SetSimpleTag(MatroskaTargetTrack, "GENRE", genre)
+}
+
+func (mkv *Matroska) SetAlbumArtist(albumArtist string) error {
+	return mkv.SetSimpleTag(MatroskaTargetAlbum, "ARTIST", albumArtist)
+}
+
+func (mkv *Matroska) SetDate(date time.Time) error {
+	return mkv.SetSimpleTag(MatroskaTargetAlbum, "DATE_RELEASED", date.Format("2006-01-02 15:04:05"))
+}
+
+func (mkv *Matroska) SetArranger(arranger string) error {
+	return mkv.SetSimpleTag(MatroskaTargetTrack, "ARRANGER", arranger)
+}
+
+func (mkv *Matroska) SetAuthor(author string) error {
+	return mkv.SetSimpleTag(MatroskaTargetTrack, "LYRICIST", author)
+}
+
+func (mkv *Matroska) SetBPM(bmp int) error {
+	return mkv.SetSimpleTag(MatroskaTargetTrack, "BPM", strconv.Itoa(bmp))
+}
+
+func (mkv *Matroska) SetCatalogNumber(catalogNumber string) error {
+	return mkv.SetSimpleTag(MatroskaTargetAlbum, "CATALOG_NUMBER", catalogNumber)
+}
+
+func (mkv *Matroska) SetCompilation(compilation string) error {
+	return mkv.SetSimpleTag(MatroskaTargetAlbum, "COMPILATION", compilation)
+}
+
+func (mkv *Matroska) SetComposer(composer string) error {
+	return mkv.SetSimpleTag(MatroskaTargetTrack, "COMPOSER", composer)
+}
+
+func (mkv *Matroska) SetConductor(conductor string) error {
+	return mkv.SetSimpleTag(MatroskaTargetTrack, "CONDUCTOR", conductor)
+}
+
+func (mkv *Matroska) SetCopyright(copyright string) error {
+	return mkv.SetSimpleTag(MatroskaTargetAlbum, "COPYRIGHT", copyright)
+}
+
+func (mkv *Matroska) SetDescription(description string) error {
+	return mkv.SetSimpleTag(MatroskaTargetTrack, "DESCRIPTION", description)
+}
+
+func (mkv *Matroska) SetDiscNumber(number int, total int) error {
+	return mkv.setPartNumber(MatroskaTargetAlbum, number, total)
+}
+
+func (mkv *Matroska) SetEncodedBy(encodedBy string) error {
+	return mkv.SetSimpleTag(MatroskaTargetTrack, "ENCODED_BY", encodedBy)
+}
+
+func (mkv *Matroska) SetTrackNumber(number int, total int) error {
+	return mkv.setPartNumber(MatroskaTargetTrack, number, total)
+}
+
+// SetPicture - replace cover attachment with cover.png
+func (mkv *Matroska) SetPicture(picture image.Image) error {
+	// Only PNG
+	buf := new(bytes.Buffer)
+	err := png.Encode(buf, picture)
+	if err != nil {
+		return err
+	}
+
+	index := mkv.coverAttachment()
+	if index < 0 {
+		var uid uint64
+		for _, attachment := range mkv.Attachments {
+			if attachment.UID > uid {
+				uid = attachment.UID
+			}
+		}
+		mkv.Attachments = append(mkv.Attachments, &MatroskaAttachment{UID: uid + 1})
+		index = len(mkv.Attachments) - 1
+	}
+
+	attachment := mkv.Attachments[index]
+	attachment.Name = "cover.png"
+	attachment.MediaType = mimeImagePNG
+	attachment.Data = buf.Bytes()
+	return nil
+}
+
+func (mkv *Matroska) SetLyrics(language string, lyrics string) error {
+	return mkv.SetSimpleTag(MatroskaTargetTrack, "LYRICS", lyrics)
+}
+
+// DeleteAll - delete all tags and cover attachment, other attachments are kept
+func (mkv *Matroska) DeleteAll() error {
+	mkv.Tags = nil
+	return mkv.DeletePicture()
+}
+
+func (mkv *Matroska) DeleteTitle() error {
+	return mkv.DeleteSimpleTag(MatroskaTargetTrack, "TITLE")
+}
+
+func (mkv *Matroska) DeleteArtist() error {
+	return mkv.DeleteSimpleTag(MatroskaTargetTrack, "ARTIST")
+}
+
+func (mkv *Matroska) DeleteAlbum() error {
+	return mkv.DeleteSimpleTag(MatroskaTargetAlbum, "TITLE")
+}
+
+func (mkv *Matroska) DeleteYear() error {
+	return mkv.deleteSimpleTag("DATE_RELEASED", MatroskaTargetAlbum, MatroskaTargetTrack)
+}
+
+func (mkv *Matroska) DeleteComment() error {
+	return mkv.deleteSimpleTag("COMMENT", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) DeleteGenre() error {
+	return mkv.deleteSimpleTag("GENRE", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) DeleteAlbumArtist() error {
+	return mkv.DeleteSimpleTag(MatroskaTargetAlbum, "ARTIST")
+}
+
+func (mkv *Matroska) DeleteDate() error {
+	return mkv.deleteSimpleTag("DATE_RELEASED", MatroskaTargetAlbum, MatroskaTargetTrack)
+}
+
+func (mkv *Matroska) DeleteArranger() error {
+	return mkv.deleteSimpleTag("ARRANGER", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) DeleteAuthor() error {
+	return mkv.deleteSimpleTag("LYRICIST", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) DeleteBPM() error {
+	return mkv.deleteSimpleTag("BPM", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) DeleteCatalogNumber() error {
+	return mkv.deleteSimpleTag("CATALOG_NUMBER", MatroskaTargetAlbum, MatroskaTargetTrack)
+}
+
+func (mkv *Matroska) DeleteCompilation() error {
+	return mkv.deleteSimpleTag("COMPILATION", MatroskaTargetAlbum, MatroskaTargetTrack)
+}
+
+func (mkv *Matroska) DeleteComposer() error {
+	return mkv.deleteSimpleTag("COMPOSER", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) DeleteConductor() error {
+	return mkv.deleteSimpleTag("CONDUCTOR", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) DeleteCopyright() error {
+	return mkv.deleteSimpleTag("COPYRIGHT", MatroskaTargetAlbum, MatroskaTargetTrack)
+}
+
+func (mkv *Matroska) DeleteDescription() error {
+	return mkv.deleteSimpleTag("DESCRIPTION", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) DeleteDiscNumber() error {
+	err := mkv.DeleteSimpleTag(MatroskaTargetAlbum, "PART_NUMBER")
+	if err != nil {
+		return err
+	}
+	return mkv.DeleteSimpleTag(MatroskaTargetVolume, "TOTAL_PARTS")
+}
+
+func (mkv *Matroska) DeleteEncodedBy() error {
+	return mkv.deleteSimpleTag("ENCODED_BY", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) DeleteTrackNumber() error {
+	err := mkv.DeleteSimpleTag(MatroskaTargetTrack, "PART_NUMBER")
+	if err != nil {
+		return err
+	}
+	return mkv.DeleteSimpleTag(MatroskaTargetAlbum, "TOTAL_PARTS")
+}
+
+func (mkv *Matroska) DeletePicture() error {
+	index := mkv.coverAttachment()
+	if index >= 0 {
+		mkv.Attachments = append(mkv.Attachments[:index], mkv.Attachments[index+1:]...)
+	}
+	return nil
+}
+
+func (mkv *Matroska) DeleteLyrics(language string) error {
+	return mkv.deleteSimpleTag("LYRICS", MatroskaTargetTrack, MatroskaTargetAlbum)
+}
+
+func (mkv *Matroska) SaveFile(path string) error {
+	return saveFile(path, mkv.Save, SaveOptions{})
+}
+
+// Save - write file with rebuilt Tags and Attachments elements. Changed element is
+// written in place if it fits into its old space with following Void elements,
+// else the old space becomes Void and the element goes to a free Void or the end
+// of segment. SeekHead is updated for the moved element
+func (mkv *Matroska) Save(input io.WriteSeeker) error {
+	elements := map[uint32][]byte{
+		matroskaTags:        mkv.serializeTags(),
+		matroskaAttachments: mkv.serializeAttachments(),
+	}
+
+	file := mkv.file.clone()
+	for _, id := range []uint32{matroskaTags, matroskaAttachments} {
+		if bytes.Equal(elements[id], mkv.saved[id]) {
+			continue
+		}
+		err := replaceMatroskaElement(file, id, elements[id])
+		if err != nil {
+			return err
+		}
+	}
+
+	err := file.write(input)
+	if err != nil {
+		return err
+	}
+	mkv.file = file
+	mkv.saved = elements
+	return nil
+}
+
+func checkMatroska(input io.ReadSeeker) bool {
+	_, err := input.Seek(0, io.SeekStart)
+	if err != nil {
+		return false
+	}
+	// EBML header is a few dozen bytes
+	header, err := ioutil.ReadAll(io.LimitReader(input, 1024))
+	if err != nil {
+		return false
+	}
+	docType, err := readMatroskaDocType(header)
+	return err == nil && (docType == matroskaDocTypeMatroska || docType == matroskaDocTypeWebM)
+}
+
+// ReadMatroska - top level elements of the segment are read into memory,
+// clusters are read from the input on save
+func ReadMatroska(input io.ReadSeeker) (*Matroska, error) {
+	file, err := readMatroskaFile(input)
+	if err != nil {
+		return nil, err
+	}
+
+	mkv := Matroska{
+		file: file,
+	}
+	mkv.DocType, err = readMatroskaDocType(file.memory(0))
+	if err != nil {
+		return nil, err
+	}
+
+	_, children, err := readMatroskaSegment(file)
+	if err != nil {
+		return nil, err
+	}
+	tagsFound, attachmentsFound := false, false
+	for _, child := range children {
+		switch {
+		case child.ID == matroskaTags && !tagsFound:
+			tagsFound = true
+			mkv.Tags, err = readMatroskaTags(child.Data)
+		case child.ID == matroskaAttachments && !attachmentsFound:
+			attachmentsFound = true
+			mkv.Attachments, err = readMatroskaAttachments(child.Data)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	mkv.saved = map[uint32][]byte{
+		matroskaTags:        mkv.serializeTags(),
+		matroskaAttachments: mkv.serializeAttachments(),
+	}
+	return &mkv, nil
+}
+
+// GetSimpleTag - value of the first SimpleTag with the name in tags for the whole segment
+func (mkv *Matroska) GetSimpleTag(targetTypeValue int, name string) (string, error) {
+	for _, mkvTag := range mkv.Tags {
+		if !mkvTag.Global() || mkvTag.TargetTypeValue != targetTypeValue {
+			continue
+		}
+		for _, simple := range mkvTag.SimpleTags {
+			if strings.EqualFold(simple.Name, name) {
+				return simple.String, nil
+			}
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+// SetSimpleTag - replace the first SimpleTag with the name or add it to the tag for the whole segment
+func (mkv *Matroska) SetSimpleTag(targetTypeValue int, name string, value string) error {
+	var target *MatroskaTag
+	for _, mkvTag := range mkv.Tags {
+		if !mkvTag.Global() || mkvTag.TargetTypeValue != targetTypeValue {
+			continue
+		}
+		for _, simple := range mkvTag.SimpleTags {
+			if strings.EqualFold(simple.Name, name) {
+				simple.String = value
+				simple.Binary = nil
+				return nil
+			}
+		}
+		if target == nil {
+			target = mkvTag
+		}
+	}
+
+	if target == nil {
+		target = &MatroskaTag{TargetTypeValue: targetTypeValue}
+		mkv.Tags = append(mkv.Tags, target)
+	}
+	target.SimpleTags = append(target.SimpleTags, &MatroskaSimpleTag{
+		Name:    name,
+		Default: true,
+		String:  value,
+	})
+	return nil
+}
+
+// DeleteSimpleTag - delete SimpleTags with the name from tags for the whole segment, empty tags are removed
+func (mkv *Matroska) DeleteSimpleTag(targetTypeValue int, name string) error {
+	tags := mkv.Tags[:0]
+	for _, mkvTag := range mkv.Tags {
+		if mkvTag.Global() && mkvTag.TargetTypeValue == targetTypeValue {
+			simpleTags := mkvTag.SimpleTags[:0]
+			for _, simple := range mkvTag.SimpleTags {
+				if !strings.EqualFold(simple.Name, name) {
+					simpleTags = append(simpleTags, simple)
+				}
+			}
+			mkvTag.SimpleTags = simpleTags
+		}
+		if len(mkvTag.SimpleTags) > 0 {
+			tags = append(tags, mkvTag)
+		}
+	}
+	mkv.Tags = tags
+	return nil
+}
+
+// getSimpleTag - value from the first target level which has it
+func (mkv *Matroska) getSimpleTag(name string, targetTypeValues ...int) (string, error) {
+	for _, targetTypeValue := range targetTypeValues {
+		value, err := mkv.GetSimpleTag(targetTypeValue, name)
+		if err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+func (mkv *Matroska) getSimpleTagInt(name string, targetTypeValues ...int) (int, error) {
+	value, err := mkv.getSimpleTag(name, targetTypeValues...)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(value)
+}
+
+func (mkv *Matroska) deleteSimpleTag(name string, targetTypeValues ...int) error {
+	for _, targetTypeValue := range targetTypeValues {
+		err := mkv.DeleteSimpleTag(targetTypeValue, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getPartNumber - PART_NUMBER of level and TOTAL_PARTS of the level above
+func (mkv *Matroska) getPartNumber(targetTypeValue int) (int, int, error) {
+	value, err := mkv.GetSimpleTag(targetTypeValue, "PART_NUMBER")
+	if err != nil {
+		return 0, 0, err
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	value, err = mkv.GetSimpleTag(targetTypeValue+20, "TOTAL_PARTS")
+	if err != nil {
+		return number, 0, nil
+	}
+	total, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, 0, err
+	}
+	return number, total, nil
+}
+
+func (mkv *Matroska) setPartNumber(targetTypeValue int, number int, total int) error {
+	err := mkv.SetSimpleTag(targetTypeValue, "PART_NUMBER", strconv.Itoa(number))
+	if err != nil {
+		return err
+	}
+	return mkv.SetSimpleTag(targetTypeValue+20, "TOTAL_PARTS", strconv.Itoa(total))
+}
+
+// coverAttachment - index of image attachment, file named cover has priority
+func (mkv *Matroska) coverAttachment() int {
+	result := -1
+	for i, attachment := range mkv.Attachments {
+		if !strings.HasPrefix(attachment.MediaType, "image/") {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(attachment.Name), "cover") {
+			return i
+		}
+		if result < 0 {
+			result = i
+		}
+	}
+	return result
+}
+
+// Global - tag is for the whole segment, targets have no UIDs or zero UIDs
+func (mkvTag *MatroskaTag) Global() bool {
+	for _, target := range mkvTag.targets {
+		element, err := readEBMLElement(target, 0)
+		if err != nil || ebmlUint(element.Data) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (mkv *Matroska) serializeTags() []byte {
+	buffer := new(bytes.Buffer)
+	for _, mkvTag := range mkv.Tags {
+		if len(mkvTag.SimpleTags) == 0 {
+			continue
+		}
+
+		targets := new(bytes.Buffer)
+		writeEBMLElement(targets, matroskaTargetValue, encodeEBMLUint(uint64(mkvTag.TargetTypeValue)))
+		if mkvTag.TargetType != "" {
+			writeEBMLElement(targets, matroskaTargetType, []byte(mkvTag.TargetType))
+		}
+		for _, target := range mkvTag.targets {
+			targets.Write(target)
+		}
+
+		payload := new(bytes.Buffer)
+		writeEBMLElement(payload, matroskaTargets, targets.Bytes())
+		for _, simple := range mkvTag.SimpleTags {
+			simple.write(payload)
+		}
+		writeEBMLElement(buffer, matroskaTag, payload.Bytes())
+	}
+
+	if buffer.Len() == 0 {
+		return nil
+	}
+	return buffer.Bytes()
+}
+
+func (simple *MatroskaSimpleTag) write(buffer *bytes.Buffer) {
+	payload := new(bytes.Buffer)
+	writeEBMLElement(payload, matroskaTagName, []byte(simple.Name))
+	if simple.Language != "" {
+		writeEBMLElement(payload, matroskaTagLanguage, []byte(simple.Language))
+	}
+	if !simple.Default {
+		writeEBMLElement(payload, matroskaTagDefault, []byte{0})
+	}
+	switch {
+	case simple.Binary != nil:
+		writeEBMLElement(payload, matroskaTagBinary, simple.Binary)
+	case simple.String != "" || len(simple.Children) == 0:
+		writeEBMLElement(payload, matroskaTagString, []byte(simple.String))
+	}
+	for _, other := range simple.other {
+		payload.Write(other)
+	}
+	for _, child := range simple.Children {
+		child.write(payload)
+	}
+	writeEBMLElement(buffer, matroskaSimpleTag, payload.Bytes())
+}
+
+func (mkv *Matroska) serializeAttachments() []byte {
+	buffer := new(bytes.Buffer)
+	for _, attachment := range mkv.Attachments {
+		payload := new(bytes.Buffer)
+		if attachment.Description != "" {
+			writeEBMLElement(payload, matroskaFileDesc, []byte(attachment.Description))
+		}
+		writeEBMLElement(payload, matroskaFileName, []byte(attachment.Name))
+		writeEBMLElement(payload, matroskaFileMediaType, []byte(attachment.MediaType))
+		writeEBMLElement(payload, matroskaFileData, attachment.Data)
+		writeEBMLElement(payload, matroskaFileUID, encodeEBMLUint(attachment.UID))
+		for _, other := range attachment.other {
+			payload.Write(other)
+		}
+		writeEBMLElement(buffer, matroskaAttachedFile, payload.Bytes())
+	}
+
+	if buffer.Len() == 0 {
+		return nil
+	}
+	return buffer.Bytes()
+}
+
+func readMatroskaDocType(data []byte) (string, error) {
+	header, err := readEBMLElement(data, 0)
+	if err != nil {
+		return "", err
+	}
+	if header.ID != matroskaEBML || header.Size < 0 {
+		return "", ErrFileMarker
+	}
+
+	children, err := readEBMLChildren(header.Data)
+	if err != nil {
+		return "", err
+	}
+	for _, child := range children {
+		if child.ID == matroskaDocType {
+			return ebmlString(child.Data), nil
+		}
+	}
+	return "", ErrFileMarker
+}
+
+// readMatroskaSegment - segment and its top level elements. Elements after an element
+// of unknown size are taken from SeekHead. Payload of clusters isn't read
+func readMatroskaSegment(file *matroskaFile) (*ebmlElement, []*ebmlElement, error) {
+	header, err := file.element(0, file.size())
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.ID != matroskaEBML || header.Size < 0 {
+		return nil, nil, ErrFileMarker
+	}
+
+	segment, err := file.element(header.end(), file.size())
+	if err != nil {
+		return nil, nil, err
+	}
+	if segment.ID != matroskaSegment {
+		return nil, nil, ErrFileMarker
+	}
+
+	start := segment.Offset + segment.HeaderSize
+	end := file.size()
+	if segment.Size >= 0 {
+		end = segment.end()
+	}
+
+	var children []*ebmlElement
+	for offset := start; offset < end; {
+		child, err := file.element(offset, end)
+		if err != nil {
+			return nil, nil, err
+		}
+		children = append(children, child)
+		if child.Size < 0 {
+			break
+		}
+		offset = child.end()
+	}
+
+	if len(children) == 0 || children[len(children)-1].Size >= 0 {
+		return segment, children, nil
+	}
+	last := children[len(children)-1].Offset
+	for _, child := range children {
+		if child.ID != matroskaSeekHead {
+			continue
+		}
+		seeks, err := readMatroskaSeeks(child.Data)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, position := range seeks {
+			if start+position <= last || start+position >= end {
+				continue
+			}
+			element, err := file.element(start+position, end)
+			if err != nil {
+				return nil, nil, err
+			}
+			children = append(children, element)
+		}
+	}
+	return segment, children, nil
+}
+
+// readMatroskaSeeks - SeekHead positions by element id
+func readMatroskaSeeks(data []byte) (map[uint32]int, error) {
+	seeks, err := readEBMLChildren(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[uint32]int{}
+	for _, seek := range seeks {
+		if seek.ID != matroskaSeek {
+			continue
+		}
+		id, position, err := readMatroskaSeek(seek.Data)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := result[id]; !ok {
+			result[id] = position
+		}
+	}
+	return result, nil
+}
+
+func readMatroskaSeek(data []byte) (uint32, int, error) {
+	children, err := readEBMLChildren(data)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var id uint32
+	position := -1
+	for _, child := range children {
+		switch child.ID {
+		case matroskaSeekID:
+			id = uint32(ebmlUint(child.Data))
+		case matroskaSeekPosition:
+			position = int(ebmlUint(child.Data))
+		}
+	}
+	if id == 0 || position < 0 {
+		return 0, 0, ErrIncorrectTag
+	}
+	return id, position, nil
+}
+
+func readMatroskaTags(data []byte) ([]*MatroskaTag, error) {
+	elements, err := readEBMLChildren(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*MatroskaTag
+	for _, element := range elements {
+		if element.ID != matroskaTag {
+			continue
+		}
+		children, err := readEBMLChildren(element.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		mkvTag := MatroskaTag{
+			TargetTypeValue: MatroskaTargetAlbum,
+		}
+		for _, child := range children {
+			switch child.ID {
+			case matroskaTargets:
+				err = mkvTag.readTargets(child.Data)
+			case matroskaSimpleTag:
+				var simple *MatroskaSimpleTag
+				simple, err = readMatroskaSimpleTag(child.Data)
+				mkvTag.SimpleTags = append(mkvTag.SimpleTags, simple)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, &mkvTag)
+	}
+	return result, nil
+}
+
+func (mkvTag *MatroskaTag) readTargets(data []byte) error {
+	targets, err := readEBMLChildren(data)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		switch target.ID {
+		case matroskaTargetValue:
+			mkvTag.TargetTypeValue = int(ebmlUint(target.Data))
+		case matroskaTargetType:
+			mkvTag.TargetType = ebmlString(target.Data)
+		case matroskaCRC32, matroskaVoid:
+		default:
+			mkvTag.targets = append(mkvTag.targets, data[target.Offset:target.end()])
+		}
+	}
+	return nil
+}
+
+func readMatroskaSimpleTag(data []byte) (*MatroskaSimpleTag, error) {
+	children, err := readEBMLChildren(data)
+	if err != nil {
+		return nil, err
+	}
+
+	simple := MatroskaSimpleTag{
+		Default: true,
+	}
+	for _, child := range children {
+		switch child.ID {
+		case matroskaTagName:
+			simple.Name = ebmlString(child.Data)
+		case matroskaTagLanguage:
+			simple.Language = ebmlString(child.Data)
+		case matroskaTagDefault:
+			simple.Default = ebmlUint(child.Data) != 0
+		case matroskaTagString:
+			simple.String = ebmlString(child.Data)
+		case matroskaTagBinary:
+			simple.Binary = child.Data
+		case matroskaSimpleTag:
+			nested, err := readMatroskaSimpleTag(child.Data)
+			if err != nil {
+				return nil, err
+			}
+			simple.Children = append(simple.Children, nested)
+		case matroskaCRC32, matroskaVoid:
+		default:
+			simple.other = append(simple.other, data[child.Offset:child.end()])
+		}
+	}
+	return &simple, nil
+}
+
+func readMatroskaAttachments(data []byte) ([]*MatroskaAttachment, error) {
+	elements, err := readEBMLChildren(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*MatroskaAttachment
+	for _, element := range elements {
+		if element.ID != matroskaAttachedFile {
+			continue
+		}
+		children, err := readEBMLChildren(element.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		var attachment MatroskaAttachment
+		for _, child := range children {
+			switch child.ID {
+			case matroskaFileDesc:
+				attachment.Description = ebmlString(child.Data)
+			case matroskaFileName:
+				attachment.Name = ebmlString(child.Data)
+			case matroskaFileMediaType:
+				attachment.MediaType = ebmlString(child.Data)
+			case matroskaFileData:
+				attachment.Data = child.Data
+			case matroskaFileUID:
+				attachment.UID = ebmlUint(child.Data)
+			case matroskaCRC32, matroskaVoid:
+			default:
+				attachment.other = append(attachment.other, element.Data[child.Offset:child.end()])
+			}
+		}
+		result = append(result, &attachment)
+	}
+	return result, nil
+}
+
+// replaceMatroskaElement - write top level element with the payload, nil payload removes it
+func replaceMatroskaElement(file *matroskaFile, id uint32, payload []byte) error {
+	segment, children, err := readMatroskaSegment(file)
+	if err != nil {
+		return err
+	}
+	segmentStart := segment.Offset + segment.HeaderSize
+
+	for i, child := range children {
+		if child.ID != id {
+			continue
+		}
+		start, end := matroskaRegion(children, i)
+		if payload != nil {
+			element, void, ok := fitEBMLElement(id, payload, end-start)
+			if ok {
+				return file.writeAt(append(element, void...), start)
+			}
+		}
+
+		// space of the old element is free
+		void, err := ebmlVoid(end - start)
+		if err != nil {
+			return err
+		}
+		err = file.writeAt(void, start)
+		if err != nil {
+			return err
+		}
+		break
+	}
+	if payload == nil {
+		return updateMatroskaSeekHead(file, id, -1)
+	}
+
+	// free Void, space after SeekHead is kept for its growth
+	for i, child := range children {
+		if child.ID != matroskaVoid || (i > 0 && children[i-1].end() == child.Offset &&
+			(children[i-1].ID == matroskaVoid || children[i-1].ID == matroskaSeekHead)) {
+			continue
+		}
+		start, end := matroskaRegion(children, i)
+		element, void, ok := fitEBMLElement(id, payload, end-start)
+		if ok {
+			err = file.writeAt(append(void, element...), start)
+			if err != nil {
+				return err
+			}
+			return updateMatroskaSeekHead(file, id, start+len(void)-segmentStart)
+		}
+	}
+
+	// end of segment with padding for next saves
+	element := new(bytes.Buffer)
+	writeEBMLElement(element, id, payload)
+	void, err := ebmlVoid(matroskaPadding)
+	if err != nil {
+		return err
+	}
+	element.Write(void)
+
+	end := file.size()
+	if segment.Size >= 0 {
+		end = segment.end()
+	}
+	file.insert(element.Bytes(), end)
+
+	if segment.Size >= 0 {
+		idLength := len(encodeEBMLID(matroskaSegment))
+		size, err := encodeEBMLSize(segment.Size+element.Len(), segment.HeaderSize-idLength)
+		if err != nil {
+			return err
+		}
+		err = file.writeAt(size, segment.Offset+idLength)
+		if err != nil {
+			return err
+		}
+	}
+	return updateMatroskaSeekHead(file, id, end-segmentStart)
+}
+
+// updateMatroskaSeekHead - point the first SeekHead to element position, negative position
+// removes the entry. SeekHead may grow into following Void, else the entry is removed
+func updateMatroskaSeekHead(file *matroskaFile, id uint32, position int) error {
+	_, children, err := readMatroskaSegment(file)
+	if err != nil {
+		return err
+	}
+
+	for i, child := range children {
+		if child.ID != matroskaSeekHead {
+			continue
+		}
+		seeks, err := readEBMLChildren(child.Data)
+		if err != nil {
+			return err
+		}
+
+		entry := new(bytes.Buffer)
+		if position >= 0 {
+			seek := new(bytes.Buffer)
+			writeEBMLElement(seek, matroskaSeekID, encodeEBMLID(id))
+			writeEBMLElement(seek, matroskaSeekPosition, encodeEBMLUint(uint64(position)))
+			writeEBMLElement(entry, matroskaSeek, seek.Bytes())
+		}
+
+		// CRC-32 is not valid after the change
+		withEntry, withoutEntry := new(bytes.Buffer), new(bytes.Buffer)
+		for _, seek := range seeks {
+			if seek.ID == matroskaCRC32 || seek.ID == matroskaVoid {
+				continue
+			}
+			if seek.ID == matroskaSeek {
+				seekID, _, err := readMatroskaSeek(seek.Data)
+				if err == nil && seekID == id {
+					continue
+				}
+			}
+			withEntry.Write(child.Data[seek.Offset:seek.end()])
+			withoutEntry.Write(child.Data[seek.Offset:seek.end()])
+		}
+		withEntry.Write(entry.Bytes())
+
+		start, end := matroskaRegion(children, i)
+		for _, payload := range [][]byte{withEntry.Bytes(), withoutEntry.Bytes()} {
+			element, void, ok := fitEBMLElement(matroskaSeekHead, payload, end-start)
+			if ok {
+				return file.writeAt(append(element, void...), start)
+			}
+		}
+		return ErrWriting
+	}
+	return nil
+}
+
+// matroskaRegion - space of the element with following Void elements
+func matroskaRegion(children []*ebmlElement, index int) (int, int) {
+	start, end := children[index].Offset, children[index].end()
+	for _, child := range children[index+1:] {
+		if child.ID != matroskaVoid || child.Offset != end || child.Size < 0 {
+			break
+		}
+		end = child.end()
+	}
+	return start, end
+}
+
+// matroskaFile - file as parts in memory and regions of the source. Element headers
+// and top level elements except clusters are in memory, so they can be changed in place
+type matroskaFile struct {
+	parts []*matroskaPart
+}
+
+// matroskaPart - data in memory, or region of the source if it's set
+type matroskaPart struct {
+	offset int
+	data   []byte
+	region *fileRegion
+}
+
+func (part *matroskaPart) size() int {
+	if part.region != nil {
+		return int(part.region.size)
+	}
+	return len(part.data)
+}
+
+// split - parts before and after position in part
+func (part *matroskaPart) split(position int) (*matroskaPart, *matroskaPart) {
+	if part.region != nil {
+		region := part.region
+		return &matroskaPart{offset: part.offset, region: &fileRegion{source: region.source,
+				offset: region.offset, size: int64(position)}},
+			&matroskaPart{offset: part.offset + position, region: &fileRegion{source: region.source,
+				offset: region.offset + int64(position), size: region.size - int64(position)}}
+	}
+	return &matroskaPart{offset: part.offset, data: part.data[:position:position]},
+		&matroskaPart{offset: part.offset + position, data: part.data[position:]}
+}
+
+// readMatroskaFile - EBML header, segment header and top level elements are read into memory,
+// payload of clusters and elements of unknown size stays in the input
+func readMatroskaFile(input io.ReadSeeker) (*matroskaFile, error) {
+	size, err := input.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := readMatroskaHeader(input, 0, int(size))
+	if err != nil {
+		return nil, err
+	}
+	if header.ID != matroskaEBML || header.Size < 0 {
+		return nil, ErrFileMarker
+	}
+	segment, err := readMatroskaHeader(input, header.end(), int(size))
+	if err != nil {
+		return nil, err
+	}
+	if segment.ID != matroskaSegment {
+		return nil, ErrFileMarker
+	}
+
+	start := segment.Offset + segment.HeaderSize
+	end := int(size)
+	if segment.Size >= 0 {
+		end = segment.end()
+	}
+
+	// [start, end) of data in memory
+	memory := [][2]int{{0, start}}
+	var children []*ebmlElement
+	for offset := start; offset < end; {
+		child, err := readMatroskaHeader(input, offset, end)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+		memory = append(memory, matroskaMemory(child))
+		if child.Size < 0 {
+			break
+		}
+		offset = child.end()
+	}
+
+	// elements after element of unknown size
+	if len(children) > 0 && children[len(children)-1].Size < 0 {
+		last := children[len(children)-1].Offset
+		for _, child := range children {
+			if child.ID != matroskaSeekHead {
+				continue
+			}
+			data, err := seekAndRead(input, int64(child.Offset+child.HeaderSize), io.SeekStart, child.Size)
+			if err != nil {
+				return nil, err
+			}
+			seeks, err := readMatroskaSeeks(data)
+			if err != nil {
+				return nil, err
+			}
+			for _, position := range seeks {
+				if start+position <= last || start+position >= end {
+					continue
+				}
+				element, err := readMatroskaHeader(input, start+position, end)
+				if err != nil {
+					return nil, err
+				}
+				memory = append(memory, matroskaMemory(element))
+			}
+		}
+	}
+
+	sort.Slice(memory, func(i, j int) bool {
+		return memory[i][0] < memory[j][0]
+	})
+	file := &matroskaFile{}
+	position := 0
+	for _, part := range append(memory, [2]int{int(size), int(size)}) {
+		if part[0] > position {
+			region, err := readRegion(input, int64(position), int64(part[0]-position))
+			if err != nil {
+				return nil, err
+			}
+			file.parts = append(file.parts, &matroskaPart{offset: position, region: region})
+			position = part[0]
+		}
+		if part[1] > position {
+			data, err := seekAndRead(input, int64(position), io.SeekStart, part[1]-position)
+			if err != nil {
+				return nil, err
+			}
+			file.parts = append(file.parts, &matroskaPart{offset: position, data: data})
+			position = part[1]
+		}
+	}
+	file.merge()
+	return file, nil
+}
+
+// readMatroskaHeader - id and size of element at offset before end, payload isn't read
+func readMatroskaHeader(input io.ReadSeeker, offset int, end int) (*ebmlElement, error) {
+	// 4 bytes id and 8 bytes size at most
+	length := end - offset
+	if length > 12 {
+		length = 12
+	}
+	if length <= 0 {
+		return nil, ErrIncorrectLength
+	}
+	data, err := seekAndRead(input, int64(offset), io.SeekStart, length)
+	if err != nil {
+		return nil, err
+	}
+
+	id, idLength, err := readEBMLID(data)
+	if err != nil {
+		return nil, err
+	}
+	size, sizeLength, err := readEBMLSize(data[idLength:])
+	if err != nil {
+		return nil, err
+	}
+	element := ebmlElement{
+		ID:         id,
+		Offset:     offset,
+		HeaderSize: idLength + sizeLength,
+		Size:       size,
+	}
+	if size >= 0 && element.end() > end {
+		return nil, ErrIncorrectLength
+	}
+	return &element, nil
+}
+
+// matroskaMemory - part of top level element read into memory, header only for clusters
+func matroskaMemory(element *ebmlElement) [2]int {
+	if element.ID == matroskaCluster || element.Size < 0 {
+		return [2]int{element.Offset, element.Offset + element.HeaderSize}
+	}
+	return [2]int{element.Offset, element.end()}
+}
+
+func (file *matroskaFile) size() int {
+	if len(file.parts) == 0 {
+		return 0
+	}
+	last := file.parts[len(file.parts)-1]
+	return last.offset + last.size()
+}
+
+// memory - data from offset to the end of its part, nil if it isn't in memory
+func (file *matroskaFile) memory(offset int) []byte {
+	for _, part := range file.parts {
+		if offset < part.offset || offset >= part.offset+part.size() {
+			continue
+		}
+		if part.region != nil {
+			return nil
+		}
+		return part.data[offset-part.offset:]
+	}
+	return nil
+}
+
+// element - element at offset before end, payload is nil if it isn't in memory
+func (file *matroskaFile) element(offset int, end int) (*ebmlElement, error) {
+	data := file.memory(offset)
+	if len(data) > end-offset {
+		data = data[:end-offset]
+	}
+	id, idLength, err := readEBMLID(data)
+	if err != nil {
+		return nil, err
+	}
+	size, sizeLength, err := readEBMLSize(data[idLength:])
+	if err != nil {
+		return nil, err
+	}
+
+	element := ebmlElement{
+		ID:         id,
+		Offset:     offset,
+		HeaderSize: idLength + sizeLength,
+		Size:       size,
+	}
+	if size >= 0 {
+		if element.end() > end {
+			return nil, ErrIncorrectLength
+		}
+		if element.end()-offset <= len(data) {
+			element.Data = data[element.HeaderSize : element.end()-offset]
+		}
+	}
+	return &element, nil
+}
+
+// writeAt - overwrite data in memory
+func (file *matroskaFile) writeAt(data []byte, offset int) error {
+	memory := file.memory(offset)
+	if len(memory) < len(data) {
+		return ErrWriting
+	}
+	copy(memory, data)
+	return nil
+}
+
+// insert - insert data at offset, following parts are shifted
+func (file *matroskaFile) insert(data []byte, offset int) {
+	parts := make([]*matroskaPart, 0, len(file.parts)+2)
+	inserted := false
+	for _, part := range file.parts {
+		if !inserted && offset < part.offset+part.size() {
+			if offset > part.offset {
+				var head *matroskaPart
+				head, part = part.split(offset - part.offset)
+				parts = append(parts, head)
+			}
+			parts = append(parts, &matroskaPart{offset: offset, data: data})
+			inserted = true
+		}
+		if inserted {
+			part.offset += len(data)
+		}
+		parts = append(parts, part)
+	}
+	if !inserted {
+		parts = append(parts, &matroskaPart{offset: file.size(), data: data})
+	}
+	file.parts = parts
+	file.merge()
+}
+
+// merge - join adjacent parts in memory, so elements with following Void are in one part
+func (file *matroskaFile) merge() {
+	parts := file.parts[:0]
+	for _, part := range file.parts {
+		if len(parts) > 0 && part.region == nil && parts[len(parts)-1].region == nil {
+			previous := parts[len(parts)-1]
+			previous.data = append(previous.data[:len(previous.data):len(previous.data)], part.data...)
+			continue
+		}
+		parts = append(parts, part)
+	}
+	file.parts = parts
+}
+
+// clone - copy of the file, regions of the source are shared
+func (file *matroskaFile) clone() *matroskaFile {
+	result := &matroskaFile{}
+	for _, part := range file.parts {
+		clone := *part
+		if part.region == nil {
+			clone.data = append([]byte{}, part.data...)
+		}
+		result.parts = append(result.parts, &clone)
+	}
+	return result
+}
+
+// reader - data from start to end
+func (file *matroskaFile) reader(start int, end int) io.Reader {
+	var readers []io.Reader
+	for _, part := range file.parts {
+		from, to := part.offset, part.offset+part.size()
+		if from < start {
+			from = start
+		}
+		if to > end {
+			to = end
+		}
+		if from >= to {
+			continue
+		}
+		if part.region != nil {
+			readers = append(readers, io.NewSectionReader(part.region.source,
+				part.region.offset+int64(from-part.offset), int64(to-from)))
+			continue
+		}
+		readers = append(readers, bytes.NewReader(part.data[from-part.offset:to-part.offset]))
+	}
+	return io.MultiReader(readers...)
+}
+
+// write - parts in memory and regions copied from the source
+func (file *matroskaFile) write(output io.Writer) error {
+	for _, part := range file.parts {
+		if part.region != nil {
+			err := writeAudio(output, nil, part.region)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		_, err := output.Write(part.data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
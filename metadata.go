@@ -16,6 +16,14 @@ var versionsMap = map[Version]string{
 	VersionID3v24:    "id3v2.4",
 	VersionMP4:       "mp4",
 	VersionFLAC:      "flac",
+	VersionOggVorbis: "ogg vorbis",
+	VersionOpus:      "opus",
+	VersionAPEv2:     "apev2",
+	VersionWAV:       "wav",
+	VersionAIFF:      "aiff",
+	VersionMatroska:  "matroska",
+	VersionDSF:       "dsf",
+	VersionLyrics3:   "lyrics3",
 }
 
 func (v Version) String() string {
@@ -25,7 +33,8 @@ func (v Version) String() string {
 type GetMetadata interface {
 	GetAllTagNames() []string
 	GetVersion() Version
-	GetFileData() []byte // all another file data
+	GetFileData() []byte    // all another file data
+	AudioReader() io.Reader // all another file data without reading it into memory
 
 	GetTitle() (string, error)
 	GetArtist() (string, error)
@@ -48,6 +57,7 @@ type GetMetadata interface {
 	GetEncodedBy() (string, error)
 	GetTrackNumber() (int, int, error) // number, total
 	GetPicture() (image.Image, error)
+	GetLyrics(language string) (string, error) // unsynchronised lyrics, of any language if it's empty
 }
 
 type SetMetadata interface {
@@ -72,6 +82,7 @@ type SetMetadata interface {
 	SetEncodedBy(encodedBy string) error
 	SetTrackNumber(number int, total int) error
 	SetPicture(picture image.Image) error
+	SetLyrics(language string, lyrics string) error
 }
 
 type DeleteMetadata interface {
@@ -98,6 +109,7 @@ type DeleteMetadata interface {
 	DeleteEncodedBy() error
 	DeleteTrackNumber() error
 	DeletePicture() error
+	DeleteLyrics(language string) error // all lyrics if language is empty
 }
 
 type SaveMetadata interface {
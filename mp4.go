@@ -16,6 +16,11 @@ const Mp4MoovAtom = "moov"
 const Mp4MetaAtom = "meta"
 const Mp4MetaUpta = "udta"
 const Mp4MetaIlst = "ilst"
+const Mp4MdatAtom = "mdat"
+const Mp4FreeAtom = "free"
+const Mp4HdlrAtom = "hdlr"
+const Mp4DataAtom = "data"
+const Mp4FreeformAtom = "----"
 
 const Mp4TagAlbum = "album"
 const Mp4TagArtist = "artist"
@@ -35,6 +40,24 @@ const Mp4TagComment = "comment"
 const Mp4TagTempo = "tempo"
 const Mp4TagCompilation = "compilation"
 const Mp4TagDisc = "disk"
+const Mp4TagDescription = "description"
+const Mp4TagArranger = "arranger"
+const Mp4TagAuthor = "author"
+const Mp4TagConductor = "conductor"
+const Mp4TagCatalogNumber = "catalog_number"
+
+// data atom types (well-known types from the QuickTime file format).
+const (
+	mp4TypeImplicit = 0
+	mp4TypeUTF8     = 1
+	mp4TypeJPEG     = 13
+	mp4TypePNG      = 14
+	mp4TypeBMP      = 27
+	mp4TypeInteger  = 21
+)
+
+// mean of freeform atoms written by iTunes and most other taggers.
+const mp4FreeformMean = "com.apple.iTunes"
 
 var Mp4Types = [...]string{
 	"mp41",
@@ -65,22 +88,92 @@ var atoms = map[string]string{
 	"tmpo":    Mp4TagTempo,
 	"cpil":    Mp4TagCompilation,
 	"disk":    Mp4TagDisc,
+	"desc":    Mp4TagDescription,
+}
+
+// Tags without a dedicated atom are stored as '----' freeform atoms.
+var freeformAtoms = map[string]string{
+	"ARRANGER":      Mp4TagArranger,
+	"AUTHOR":        Mp4TagAuthor,
+	"CONDUCTOR":     Mp4TagConductor,
+	"CATALOGNUMBER": Mp4TagCatalogNumber,
+}
+
+// Atoms in the order they are written to 'ilst'.
+var mp4WriteOrder = []struct {
+	Atom string
+	Tag  string
+}{
+	{"\xa9nam", Mp4TagTitle},
+	{"\xa9ART", Mp4TagArtist},
+	{"aART", Mp4TagAlbumArtist},
+	{"\xa9alb", Mp4TagAlbum},
+	{"\xa9day", Mp4TagYear},
+	{"\xa9gen", Mp4TagGenre},
+	{"trkn", Mp4TagTrack},
+	{"disk", Mp4TagDisc},
+	{"\xa9wrt", Mp4TagComposer},
+	{"\xa9too", Mp4TagEncoder},
+	{"cprt", Mp4TagCopyright},
+	{"\xa9grp", Mp4TagGrouping},
+	{"keyw", Mp4TagKeyword},
+	{"\xa9lyr", Mp4TagLyrics},
+	{"\xa9cmt", Mp4TagComment},
+	{"desc", Mp4TagDescription},
+	{"tmpo", Mp4TagTempo},
+	{"cpil", Mp4TagCompilation},
+	{"covr", Mp4TagPicture},
+}
+
+type mp4Atom struct {
+	Name string
+	Data []byte // atom payload without header
+
+	// Large - atom was stored with 64-bit size, keep it on write
+	Large bool
+
+	// region - 'mdat' payload in the source, Data is nil
+	region *fileRegion
 }
 
 type MP4 struct {
 	data map[string]interface{}
+
+	// atoms - top level atoms of the file
+	atoms []*mp4Atom
+	// unknown - 'ilst' items that don't map to tags, written back as is
+	unknown []*mp4Atom
 }
 
-func (MP4) GetAllTagNames() []string {
-	panic("implement me")
+func (mp4 *MP4) GetAllTagNames() []string {
+	result := make([]string, 0, len(mp4.data))
+	for key := range mp4.data {
+		if key == Mp4TagTrack+"_TOTAL" || key == Mp4TagDisc+"_TOTAL" {
+			continue
+		}
+		result = append(result, key)
+	}
+	return result
 }
 
 func (mp4 *MP4) GetVersion() Version {
 	return VersionMP4
 }
 
-func (MP4) GetFileData() []byte {
-	panic("implement me")
+func (mp4 *MP4) GetFileData() []byte {
+	mdat := findMp4Atom(mp4.atoms, Mp4MdatAtom)
+	if mdat == nil {
+		return nil
+	}
+	return readSection(audioSection(mdat.Data, mdat.region))
+}
+
+func (mp4 *MP4) AudioReader() io.Reader {
+	mdat := findMp4Atom(mp4.atoms, Mp4MdatAtom)
+	if mdat == nil {
+		return bytes.NewReader(nil)
+	}
+	return audioSection(mdat.Data, mdat.region)
 }
 
 func (mp4 *MP4) GetTitle() (string, error) {
@@ -100,11 +193,24 @@ func (mp4 *MP4) GetYear() (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	// '\xa9day' can hold a full date
+	if len(year) > 4 {
+		year = year[:4]
+	}
 	return strconv.Atoi(year)
 }
 
-func (MP4) GetComment() (string, error) {
-	panic("implement me")
+func (mp4 *MP4) GetComment() (string, error) {
+	return mp4.getString(Mp4TagComment)
+}
+
+// Comments - values of '\xa9cmt' atom, MP4 comments have no language and description
+func (mp4 *MP4) Comments() []Comment {
+	var result []Comment
+	for _, value := range mp4Strings(mp4.data[Mp4TagComment]) {
+		result = append(result, Comment{Text: value})
+	}
+	return result
 }
 
 func (mp4 *MP4) GetGenre() (string, error) {
@@ -115,48 +221,66 @@ func (mp4 *MP4) GetAlbumArtist() (string, error) {
 	return mp4.getString(Mp4TagAlbumArtist)
 }
 
-func (MP4) GetDate() (time.Time, error) {
-	panic("implement me")
+func (mp4 *MP4) GetDate() (time.Time, error) {
+	date, err := mp4.getString(Mp4TagYear)
+	if err != nil {
+		return time.Now(), err
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02", "2006"} {
+		result, errParse := time.Parse(layout, date)
+		if errParse == nil {
+			return result, nil
+		}
+	}
+	return time.Now(), ErrIncorrectTag
 }
 
-func (MP4) GetArranger() (string, error) {
-	panic("implement me")
+func (mp4 *MP4) GetArranger() (string, error) {
+	return mp4.getString(Mp4TagArranger)
 }
 
-func (MP4) GetAuthor() (string, error) {
-	panic("implement me")
+func (mp4 *MP4) GetAuthor() (string, error) {
+	return mp4.getString(Mp4TagAuthor)
 }
 
-func (MP4) GetBPM() (int, error) {
-	panic("implement me")
+func (mp4 *MP4) GetBPM() (int, error) {
+	return mp4.getInt(Mp4TagTempo)
 }
 
-func (MP4) GetCatalogNumber() (string, error) {
-	panic("implement me")
+func (mp4 *MP4) GetCatalogNumber() (string, error) {
+	return mp4.getString(Mp4TagCatalogNumber)
 }
 
-func (MP4) GetCompilation() (string, error) {
-	panic("implement me")
+func (mp4 *MP4) GetCompilation() (string, error) {
+	return mp4.getString(Mp4TagCompilation)
 }
 
 func (mp4 *MP4) GetComposer() (string, error) {
 	return mp4.getString(Mp4TagComposer)
 }
 
-func (MP4) GetConductor() (string, error) {
-	panic("implement me")
+func (mp4 *MP4) GetConductor() (string, error) {
+	return mp4.getString(Mp4TagConductor)
 }
 
 func (mp4 *MP4) GetCopyright() (string, error) {
 	return mp4.getString(Mp4TagCopyright)
 }
 
-func (MP4) GetDescription() (string, error) {
-	panic("implement me")
+func (mp4 *MP4) GetDescription() (string, error) {
+	return mp4.getString(Mp4TagDescription)
 }
 
-func (MP4) GetDiscNumber() (int, int, error) {
-	panic("implement me")
+func (mp4 *MP4) GetDiscNumber() (int, int, error) {
+	disc, err := mp4.getInt(Mp4TagDisc)
+	if err != nil {
+		return 0, 0, err
+	}
+	total, err := mp4.getInt(Mp4TagDisc + "_TOTAL")
+	if err != nil {
+		return 0, 0, err
+	}
+	return disc, total, nil
 }
 
 func (mp4 *MP4) GetEncodedBy() (string, error) {
@@ -180,207 +304,706 @@ func (mp4 *MP4) GetPicture() (image.Image, error) {
 	if !ok {
 		return nil, ErrTagNotFound
 	}
-	picture, ok := pictureBlock.(AttachedPicture)
-	if !ok {
+	pictures, ok := pictureBlock.([]AttachedPicture)
+	if !ok || len(pictures) == 0 {
 		return nil, ErrNotPictureBlock
 	}
+	picture := pictures[0]
 
 	switch picture.MIME {
-	case "image/jpeg":
+	case mimeImageJPEG:
 		return jpeg.Decode(bytes.NewReader(picture.Data))
-	case "image/png":
+	case mimeImagePNG:
 		return png.Decode(bytes.NewReader(picture.Data))
 	}
 
 	return nil, ErrIncorrectTag
 }
 
-func (MP4) SetTitle(title string) error {
-	panic("implement me")
+// GetLyrics - '\xa9lyr' atom, MP4 lyrics have no language
+func (mp4 *MP4) GetLyrics(language string) (string, error) {
+	return mp4.getString(Mp4TagLyrics)
 }
 
-func (MP4) SetArtist(artist string) error {
-	panic("implement me")
+func (mp4 *MP4) SetTitle(title string) error {
+	mp4.data[Mp4TagTitle] = title
+	return nil
 }
 
-func (MP4) SetAlbum(album string) error {
-	panic("implement me")
+func (mp4 *MP4) SetArtist(artist string) error {
+	mp4.data[Mp4TagArtist] = artist
+	return nil
 }
 
-func (MP4) SetYear(year int) error {
-	panic("implement me")
+func (mp4 *MP4) SetAlbum(album string) error {
+	mp4.data[Mp4TagAlbum] = album
+	return nil
 }
 
-func (MP4) SetComment(comment string) error {
-	panic("implement me")
+func (mp4 *MP4) SetYear(year int) error {
+	// keep the rest of the date if '\xa9day' holds one
+	date, err := mp4.getString(Mp4TagYear)
+	if err == nil && len(date) > 4 {
+		mp4.data[Mp4TagYear] = strconv.Itoa(year) + date[4:]
+		return nil
+	}
+	mp4.data[Mp4TagYear] = strconv.Itoa(year)
+	return nil
 }
 
-func (MP4) SetGenre(genre string) error {
-	panic("implement me")
+func (mp4 *MP4) SetComment(comment string) error {
+	mp4.data[Mp4TagComment] = comment
+	return nil
 }
 
-func (MP4) SetAlbumArtist(albumArtist string) error {
-	panic("implement me")
+func (mp4 *MP4) SetGenre(genre string) error {
+	mp4.data[Mp4TagGenre] = genre
+	return nil
 }
 
-func (MP4) SetDate(date time.Time) error {
-	panic("implement me")
+func (mp4 *MP4) SetAlbumArtist(albumArtist string) error {
+	mp4.data[Mp4TagAlbumArtist] = albumArtist
+	return nil
 }
 
-func (MP4) SetArranger(arranger string) error {
-	panic("implement me")
+func (mp4 *MP4) SetDate(date time.Time) error {
+	mp4.data[Mp4TagYear] = date.Format("2006-01-02T15:04:05")
+	return nil
 }
 
-func (MP4) SetAuthor(author string) error {
-	panic("implement me")
+func (mp4 *MP4) SetArranger(arranger string) error {
+	mp4.data[Mp4TagArranger] = arranger
+	return nil
 }
 
-func (MP4) SetBPM(bmp int) error {
-	panic("implement me")
+func (mp4 *MP4) SetAuthor(author string) error {
+	mp4.data[Mp4TagAuthor] = author
+	return nil
 }
 
-func (MP4) SetCatalogNumber(catalogNumber string) error {
-	panic("implement me")
+func (mp4 *MP4) SetBPM(bmp int) error {
+	mp4.data[Mp4TagTempo] = bmp
+	return nil
 }
 
-func (MP4) SetCompilation(compilation string) error {
-	panic("implement me")
+func (mp4 *MP4) SetCatalogNumber(catalogNumber string) error {
+	mp4.data[Mp4TagCatalogNumber] = catalogNumber
+	return nil
 }
 
-func (MP4) SetComposer(composer string) error {
-	panic("implement me")
+func (mp4 *MP4) SetCompilation(compilation string) error {
+	if _, err := strconv.Atoi(compilation); err != nil {
+		return ErrIncorrectTag
+	}
+	mp4.data[Mp4TagCompilation] = compilation
+	return nil
 }
 
-func (MP4) SetConductor(conductor string) error {
-	panic("implement me")
+func (mp4 *MP4) SetComposer(composer string) error {
+	mp4.data[Mp4TagComposer] = composer
+	return nil
 }
 
-func (MP4) SetCopyright(copyright string) error {
-	panic("implement me")
+func (mp4 *MP4) SetConductor(conductor string) error {
+	mp4.data[Mp4TagConductor] = conductor
+	return nil
 }
 
-func (MP4) SetDescription(description string) error {
-	panic("implement me")
+func (mp4 *MP4) SetCopyright(copyright string) error {
+	mp4.data[Mp4TagCopyright] = copyright
+	return nil
 }
 
-func (MP4) SetDiscNumber(number int, total int) error {
-	panic("implement me")
+func (mp4 *MP4) SetDescription(description string) error {
+	mp4.data[Mp4TagDescription] = description
+	return nil
 }
 
-func (MP4) SetEncodedBy(encodedBy string) error {
-	panic("implement me")
+func (mp4 *MP4) SetDiscNumber(number int, total int) error {
+	mp4.data[Mp4TagDisc] = number
+	mp4.data[Mp4TagDisc+"_TOTAL"] = total
+	return nil
 }
 
-func (MP4) SetTrackNumber(number int, total int) error {
-	panic("implement me")
+func (mp4 *MP4) SetEncodedBy(encodedBy string) error {
+	mp4.data[Mp4TagEncoder] = encodedBy
+	return nil
 }
 
-func (MP4) SetPicture(picture image.Image) error {
-	panic("implement me")
+func (mp4 *MP4) SetTrackNumber(number int, total int) error {
+	mp4.data[Mp4TagTrack] = number
+	mp4.data[Mp4TagTrack+"_TOTAL"] = total
+	return nil
 }
 
-func (MP4) DeleteAll() error {
-	panic("implement me")
+func (mp4 *MP4) SetPicture(picture image.Image) error {
+	// Only PNG
+	buf := new(bytes.Buffer)
+	err := png.Encode(buf, picture)
+	if err != nil {
+		return err
+	}
+
+	mp4.data[Mp4TagPicture] = []AttachedPicture{{
+		MIME:        mimeImagePNG,
+		PictureType: PictureTypeFrontCover,
+		Data:        buf.Bytes(),
+	}}
+	return nil
 }
 
-func (MP4) DeleteTitle() error {
-	panic("implement me")
+func (mp4 *MP4) SetLyrics(language string, lyrics string) error {
+	mp4.data[Mp4TagLyrics] = lyrics
+	return nil
 }
 
-func (MP4) DeleteArtist() error {
-	panic("implement me")
+// Pictures - pictures of 'covr' atom. They have no type and description, all are front covers
+func (mp4 *MP4) Pictures() []AttachedPicture {
+	pictures, _ := mp4.data[Mp4TagPicture].([]AttachedPicture)
+	return append([]AttachedPicture{}, pictures...)
 }
 
-func (MP4) DeleteAlbum() error {
-	panic("implement me")
+// AddPicture - add JPEG, PNG or BMP picture to 'covr' atom
+func (mp4 *MP4) AddPicture(picture AttachedPicture) error {
+	if mp4PictureType(picture.MIME) == 0 {
+		return ErrIncorrectTag
+	}
+	pictures, _ := mp4.data[Mp4TagPicture].([]AttachedPicture)
+	picture.PictureType = PictureTypeFrontCover
+	picture.Description = ""
+	mp4.data[Mp4TagPicture] = append(pictures, picture)
+	return nil
 }
 
-func (MP4) DeleteYear() error {
-	panic("implement me")
+// RemovePicture - delete all pictures of 'covr' atom for PictureTypeFrontCover
+func (mp4 *MP4) RemovePicture(pictureType byte) error {
+	if pictureType == PictureTypeFrontCover {
+		delete(mp4.data, Mp4TagPicture)
+	}
+	return nil
 }
 
-func (MP4) DeleteComment() error {
-	panic("implement me")
+// ReplacePicture - replace pictures of 'covr' atom with the picture
+func (mp4 *MP4) ReplacePicture(picture AttachedPicture) error {
+	if mp4PictureType(picture.MIME) == 0 {
+		return ErrIncorrectTag
+	}
+	err := mp4.RemovePicture(PictureTypeFrontCover)
+	if err != nil {
+		return err
+	}
+	return mp4.AddPicture(picture)
+}
+
+// mp4PictureType - type of data atom for picture MIME, 0 if not supported
+func mp4PictureType(mime string) uint32 {
+	switch mime {
+	case mimeImageJPEG:
+		return mp4TypeJPEG
+	case mimeImagePNG:
+		return mp4TypePNG
+	case mimeImageBMP:
+		return mp4TypeBMP
+	}
+	return 0
+}
+
+func (mp4 *MP4) DeleteAll() error {
+	mp4.data = map[string]interface{}{}
+	mp4.unknown = nil
+	return nil
+}
+
+func (mp4 *MP4) DeleteTitle() error {
+	return mp4.deleteTag(Mp4TagTitle)
+}
+
+func (mp4 *MP4) DeleteArtist() error {
+	return mp4.deleteTag(Mp4TagArtist)
+}
+
+func (mp4 *MP4) DeleteAlbum() error {
+	return mp4.deleteTag(Mp4TagAlbum)
+}
+
+func (mp4 *MP4) DeleteYear() error {
+	return mp4.deleteTag(Mp4TagYear)
+}
+
+func (mp4 *MP4) DeleteComment() error {
+	return mp4.deleteTag(Mp4TagComment)
+}
+
+func (mp4 *MP4) DeleteGenre() error {
+	return mp4.deleteTag(Mp4TagGenre)
 }
 
-func (MP4) DeleteGenre() error {
-	panic("implement me")
+func (mp4 *MP4) DeleteAlbumArtist() error {
+	return mp4.deleteTag(Mp4TagAlbumArtist)
 }
 
-func (MP4) DeleteAlbumArtist() error {
-	panic("implement me")
+func (mp4 *MP4) DeleteDate() error {
+	return mp4.deleteTag(Mp4TagYear)
 }
 
-func (MP4) DeleteDate() error {
-	panic("implement me")
+func (mp4 *MP4) DeleteArranger() error {
+	return mp4.deleteTag(Mp4TagArranger)
 }
 
-func (MP4) DeleteArranger() error {
-	panic("implement me")
+func (mp4 *MP4) DeleteAuthor() error {
+	return mp4.deleteTag(Mp4TagAuthor)
 }
 
-func (MP4) DeleteAuthor() error {
-	panic("implement me")
+func (mp4 *MP4) DeleteBPM() error {
+	return mp4.deleteTag(Mp4TagTempo)
 }
 
-func (MP4) DeleteBPM() error {
-	panic("implement me")
+func (mp4 *MP4) DeleteCatalogNumber() error {
+	return mp4.deleteTag(Mp4TagCatalogNumber)
 }
 
-func (MP4) DeleteCatalogNumber() error {
-	panic("implement me")
+func (mp4 *MP4) DeleteCompilation() error {
+	return mp4.deleteTag(Mp4TagCompilation)
 }
 
-func (MP4) DeleteCompilation() error {
-	panic("implement me")
+func (mp4 *MP4) DeleteComposer() error {
+	return mp4.deleteTag(Mp4TagComposer)
 }
 
-func (MP4) DeleteComposer() error {
-	panic("implement me")
+func (mp4 *MP4) DeleteConductor() error {
+	return mp4.deleteTag(Mp4TagConductor)
 }
 
-func (MP4) DeleteConductor() error {
-	panic("implement me")
+func (mp4 *MP4) DeleteCopyright() error {
+	return mp4.deleteTag(Mp4TagCopyright)
 }
 
-func (MP4) DeleteCopyright() error {
-	panic("implement me")
+func (mp4 *MP4) DeleteDescription() error {
+	return mp4.deleteTag(Mp4TagDescription)
 }
 
-func (MP4) DeleteDescription() error {
-	panic("implement me")
+func (mp4 *MP4) DeleteDiscNumber() error {
+	delete(mp4.data, Mp4TagDisc+"_TOTAL")
+	return mp4.deleteTag(Mp4TagDisc)
 }
 
-func (MP4) DeleteDiscNumber() error {
-	panic("implement me")
+func (mp4 *MP4) DeleteEncodedBy() error {
+	return mp4.deleteTag(Mp4TagEncoder)
+}
+
+func (mp4 *MP4) DeleteTrackNumber() error {
+	delete(mp4.data, Mp4TagTrack+"_TOTAL")
+	return mp4.deleteTag(Mp4TagTrack)
+}
+
+func (mp4 *MP4) DeletePicture() error {
+	return mp4.deleteTag(Mp4TagPicture)
+}
+
+func (mp4 *MP4) DeleteLyrics(language string) error {
+	return mp4.deleteTag(Mp4TagLyrics)
+}
+
+func (mp4 *MP4) SaveFile(path string) error {
+	return saveFile(path, mp4.Save, SaveOptions{})
+}
+
+// Save - write file with rebuilt 'moov/udta/meta/ilst'.
+// If 'moov' changes its size and it is located before 'mdat'
+// chunk offsets in 'stco'/'co64' are moved by the same delta.
+func (mp4 *MP4) Save(input io.WriteSeeker) error {
+	result, err := mp4.buildAtoms()
+	if err != nil {
+		return err
+	}
+
+	for _, atom := range result {
+		err = writeMp4Atom(input, atom)
+		if err != nil {
+			return err
+		}
+	}
+
+	mp4.atoms = result
+	return nil
+}
+
+// nolint:gocyclo
+func (mp4 *MP4) buildAtoms() ([]*mp4Atom, error) {
+	moovIndex := -1
+	var moovOffset int64
+	for i, atom := range mp4.atoms {
+		if atom.Name == Mp4MoovAtom {
+			moovIndex = i
+			break
+		}
+		moovOffset += atom.size()
+	}
+	if moovIndex == -1 {
+		return nil, ErrIncorrectTag
+	}
+	moov := mp4.atoms[moovIndex]
+
+	moovChildren, err := readMp4Atoms(moov.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	// moov/udta
+	udta := findMp4Atom(moovChildren, Mp4MetaUpta)
+	if udta == nil {
+		udta = &mp4Atom{Name: Mp4MetaUpta}
+		moovChildren = append(moovChildren, udta)
+	}
+	udtaChildren, err := readMp4Atoms(udta.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	// moov/udta/meta
+	meta := findMp4Atom(udtaChildren, Mp4MetaAtom)
+	if meta == nil {
+		meta = &mp4Atom{Name: Mp4MetaAtom, Data: make([]byte, 4)}
+		udtaChildren = append(udtaChildren, meta)
+	}
+	if len(meta.Data) < 4 {
+		return nil, ErrIncorrectLength
+	}
+	metaChildren, err := readMp4Atoms(meta.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	if findMp4Atom(metaChildren, Mp4HdlrAtom) == nil {
+		metaChildren = append([]*mp4Atom{newMp4MetaHandler()}, metaChildren...)
+	}
+
+	// moov/udta/meta/ilst
+	ilst := &mp4Atom{Name: Mp4MetaIlst, Data: mp4.serializeIlst()}
+	ilstIndex := -1
+	for i, atom := range metaChildren {
+		if atom.Name == Mp4MetaIlst {
+			ilstIndex = i
+			break
+		}
+	}
+	if ilstIndex == -1 {
+		metaChildren = append(metaChildren, ilst)
+	} else {
+		old := metaChildren[ilstIndex]
+		metaChildren[ilstIndex] = ilst
+		// reuse padding after 'ilst'
+		if ilstIndex+1 < len(metaChildren) {
+			resizeMp4Free(metaChildren[ilstIndex+1], ilst.size()-old.size())
+		}
+	}
+
+	meta.Data = append(append([]byte{}, meta.Data[:4]...), serializeMp4Atoms(metaChildren)...)
+	udta.Data = serializeMp4Atoms(udtaChildren)
+	newMoov := &mp4Atom{Name: Mp4MoovAtom, Data: serializeMp4Atoms(moovChildren), Large: moov.Large}
+
+	result := make([]*mp4Atom, len(mp4.atoms))
+	copy(result, mp4.atoms)
+	result[moovIndex] = newMoov
+
+	delta := newMoov.size() - moov.size()
+	if delta == 0 {
+		return result, nil
+	}
+
+	// reuse padding after 'moov'
+	if moovIndex+1 < len(result) && result[moovIndex+1].Name == Mp4FreeAtom {
+		free := &mp4Atom{Name: Mp4FreeAtom, Data: result[moovIndex+1].Data, Large: result[moovIndex+1].Large}
+		if resizeMp4Free(free, delta) {
+			result[moovIndex+1] = free
+			return result, nil
+		}
+	}
+
+	// all atoms after 'moov' are moved. 'stco' converted to 'co64'
+	// grows 'moov' again, offsets are shifted until delta is stable
+	data := newMoov.Data
+	for {
+		children, err := readMp4Atoms(data)
+		if err != nil {
+			return nil, err
+		}
+		err = shiftMp4ChunkOffsets(children, moovOffset+moov.size(), delta)
+		if err != nil {
+			return nil, err
+		}
+		newMoov.Data = serializeMp4Atoms(children)
+		if newMoov.size()-moov.size() == delta {
+			return result, nil
+		}
+		delta = newMoov.size() - moov.size()
+	}
+}
+
+// resizeMp4Free - shrink or grow 'free' atom to compensate
+// size delta of the previous atom.
+func resizeMp4Free(free *mp4Atom, delta int64) bool {
+	if free.Name != Mp4FreeAtom {
+		return false
+	}
+	size := int64(len(free.Data)) - delta
+	if size < 0 {
+		return false
+	}
+	free.Data = make([]byte, size)
+	return true
+}
+
+func shiftMp4ChunkOffsets(atoms []*mp4Atom, from int64, delta int64) error {
+	for _, atom := range atoms {
+		switch atom.Name {
+		case "trak", "mdia", "minf", "stbl":
+			children, err := readMp4Atoms(atom.Data)
+			if err != nil {
+				return err
+			}
+			err = shiftMp4ChunkOffsets(children, from, delta)
+			if err != nil {
+				return err
+			}
+			atom.Data = serializeMp4Atoms(children)
+		case "stco":
+			// [version and flags (4)][count (4)][offset (4)]...
+			if len(atom.Data) < 8 {
+				return ErrIncorrectLength
+			}
+			count := int(binary.BigEndian.Uint32(atom.Data[4:8]))
+			if len(atom.Data) < 8+count*4 {
+				return ErrIncorrectLength
+			}
+			offsets := make([]int64, count)
+			large := false
+			for i := range offsets {
+				offset := int64(binary.BigEndian.Uint32(atom.Data[8+i*4:]))
+				if offset >= from {
+					offset += delta
+				}
+				if offset < 0 {
+					return ErrIncorrectLength
+				}
+				large = large || offset > 0xFFFFFFFF
+				offsets[i] = offset
+			}
+			if large {
+				// offsets don't fit 32 bits, table is converted to 'co64'
+				data := make([]byte, 8+count*8)
+				copy(data, atom.Data[:8])
+				for i, offset := range offsets {
+					binary.BigEndian.PutUint64(data[8+i*8:], uint64(offset))
+				}
+				atom.Name = "co64"
+				atom.Data = data
+				continue
+			}
+			data := append([]byte{}, atom.Data...)
+			for i, offset := range offsets {
+				binary.BigEndian.PutUint32(data[8+i*4:], uint32(offset))
+			}
+			atom.Data = data
+		case "co64":
+			// [version and flags (4)][count (4)][offset (8)]...
+			if len(atom.Data) < 8 {
+				return ErrIncorrectLength
+			}
+			data := append([]byte{}, atom.Data...)
+			count := int(binary.BigEndian.Uint32(data[4:8]))
+			if len(data) < 8+count*8 {
+				return ErrIncorrectLength
+			}
+			for i := 0; i < count; i++ {
+				pos := 8 + i*8
+				offset := int64(binary.BigEndian.Uint64(data[pos:]))
+				if offset < from {
+					continue
+				}
+				binary.BigEndian.PutUint64(data[pos:], uint64(offset+delta))
+			}
+			atom.Data = data
+		}
+	}
+	return nil
 }
 
-func (MP4) DeleteEncodedBy() error {
-	panic("implement me")
+// nolint:gocyclo
+func (mp4 *MP4) serializeIlst() []byte {
+	output := new(bytes.Buffer)
+	written := map[string]bool{}
+	for _, item := range mp4WriteOrder {
+		value, ok := mp4.data[item.Tag]
+		if !ok {
+			continue
+		}
+
+		var data []byte
+		switch item.Tag {
+		case Mp4TagTrack, Mp4TagDisc:
+			// [reserved (2)][number (2)][total (2)] + [reserved (2)] for track
+			number, _ := value.(int)
+			total, _ := mp4.data[item.Tag+"_TOTAL"].(int)
+			payload := make([]byte, 6)
+			binary.BigEndian.PutUint16(payload[2:4], uint16(number))
+			binary.BigEndian.PutUint16(payload[4:6], uint16(total))
+			if item.Tag == Mp4TagTrack {
+				payload = append(payload, 0, 0)
+			}
+			data = newMp4DataAtom(mp4TypeImplicit, payload)
+		case Mp4TagTempo:
+			tempo, _ := value.(int)
+			payload := make([]byte, 2)
+			binary.BigEndian.PutUint16(payload, uint16(tempo))
+			data = newMp4DataAtom(mp4TypeInteger, payload)
+		case Mp4TagCompilation:
+			str, _ := value.(string)
+			compilation, _ := strconv.Atoi(str)
+			data = newMp4DataAtom(mp4TypeInteger, []byte{byte(compilation)})
+		case Mp4TagPicture:
+			pictures, _ := value.([]AttachedPicture)
+			if len(pictures) == 0 {
+				continue
+			}
+			for _, picture := range pictures {
+				data = append(data, newMp4DataAtom(mp4PictureType(picture.MIME), picture.Data)...)
+			}
+		default:
+			for _, str := range mp4Strings(value) {
+				data = append(data, newMp4DataAtom(mp4TypeUTF8, []byte(str))...)
+			}
+		}
+
+		_ = writeMp4Atom(output, &mp4Atom{Name: item.Atom, Data: data})
+		written[item.Atom] = true
+	}
+
+	// freeform atoms in stable order
+	for _, name := range []string{"ARRANGER", "AUTHOR", "CONDUCTOR", "CATALOGNUMBER"} {
+		values := mp4Strings(mp4.data[freeformAtoms[name]])
+		if len(values) == 0 {
+			continue
+		}
+		_ = writeMp4Atom(output, &mp4Atom{Name: Mp4FreeformAtom, Data: newMp4FreeformAtom(name, values)})
+		written[Mp4FreeformAtom+":"+name] = true
+	}
+
+	// unknown items with the name of a written item are replaced by it
+	for _, atom := range mp4.unknown {
+		if written[mp4ItemName(atom)] {
+			continue
+		}
+		_ = writeMp4Atom(output, atom)
+	}
+
+	return output.Bytes()
 }
 
-func (MP4) DeleteTrackNumber() error {
-	panic("implement me")
+// mp4ItemName - name of 'ilst' item, '----:name' for freeform items of iTunes
+func mp4ItemName(item *mp4Atom) string {
+	if item.Name != Mp4FreeformAtom {
+		return item.Name
+	}
+	children, err := readMp4Atoms(item.Data)
+	if err != nil {
+		return item.Name
+	}
+	mean := findMp4Atom(children, "mean")
+	name := findMp4Atom(children, "name")
+	if mean == nil || name == nil || len(mean.Data) < 4 || len(name.Data) < 4 {
+		return item.Name
+	}
+	if string(mean.Data[4:]) != mp4FreeformMean {
+		return item.Name
+	}
+	return item.Name + ":" + string(name.Data[4:])
 }
 
-func (MP4) DeletePicture() error {
-	panic("implement me")
+// data atom
+// [version (1)][type (3)][locale (4)][value].
+func newMp4DataAtom(dataType uint32, value []byte) []byte {
+	payload := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint32(payload[0:4], dataType)
+	payload = append(payload, value...)
+
+	output := new(bytes.Buffer)
+	_ = writeMp4Atom(output, &mp4Atom{Name: Mp4DataAtom, Data: payload})
+	return output.Bytes()
 }
 
-func (MP4) SaveFile(path string) error {
-	panic("implement me")
+// freeform atom
+// [mean][name][data].
+func newMp4FreeformAtom(name string, values []string) []byte {
+	output := new(bytes.Buffer)
+	_ = writeMp4Atom(output, &mp4Atom{Name: "mean", Data: append(make([]byte, 4), mp4FreeformMean...)})
+	_ = writeMp4Atom(output, &mp4Atom{Name: "name", Data: append(make([]byte, 4), name...)})
+	for _, value := range values {
+		output.Write(newMp4DataAtom(mp4TypeUTF8, []byte(value)))
+	}
+	return output.Bytes()
 }
 
-func (MP4) Save(input io.WriteSeeker) error {
-	panic("implement me")
+// metadata handler required by iTunes
+// [version and flags (4)][predefined (4)][handler 'mdir'][reserved 'appl' + 8 zero bytes][empty name].
+func newMp4MetaHandler() *mp4Atom {
+	data := make([]byte, 4+4+4+12+1)
+	copy(data[8:12], "mdir")
+	copy(data[12:16], "appl")
+	return &mp4Atom{Name: Mp4HdlrAtom, Data: data}
 }
 
+// getString - text of the tag, the first value of list
 func (mp4 *MP4) getString(tag string) (string, error) {
 	val, ok := mp4.data[tag]
 	if !ok {
 		return "", ErrTagNotFound
 	}
-	return val.(string), nil
+	values := mp4Strings(val)
+	if len(values) == 0 {
+		return "", ErrIncorrectTag
+	}
+	return values[0], nil
+}
+
+// GetValues - all values of text tag (Mp4TagArtist, Mp4TagGenre, ...), each is stored in own data atom
+func (mp4 *MP4) GetValues(tag string) ([]string, error) {
+	val, ok := mp4.data[tag]
+	if !ok {
+		return nil, ErrTagNotFound
+	}
+	values := mp4Strings(val)
+	if len(values) == 0 {
+		return nil, ErrIncorrectTag
+	}
+	return append([]string{}, values...), nil
+}
+
+// SetValues - replace all values of text tag, empty values delete it
+func (mp4 *MP4) SetValues(tag string, values []string) error {
+	switch tag {
+	case Mp4TagTrack, Mp4TagDisc, Mp4TagTempo, Mp4TagCompilation, Mp4TagPicture:
+		return ErrIncorrectTag
+	}
+	switch len(values) {
+	case 0:
+		delete(mp4.data, tag)
+	case 1:
+		mp4.data[tag] = values[0]
+	default:
+		mp4.data[tag] = append([]string{}, values...)
+	}
+	return nil
+}
+
+// mp4Strings - values of text tag, nil for other tags
+func mp4Strings(value interface{}) []string {
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []string:
+		return value
+	}
+	return nil
 }
 
 func (mp4 *MP4) getInt(tag string) (int, error) {
@@ -388,7 +1011,16 @@ func (mp4 *MP4) getInt(tag string) (int, error) {
 	if !ok {
 		return 0, ErrTagNotFound
 	}
-	return val.(int), nil
+	number, ok := val.(int)
+	if !ok {
+		return 0, ErrIncorrectTag
+	}
+	return number, nil
+}
+
+func (mp4 *MP4) deleteTag(tag string) error {
+	delete(mp4.data, tag)
+	return nil
 }
 
 func checkMp4(input io.ReadSeeker) bool {
@@ -428,76 +1060,279 @@ func ReadMp4(input io.ReadSeeker) (*MP4, error) {
 		return nil, err
 	}
 
-	for {
-		var size uint32 = 0
-		err = binary.Read(input, binary.BigEndian, &size)
-		if err != nil {
-			break
-		}
+	header.atoms, err = readMp4FileAtoms(input)
+	if err != nil {
+		return nil, err
+	}
 
-		nameBytes := make([]byte, 4)
-		_, err = input.Read(nameBytes)
-		if err != nil {
-			break
-		}
-		name := string(nameBytes)
+	moov := findMp4Atom(header.atoms, Mp4MoovAtom)
+	if moov == nil {
+		return &header, nil
+	}
 
-		bytes := make([]byte, size-8)
-		_, err = input.Read(bytes)
-		if err != nil {
-			break
+	err = parseMoovAtom(moov.Data, &header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &header, nil
+}
+
+// parseMoovAtom - read tags from moov/udta/meta/ilst.
+func parseMoovAtom(data []byte, mp4 *MP4) error {
+	children, err := readMp4Atoms(data)
+	if err != nil {
+		return err
+	}
+	udta := findMp4Atom(children, Mp4MetaUpta)
+	if udta == nil {
+		return nil
+	}
+
+	children, err = readMp4Atoms(udta.Data)
+	if err != nil {
+		return err
+	}
+	meta := findMp4Atom(children, Mp4MetaAtom)
+	if meta == nil {
+		return nil
+	}
+
+	// meta is a full atom: skip version and flags
+	if len(meta.Data) < 4 {
+		return ErrIncorrectLength
+	}
+	children, err = readMp4Atoms(meta.Data[4:])
+	if err != nil {
+		return err
+	}
+	ilst := findMp4Atom(children, Mp4MetaIlst)
+	if ilst == nil {
+		return nil
+	}
+
+	items, err := readMp4Atoms(ilst.Data)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if !parseAtomData(item, mp4) {
+			mp4.unknown = append(mp4.unknown, item)
 		}
+	}
+	return nil
+}
+
+// parseAtomData - read 'ilst' item. Return false if item isn't known.
+// nolint:gocyclo
+func parseAtomData(item *mp4Atom, mp4 *MP4) bool {
+	children, err := readMp4Atoms(item.Data)
+	if err != nil {
+		return false
+	}
 
-		if name == Mp4MoovAtom {
-			parseMoovAtom(bytes, &header)
+	atomName, ok := atoms[item.Name]
+	if item.Name == Mp4FreeformAtom {
+		mean := findMp4Atom(children, "mean")
+		name := findMp4Atom(children, "name")
+		if mean == nil || name == nil || len(mean.Data) < 4 || len(name.Data) < 4 {
+			return false
+		}
+		if string(mean.Data[4:]) != mp4FreeformMean {
+			return false
 		}
+		atomName, ok = freeformAtoms[string(name.Data[4:])]
+	}
+	if !ok {
+		return false
 	}
 
-	return &header, nil
+	data := findMp4Atom(children, Mp4DataAtom)
+	if data == nil || len(data.Data) < 8 {
+		return false
+	}
+	value := data.Data[8:]
+
+	switch {
+	case atomName == Mp4TagTrack || atomName == Mp4TagDisc:
+		if len(value) < 6 {
+			return false
+		}
+		mp4.data[atomName] = int(binary.BigEndian.Uint16(value[2:4]))
+		mp4.data[atomName+"_TOTAL"] = int(binary.BigEndian.Uint16(value[4:6]))
+	case atomName == Mp4TagTempo:
+		mp4.data[atomName] = ByteToInt(value)
+	case atomName == Mp4TagCompilation:
+		mp4.data[atomName] = strconv.Itoa(ByteToInt(value))
+	case atomName == Mp4TagPicture:
+		// picture in each data atom
+		var pictures []AttachedPicture
+		for _, child := range children {
+			if child.Name != Mp4DataAtom || len(child.Data) < 8 {
+				continue
+			}
+			mime := ""
+			switch binary.BigEndian.Uint32(child.Data[0:4]) & 0xFFFFFF {
+			case mp4TypeJPEG:
+				mime = mimeImageJPEG
+			case mp4TypePNG:
+				mime = mimeImagePNG
+			case mp4TypeBMP:
+				mime = mimeImageBMP
+			default:
+				return false
+			}
+			pictures = append(pictures, AttachedPicture{
+				MIME:        mime,
+				PictureType: PictureTypeFrontCover,
+				Data:        child.Data[8:],
+			})
+		}
+		mp4.data[atomName] = pictures
+	default:
+		// list of values in several data atoms
+		values := []string{}
+		for _, child := range children {
+			if child.Name == Mp4DataAtom && len(child.Data) >= 8 {
+				values = append(values, string(child.Data[8:]))
+			}
+		}
+		if len(values) > 1 {
+			mp4.data[atomName] = values
+		} else {
+			mp4.data[atomName] = string(value)
+		}
+	}
+	return true
 }
 
-func parseMoovAtom(bytes []byte, mp4 *MP4) {
-	for {
-		size := binary.BigEndian.Uint32(bytes[0:4])
-		name := string(bytes[4:8])
-
-		switch name {
-		case Mp4MetaAtom:
-			bytes = bytes[4:]
-			size -= 4
-			parseMoovAtom(bytes[8:], mp4)
-		case Mp4MetaUpta, Mp4MetaIlst:
-			parseMoovAtom(bytes[8:], mp4)
-		default:
-			atomName, ok := atoms[name]
-			if ok {
-				parseAtomData(bytes[8:size], atomName, mp4)
+// readMp4Atoms - split data to atoms
+// [size (4)][name (4)][data] or [1 (4)][name (4)][size (8)][data].
+// Size 0 means that the atom lasts to the end of data.
+func readMp4Atoms(data []byte) ([]*mp4Atom, error) {
+	result := []*mp4Atom{}
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return nil, ErrIncorrectLength
+		}
+		size := uint64(binary.BigEndian.Uint32(data[0:4]))
+		atom := &mp4Atom{Name: string(data[4:8])}
+		headerSize := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return nil, ErrIncorrectLength
 			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			headerSize = 16
+			atom.Large = true
 		}
+		if size < headerSize || size > uint64(len(data)) {
+			return nil, ErrIncorrectLength
+		}
+		atom.Data = data[headerSize:size]
+		result = append(result, atom)
+		data = data[size:]
+	}
+	return result, nil
+}
 
-		bytes = bytes[size:]
+// readMp4FileAtoms - top level atoms, 'mdat' payload is read from the source on demand
+func readMp4FileAtoms(input io.ReadSeeker) ([]*mp4Atom, error) {
+	end, err := input.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
 
-		if len(bytes) == 0 {
-			break
+	result := []*mp4Atom{}
+	for offset := int64(0); offset < end; {
+		if end-offset < 8 {
+			return nil, ErrIncorrectLength
+		}
+		header, err := seekAndRead(input, offset, io.SeekStart, 8)
+		if err != nil {
+			return nil, err
+		}
+		size := int64(binary.BigEndian.Uint32(header[0:4]))
+		atom := &mp4Atom{Name: string(header[4:8])}
+		headerSize := int64(8)
+		switch size {
+		case 0:
+			size = end - offset
+		case 1:
+			if end-offset < 16 {
+				return nil, ErrIncorrectLength
+			}
+			header, err = seekAndRead(input, offset+8, io.SeekStart, 8)
+			if err != nil {
+				return nil, err
+			}
+			size = int64(binary.BigEndian.Uint64(header))
+			headerSize = 16
+			atom.Large = true
+		}
+		if size < headerSize || size > end-offset {
+			return nil, ErrIncorrectLength
+		}
+
+		if atom.Name == Mp4MdatAtom {
+			atom.region, err = readRegion(input, offset+headerSize, size-headerSize)
+		} else {
+			atom.Data, err = seekAndRead(input, offset+headerSize, io.SeekStart, int(size-headerSize))
+		}
+		if err != nil {
+			return nil, err
 		}
+		result = append(result, atom)
+		offset += size
 	}
+	return result, nil
 }
 
-func parseAtomData(bytes []byte, atomName string, mp4 *MP4) {
-	// TODO : different types
-	value := string(bytes[16:])
-	mp4.data[atomName] = value
+func serializeMp4Atoms(atoms []*mp4Atom) []byte {
+	output := new(bytes.Buffer)
+	for _, atom := range atoms {
+		_ = writeMp4Atom(output, atom)
+	}
+	return output.Bytes()
+}
+
+func writeMp4Atom(output io.Writer, atom *mp4Atom) error {
+	size := atom.size()
+	var header []byte
+	if atom.Large || size > 0xFFFFFFFF {
+		header = make([]byte, 16)
+		binary.BigEndian.PutUint32(header[0:4], 1)
+		binary.BigEndian.PutUint64(header[8:16], uint64(size))
+	} else {
+		header = make([]byte, 8)
+		binary.BigEndian.PutUint32(header[0:4], uint32(size))
+	}
+	copy(header[4:8], atom.Name)
+
+	_, err := output.Write(header)
+	if err != nil {
+		return err
+	}
+	return writeAudio(output, atom.Data, atom.region)
+}
 
-	datatype := binary.BigEndian.Uint32(bytes[8:12])
-	if datatype == 13 {
-		mp4.data[atomName] = AttachedPicture{
-			MIME: "image/jpeg",
-			Data: bytes[16:],
-		}
+// size - full atom size with header.
+func (atom *mp4Atom) size() int64 {
+	size := audioSection(atom.Data, atom.region).Size() + 8
+	if atom.Large || size > 0xFFFFFFFF {
+		size += 8
 	}
+	return size
+}
 
-	if atomName == Mp4TagTrack || atomName == Mp4TagDisc {
-		mp4.data[atomName] = int(bytes[19:20][0])
-		mp4.data[atomName+"_TOTAL"] = int(bytes[21:22][0])
+func findMp4Atom(atoms []*mp4Atom, name string) *mp4Atom {
+	for _, atom := range atoms {
+		if atom.Name == name {
+			return atom
+		}
 	}
+	return nil
 }
@@ -0,0 +1,279 @@
+package tag
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+)
+
+/*
+Ogg page header:
+
+	capture_pattern          4 bytes "OggS"
+	stream_structure_version 1 byte, always 0
+	header_type_flag         1 byte
+	granule_position         8 bytes, little endian
+	bitstream_serial_number  4 bytes, little endian
+	page_sequence_number     4 bytes, little endian
+	CRC_checksum             4 bytes, little endian
+	number_page_segments     1 byte
+	segment_table            number_page_segments bytes (lacing values)
+*/
+const (
+	oggPageHeaderSize  = 27
+	oggMaxSegments     = 255
+	oggMaxSegmentSize  = 255
+	oggGranuleNoPacket = ^uint64(0) // no packet finishes on the page
+
+	oggContinued byte = 0x01 // page continues packet from previous page
+	oggBOS       byte = 0x02 // first page of logical bitstream
+)
+
+type oggPage struct {
+	HeaderType      byte
+	GranulePosition uint64
+	Serial          uint32
+	Sequence        uint32
+	Segments        []byte
+	Data            []byte
+}
+
+// oggCRCTable - crc32 with polynomial 0x04c11db7, without bit reflection
+var oggCRCTable = func() [256]uint32 {
+	var table [256]uint32
+	for i := range table {
+		crc := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}()
+
+func oggCRC(crc uint32, data []byte) uint32 {
+	for _, b := range data {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+func checkOggPage(input io.ReadSeeker) ([]byte, bool) {
+	header, err := seekAndRead(input, 0, io.SeekStart, oggPageHeaderSize)
+	if err != nil {
+		return nil, false
+	}
+	if string(header[0:4]) != OggIdentifier {
+		return nil, false
+	}
+
+	// first packet of the first page
+	segments, err := readBytes(input, int(header[26]))
+	if err != nil {
+		return nil, false
+	}
+	size := 0
+	for _, segment := range segments {
+		size += int(segment)
+		if segment < oggMaxSegmentSize {
+			break
+		}
+	}
+	packet, err := readBytes(input, size)
+	if err != nil {
+		return nil, false
+	}
+	return packet, true
+}
+
+func readOggPage(input io.Reader) (*oggPage, error) {
+	header, err := readBytes(input, oggPageHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	if string(header[0:4]) != OggIdentifier {
+		return nil, ErrFileMarker
+	}
+	if header[4] != 0 {
+		return nil, ErrUnsupportedFormat
+	}
+
+	page := oggPage{
+		HeaderType:      header[5],
+		GranulePosition: binary.LittleEndian.Uint64(header[6:14]),
+		Serial:          binary.LittleEndian.Uint32(header[14:18]),
+		Sequence:        binary.LittleEndian.Uint32(header[18:22]),
+	}
+
+	page.Segments, err = readBytes(input, int(header[26]))
+	if err != nil {
+		return nil, err
+	}
+
+	size := 0
+	for _, segment := range page.Segments {
+		size += int(segment)
+	}
+	page.Data, err = readBytes(input, size)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Write - write page with recomputed CRC
+func (page *oggPage) Write(w io.Writer) error {
+	header := make([]byte, oggPageHeaderSize, oggPageHeaderSize+len(page.Segments))
+	copy(header, OggIdentifier)
+	header[5] = page.HeaderType
+	binary.LittleEndian.PutUint64(header[6:14], page.GranulePosition)
+	binary.LittleEndian.PutUint32(header[14:18], page.Serial)
+	binary.LittleEndian.PutUint32(header[18:22], page.Sequence)
+	header[26] = byte(len(page.Segments))
+	header = append(header, page.Segments...)
+
+	crc := oggCRC(oggCRC(0, header), page.Data)
+	binary.LittleEndian.PutUint32(header[22:26], crc)
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(page.Data); err != nil {
+		return err
+	}
+	return nil
+}
+
+// readOggHeaderPackets - read first count packets of the logical bitstream.
+// Header packets must finish the page, audio data starts on a fresh page
+func readOggHeaderPackets(input io.Reader, count int) ([][]byte, uint32, error) {
+	var packets [][]byte
+	var packet []byte
+	var serial uint32
+
+	for pages := 0; len(packets) < count; pages++ {
+		page, err := readOggPage(input)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if pages == 0 {
+			if page.HeaderType&oggBOS == 0 {
+				return nil, 0, ErrIncorrectTag
+			}
+			serial = page.Serial
+		} else if page.Serial != serial {
+			// multiplexed streams are not supported
+			return nil, 0, ErrUnsupportedFormat
+		}
+
+		offset := 0
+		for _, segment := range page.Segments {
+			if len(packets) == count {
+				return nil, 0, ErrIncorrectTag
+			}
+			packet = append(packet, page.Data[offset:offset+int(segment)]...)
+			offset += int(segment)
+			if segment < oggMaxSegmentSize {
+				packets = append(packets, packet)
+				packet = nil
+			}
+		}
+	}
+
+	return packets, serial, nil
+}
+
+// paginateOggPackets - split packets into pages starting with sequence number
+func paginateOggPackets(packets [][]byte, serial uint32, sequence uint32, headerType byte) []*oggPage {
+	var result []*oggPage
+	page := &oggPage{
+		HeaderType:      headerType,
+		GranulePosition: oggGranuleNoPacket,
+		Serial:          serial,
+		Sequence:        sequence,
+	}
+
+	for _, packet := range packets {
+		// lacing values: 255 for full segments, last one less than 255 (may be 0)
+		for offset := 0; ; offset += oggMaxSegmentSize {
+			if len(page.Segments) == oggMaxSegments {
+				result = append(result, page)
+				sequence++
+				page = &oggPage{
+					GranulePosition: oggGranuleNoPacket,
+					Serial:          serial,
+					Sequence:        sequence,
+				}
+				if offset > 0 {
+					page.HeaderType = oggContinued
+				}
+			}
+
+			size := len(packet) - offset
+			if size >= oggMaxSegmentSize {
+				page.Segments = append(page.Segments, oggMaxSegmentSize)
+				page.Data = append(page.Data, packet[offset:offset+oggMaxSegmentSize]...)
+				continue
+			}
+
+			page.Segments = append(page.Segments, byte(size))
+			page.Data = append(page.Data, packet[offset:]...)
+			// header packets have granule position 0
+			page.GranulePosition = 0
+			break
+		}
+	}
+
+	if len(page.Segments) > 0 {
+		result = append(result, page)
+	}
+	return result
+}
+
+// writeOggPages - write header pages followed by audio pages.
+// Audio pages of the logical bitstream are renumbered to follow header pages,
+// they are read from the source page by page
+func writeOggPages(output io.Writer, headers []*oggPage, data []byte, region *fileRegion) error {
+	if len(headers) == 0 {
+		return ErrWriting
+	}
+
+	for _, page := range headers {
+		if err := page.Write(output); err != nil {
+			return err
+		}
+	}
+
+	// renumbered pages have the same size
+	err := recordRegion(output, data, region)
+	if err != nil {
+		return err
+	}
+
+	serial := headers[0].Serial
+	sequence := headers[len(headers)-1].Sequence + 1
+	audio := audioSection(data, region)
+	reader := bufio.NewReaderSize(audio, audioCopyBufferSize)
+	for offset := int64(0); offset < audio.Size(); {
+		page, err := readOggPage(reader)
+		if err != nil {
+			// keep unknown trailing data as is
+			return writeSection(output, io.NewSectionReader(audio, offset, audio.Size()-offset))
+		}
+		offset += int64(oggPageHeaderSize + len(page.Segments) + len(page.Data))
+
+		if page.Serial == serial {
+			page.Sequence = sequence
+			sequence++
+		}
+		if err = page.Write(output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package tag
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// OggVorbis - vorbis comments of the Ogg Vorbis stream
+type OggVorbis struct {
+	// Vorbis header packets
+	Identification []byte
+	Setup          []byte
+	Serial         uint32
+
+	// Vorbis Comment
+	VorbisComments
+
+	// Data - audio pages, nil if they are read from the source on demand
+	Data  []byte
+	audio *fileRegion
+}
+
+func (ogg *OggVorbis) GetVersion() Version {
+	return VersionOggVorbis
+}
+
+func (ogg *OggVorbis) GetFileData() []byte {
+	return readSection(audioSection(ogg.Data, ogg.audio))
+}
+
+func (ogg *OggVorbis) AudioReader() io.Reader {
+	return audioSection(ogg.Data, ogg.audio)
+}
+
+func (ogg *OggVorbis) SaveFile(path string) error {
+	return saveFile(path, ogg.Save, SaveOptions{})
+}
+
+// Save - write header packets with new comments.
+// Comment and setup headers are repaginated, sequence numbers of the audio pages
+// are shifted and all page CRCs are recomputed
+func (ogg *OggVorbis) Save(input io.WriteSeeker) error {
+	comment := append([]byte{vorbisCommentHeader}, vorbisIdentifier...)
+	comment = append(comment, serializeVorbisComments(ogg.Tags, ogg.Vendor)...)
+	comment = append(comment, 1) // framing bit
+
+	pages := paginateOggPackets([][]byte{ogg.Identification}, ogg.Serial, 0, oggBOS)
+	pages = append(pages, paginateOggPackets([][]byte{comment, ogg.Setup}, ogg.Serial, 1, 0)...)
+
+	return writeOggPages(input, pages, ogg.Data, ogg.audio)
+}
+
+func checkOggVorbis(input io.ReadSeeker) bool {
+	packet, ok := checkOggPage(input)
+	if !ok {
+		return false
+	}
+	return bytes.HasPrefix(packet, append([]byte{vorbisIdentificationHeader}, vorbisIdentifier...))
+}
+
+func ReadOggVorbis(input io.ReadSeeker) (*OggVorbis, error) {
+	ogg := OggVorbis{
+		VorbisComments: VorbisComments{Tags: map[string][]string{}},
+	}
+
+	_, err := input.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	// identification, comment and setup headers
+	packets, serial, err := readOggHeaderPackets(input, 3)
+	if err != nil {
+		return nil, err
+	}
+	ogg.Serial = serial
+
+	for i, packetType := range []byte{vorbisIdentificationHeader, vorbisCommentHeader, vorbisSetupHeader} {
+		if len(packets[i]) < 7 || packets[i][0] != packetType || string(packets[i][1:7]) != vorbisIdentifier {
+			return nil, ErrIncorrectTag
+		}
+	}
+	ogg.Identification = packets[0]
+	ogg.Setup = packets[2]
+
+	comments, vendor, err := readVorbisComments(bytes.NewReader(packets[1][7:]))
+	if err != nil {
+		return nil, err
+	}
+
+	ogg.Vendor = vendor
+	for i := range comments {
+		// case insensitive
+		field := strings.ToUpper(comments[i].Name)
+		ogg.Tags[field] = append(ogg.Tags[field], comments[i].Value)
+	}
+
+	// audio pages after header packets
+	ogg.audio, err = readerRegion(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ogg, nil
+}
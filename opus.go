@@ -0,0 +1,174 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Opus - comments of the Ogg Opus stream
+type Opus struct {
+	// OpusHead packet
+	Head   []byte
+	Serial uint32
+
+	// OpusTags
+	VorbisComments
+	// Binary data after comments, kept when its first byte has the least-significant bit set
+	Extra []byte
+
+	// Data - audio pages, nil if they are read from the source on demand
+	Data  []byte
+	audio *fileRegion
+}
+
+func (opus *Opus) GetVersion() Version {
+	return VersionOpus
+}
+
+func (opus *Opus) GetFileData() []byte {
+	return readSection(audioSection(opus.Data, opus.audio))
+}
+
+func (opus *Opus) AudioReader() io.Reader {
+	return audioSection(opus.Data, opus.audio)
+}
+
+func (opus *Opus) SaveFile(path string) error {
+	return saveFile(path, opus.Save, SaveOptions{})
+}
+
+// Save - write OpusHead and repaginated OpusTags packets,
+// sequence numbers of the audio pages are shifted and all page CRCs are recomputed
+func (opus *Opus) Save(input io.WriteSeeker) error {
+	tags := append([]byte(opusTagsIdentifier), serializeVorbisComments(opus.Tags, opus.Vendor)...)
+	tags = append(tags, opus.Extra...)
+
+	pages := paginateOggPackets([][]byte{opus.Head}, opus.Serial, 0, oggBOS)
+	pages = append(pages, paginateOggPackets([][]byte{tags}, opus.Serial, 1, 0)...)
+
+	return writeOggPages(input, pages, opus.Data, opus.audio)
+}
+
+func checkOpus(input io.ReadSeeker) bool {
+	packet, ok := checkOggPage(input)
+	if !ok {
+		return false
+	}
+	return bytes.HasPrefix(packet, []byte(opusHeadIdentifier))
+}
+
+func ReadOpus(input io.ReadSeeker) (*Opus, error) {
+	opus := Opus{
+		VorbisComments: VorbisComments{Tags: map[string][]string{}},
+	}
+
+	_, err := input.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	// identification and comment headers
+	packets, serial, err := readOggHeaderPackets(input, 2)
+	if err != nil {
+		return nil, err
+	}
+	opus.Serial = serial
+
+	// OpusHead is at least 19 bytes
+	if len(packets[0]) < 19 || string(packets[0][0:8]) != opusHeadIdentifier {
+		return nil, ErrIncorrectTag
+	}
+	if !bytes.HasPrefix(packets[1], []byte(opusTagsIdentifier)) {
+		return nil, ErrIncorrectTag
+	}
+	opus.Head = packets[0]
+
+	reader := bytes.NewReader(packets[1][len(opusTagsIdentifier):])
+	comments, vendor, err := readVorbisComments(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	opus.Vendor = vendor
+	for i := range comments {
+		// case insensitive
+		field := strings.ToUpper(comments[i].Name)
+		opus.Tags[field] = append(opus.Tags[field], comments[i].Value)
+	}
+
+	// padding is dropped, other data is preserved
+	extra := packets[1][len(packets[1])-reader.Len():]
+	if len(extra) > 0 && extra[0]&1 == 1 {
+		opus.Extra = extra
+	}
+
+	// audio pages after header packets
+	opus.audio, err = readerRegion(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &opus, nil
+}
+
+// GetOutputGain - OpusHead output gain in Q7.8 format (1/256 dB)
+func (opus *Opus) GetOutputGain() (int, error) {
+	if len(opus.Head) < 19 {
+		return 0, ErrIncorrectLength
+	}
+	return int(int16(binary.LittleEndian.Uint16(opus.Head[16:18]))), nil
+}
+
+// SetOutputGain - set OpusHead output gain in Q7.8 format (1/256 dB)
+func (opus *Opus) SetOutputGain(gain int) error {
+	if len(opus.Head) < 19 {
+		return ErrIncorrectLength
+	}
+	if gain < math.MinInt16 || gain > math.MaxInt16 {
+		return ErrIncorrectValue
+	}
+	binary.LittleEndian.PutUint16(opus.Head[16:18], uint16(int16(gain)))
+	return nil
+}
+
+// GetTrackGain - R128_TRACK_GAIN in Q7.8 format, relative to the output gain
+func (opus *Opus) GetTrackGain() (int, error) {
+	return opus.GetVorbisCommentInt(opusTrackGainTag)
+}
+
+// SetTrackGain - R128_TRACK_GAIN in Q7.8 format, relative to the output gain
+func (opus *Opus) SetTrackGain(gain int) error {
+	if gain < math.MinInt16 || gain > math.MaxInt16 {
+		return ErrIncorrectValue
+	}
+	opus.Tags[opusTrackGainTag] = []string{strconv.Itoa(gain)}
+	return nil
+}
+
+func (opus *Opus) DeleteTrackGain() error {
+	delete(opus.Tags, opusTrackGainTag)
+	return nil
+}
+
+// GetAlbumGain - R128_ALBUM_GAIN in Q7.8 format, relative to the output gain
+func (opus *Opus) GetAlbumGain() (int, error) {
+	return opus.GetVorbisCommentInt(opusAlbumGainTag)
+}
+
+// SetAlbumGain - R128_ALBUM_GAIN in Q7.8 format, relative to the output gain
+func (opus *Opus) SetAlbumGain(gain int) error {
+	if gain < math.MinInt16 || gain > math.MaxInt16 {
+		return ErrIncorrectValue
+	}
+	opus.Tags[opusAlbumGainTag] = []string{strconv.Itoa(gain)}
+	return nil
+}
+
+func (opus *Opus) DeleteAlbumGain() error {
+	delete(opus.Tags, opusAlbumGainTag)
+	return nil
+}
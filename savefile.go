@@ -0,0 +1,260 @@
+package tag
+
+import (
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// SaveOptions - options of SaveFileWithOptions
+type SaveOptions struct {
+	KeepModTime bool // keep modification time of the replaced file
+	Backup      bool // keep the replaced file as path + ".bak"
+
+	// CopyOnChownError - if owner of the replaced file can't be kept, copy the new file over it
+	// instead of failing. Copying isn't atomic, the file is left half-written if it fails
+	CopyOnChownError bool
+}
+
+// SaveFileWithOptions - SaveFile with options
+func SaveFileWithOptions(metadata Metadata, path string, options SaveOptions) error {
+	return saveFile(path, metadata.Save, options)
+}
+
+// savedFile - temporary file written by saveFile and positions of audio regions in it
+type savedFile struct {
+	*os.File
+	regions []savedRegion
+}
+
+type savedRegion struct {
+	region   *fileRegion
+	position int64
+}
+
+// writeAudio - copy audio to output, position of region in file written by saveFile is recorded
+func writeAudio(output io.Writer, data []byte, region *fileRegion) error {
+	err := recordRegion(output, data, region)
+	if err != nil {
+		return err
+	}
+	return writeSection(output, audioSection(data, region))
+}
+
+// recordRegion - record position of region in file written by saveFile,
+// data of the same size as region must be written at this position
+func recordRegion(output io.Writer, data []byte, region *fileRegion) error {
+	file, ok := output.(*savedFile)
+	if !ok || data != nil || region == nil {
+		return nil
+	}
+	position, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	file.regions = append(file.regions, savedRegion{region: region, position: position})
+	return nil
+}
+
+// saveFile - write file to a temporary file in the same directory, sync it and rename it over path.
+// Mode bits and owner of the replaced file are kept, error is returned if owner can't be kept.
+// With CopyOnChownError the temporary file is copied over the replaced file instead,
+// it's left if copying fails. Audio regions of the replaced file are moved to the new file
+// nolint:gocyclo
+func saveFile(path string, save func(io.WriteSeeker) error, options SaveOptions) error {
+	// replace target of symlink
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	exists := err == nil
+
+	temp, err := createTempFile(path)
+	if err != nil {
+		return err
+	}
+	file := &savedFile{File: temp}
+	keepTemp := false
+	defer func() {
+		file.Close()
+		if !keepTemp {
+			os.Remove(temp.Name())
+		}
+	}()
+
+	err = save(file)
+	if err != nil {
+		return err
+	}
+	err = file.Sync()
+	if err != nil {
+		return err
+	}
+
+	// regions of the replaced file
+	var moved []savedRegion
+	replace := true
+	if exists {
+		for _, saved := range file.regions {
+			if saved.region.sameFile(info) {
+				moved = append(moved, saved)
+			}
+		}
+
+		// owner first, it can reset mode bits
+		err = chownFile(file.File, info)
+		if err != nil && !options.CopyOnChownError {
+			return err
+		}
+		replace = err == nil
+		if replace {
+			err = file.Chmod(info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky))
+			if err != nil {
+				return err
+			}
+		}
+
+		if options.Backup {
+			err = backupFile(path, replace)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if replace {
+		err = file.Close()
+		if err != nil {
+			return err
+		}
+		err = os.Rename(temp.Name(), path)
+		if err != nil {
+			return err
+		}
+		syncDir(filepath.Dir(path))
+	} else {
+		keepTemp = true
+		err = copyFile(file.File, path)
+		if err != nil {
+			return err
+		}
+		keepTemp = false
+	}
+
+	if exists && options.KeepModTime {
+		err = os.Chtimes(path, time.Now(), info.ModTime())
+		if err != nil {
+			return err
+		}
+	}
+
+	// moved regions are read from the new file
+	if len(moved) > 0 {
+		saved, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		source, err := newFileSource(saved)
+		if err != nil {
+			saved.Close()
+			return err
+		}
+		for _, region := range moved {
+			region.region.source = source
+			region.region.offset = region.position
+		}
+	}
+	return nil
+}
+
+// createTempFile - new file in directory of path, mode is set by umask like os.Create
+func createTempFile(path string) (*os.File, error) {
+	dir, name := filepath.Split(path)
+	for i := 0; i < 10000; i++ {
+		temp := filepath.Join(dir, "."+name+"."+strconv.Itoa(int(rand.Int31()))+".tmp")
+		file, err := os.OpenFile(temp, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) {
+			continue
+		}
+		return file, err
+	}
+	return nil, ErrWriteFile
+}
+
+// backupFile - keep file as path + ".bak". Hard link is used if the file is replaced by rename,
+// the file copied over is copied
+func backupFile(path string, link bool) error {
+	backup := path + backupSuffix
+	err := os.Remove(backup)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if link && os.Link(path, backup) == nil {
+		return nil
+	}
+
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	info, err := source.Stat()
+	if err != nil {
+		return err
+	}
+	output, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(output, source)
+	if err == nil {
+		err = output.Sync()
+	}
+	closeErr := output.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// copyFile - copy file over path, file at path keeps its owner and mode
+func copyFile(file *os.File, path string) error {
+	_, err := file.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	output, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(output, file)
+	if err == nil {
+		err = output.Sync()
+	}
+	closeErr := output.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// syncDir - sync directory after rename, not supported on some systems
+func syncDir(path string) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	_ = dir.Sync()
+	dir.Close()
+}
@@ -0,0 +1,11 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package tag
+
+import "os"
+
+// chownFile - files have no owner to keep
+func chownFile(file *os.File, info os.FileInfo) error {
+	return nil
+}
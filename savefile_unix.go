@@ -0,0 +1,25 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package tag
+
+import (
+	"os"
+	"syscall"
+)
+
+// chownFile - set owner of the replaced file
+func chownFile(file *os.File, info os.FileInfo) error {
+	owner, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if current, ok := fileInfo.Sys().(*syscall.Stat_t); ok && current.Uid == owner.Uid && current.Gid == owner.Gid {
+		return nil
+	}
+	return file.Chown(int(owner.Uid), int(owner.Gid))
+}
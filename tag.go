@@ -10,16 +10,79 @@ func ReadFile(path string) (Metadata, error) {
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	source, err := newFileSource(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
 
-	return Read(file)
+	// audio data is read from the file on demand, it stays open while the result uses it
+	metadata, err := Read(&namedFile{File: file, source: source})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return metadata, nil
 }
 
+// Read - native tag of the container. Container with other tags around it is read
+// by ReadAll with the native tag first, MPEG audio and unknown data use ID3v2, APEv2 or ID3v1 tag
 func Read(input io.ReadSeeker) (Metadata, error) {
-	version := CheckVersion(input)
+	container, offset := detectContainer(input)
+	version := containerVersion(input, container, offset)
+	if version == VersionUndefined {
+		return readVersion(input, checkTagVersion(input))
+	}
+
+	if offset > 0 || checkID3v1(input) || checkAPEv2(input) || checkAppendedID3v24(input) {
+		all, err := ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		all.Priority = []Version{version}
+		return all, nil
+	}
+	return readVersion(input, version)
+}
+
+// ReadOrCreate - same as Read, but MPEG audio without tags gets an empty tag of preferred version
+// (ID3v2.4 if VersionUndefined). FLAC and MP4 without tags are read with empty native tag
+func ReadOrCreate(input io.ReadSeeker, preferred Version) (Metadata, error) {
+	metadata, err := Read(input)
+	if err != ErrUnsupportedFormat || DetectContainer(input) != ContainerMPEG {
+		return metadata, err
+	}
+
+	_, err = input.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+	switch preferred {
+	case VersionUndefined, VersionID3v24:
+		return NewID3v24(input)
+	case VersionID3v23:
+		return NewID3v23(input)
+	case VersionAPEv2:
+		return NewAPEv2(input)
+	default:
+		return nil, ErrUnsupportedTag
+	}
+}
+
+func readVersion(input io.ReadSeeker, version Version) (Metadata, error) {
 	switch version {
 	case VersionID3v1:
 		return ReadID3v1(input)
+	case VersionAPEv2:
+		return ReadAPEv2(input)
+	case VersionWAV:
+		return ReadWAV(input)
+	case VersionAIFF:
+		return ReadAIFF(input)
+	case VersionMatroska:
+		return ReadMatroska(input)
+	case VersionDSF:
+		return ReadDSF(input)
 	case VersionID3v22:
 		return ReadID3v22(input)
 	case VersionID3v23:
@@ -30,12 +93,26 @@ func Read(input io.ReadSeeker) (Metadata, error) {
 		return ReadMp4(input)
 	case VersionFLAC:
 		return ReadFLAC(input)
+	case VersionOggVorbis:
+		return ReadOggVorbis(input)
+	case VersionOpus:
+		return ReadOpus(input)
 	default:
 		return nil, ErrUnsupportedFormat
 	}
 }
 
+// CheckVersion - native tag of the container, or tag of MPEG audio and unknown data
 func CheckVersion(input io.ReadSeeker) Version {
+	container, offset := detectContainer(input)
+	version := containerVersion(input, container, offset)
+	if version != VersionUndefined {
+		return version
+	}
+	return checkTagVersion(input)
+}
+
+func checkTagVersion(input io.ReadSeeker) Version {
 	if checkID3v24(input) {
 		return VersionID3v24
 	}
@@ -48,16 +125,12 @@ func CheckVersion(input io.ReadSeeker) Version {
 		return VersionID3v22
 	}
 
-	if checkID3v1(input) {
-		return VersionID3v1
+	if checkAPEv2(input) {
+		return VersionAPEv2
 	}
 
-	if checkMp4(input) {
-		return VersionMP4
-	}
-
-	if checkFLAC(input) {
-		return VersionFLAC
+	if checkID3v1(input) {
+		return VersionID3v1
 	}
 
 	return VersionUndefined
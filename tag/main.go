@@ -92,6 +92,55 @@ func main() {
 				return nil
 			},
 		},
+		cli.Command{
+			Name:      "convert",
+			ShortName: "c",
+			Usage:     "convert id3v2 tag to another version",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "input, in",
+					Usage: "path to input file",
+				},
+				cli.StringFlag{
+					Name:  "output, out",
+					Usage: "path to output file (default: input file)",
+				},
+				cli.StringFlag{
+					Name:  "version, v",
+					Value: "id3v2.4",
+					Usage: "target version: id3v2.3 or id3v2.4",
+				},
+			},
+
+			Action: func(c *cli.Context) error {
+				var version tag.Version
+				switch c.String("version") {
+				case "id3v2.3", "2.3":
+					version = tag.VersionID3v23
+				case "id3v2.4", "2.4":
+					version = tag.VersionID3v24
+				default:
+					return fmt.Errorf("unsupported version: %s", c.String("version"))
+				}
+
+				input := c.String("input")
+				metadata, err := tag.ReadFile(input)
+				if err != nil {
+					return err
+				}
+
+				converted, err := tag.ConvertID3v2(metadata, version)
+				if err != nil {
+					return err
+				}
+
+				output := c.String("output")
+				if output == "" {
+					output = input
+				}
+				return converted.SaveFile(output)
+			},
+		},
 	}
 
 	err := app.Run(os.Args)
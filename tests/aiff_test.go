@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"encoding/binary"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestAIFFRead(t *testing.T) {
+	asrt := assert.New(t)
+	aiff, err := tag.ReadFile("kitten.aiff")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.Equal(tag.VersionAIFF, aiff.GetVersion())
+	asrt.Equal(509, len(aiff.GetFileData()))
+
+	title, err := aiff.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("AIFF Cat", title)
+
+	artist, err := aiff.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cute Kitten", artist)
+
+	comment, err := aiff.GetComment()
+	asrt.NoError(err)
+	asrt.Equal("meow", comment)
+
+	// ID3 chunk
+	album, err := aiff.GetAlbum()
+	asrt.NoError(err)
+	asrt.Equal("CatAlbum", album)
+
+	composer, err := aiff.GetComposer()
+	asrt.NoError(err)
+	asrt.Equal("catcomposer", composer)
+}
+
+func TestAIFFWrite(t *testing.T) {
+	asrt := assert.New(t)
+	data, err := ioutil.ReadFile("kitten.aiff")
+	asrt.NoError(err)
+
+	aiff, err := tag.ReadAIFF(mustOpen(t, "kitten.aiff"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	// unchanged tags
+	out, err := ioutil.TempFile("", "aiffTst.aiff")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(aiff.SaveFile(out.Name()))
+
+	saved, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(data, saved)
+
+	asrt.NoError(aiff.SetTitle("AIFF Cat Running"))
+	asrt.NoError(aiff.SetCopyright("2019 cat"))
+	asrt.NoError(aiff.SetConductor("catconductor"))
+	asrt.NoError(aiff.DeleteArtist())
+	asrt.NoError(aiff.SaveFile(out.Name()))
+
+	// big endian FORM size and pad bytes
+	saved, err = ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(len(saved)-8, int(binary.BigEndian.Uint32(saved[4:8])))
+	asrt.Equal(0, len(saved)%2)
+
+	aiff2, err := tag.ReadAIFF(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(aiff.GetFileData(), aiff2.GetFileData())
+
+	title, err := aiff2.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("AIFF Cat Running", title)
+
+	copyright, err := aiff2.GetCopyright()
+	asrt.NoError(err)
+	asrt.Equal("2019 cat", copyright)
+
+	conductor, err := aiff2.GetConductor()
+	asrt.NoError(err)
+	asrt.Equal("catconductor", conductor)
+
+	_, err = aiff2.GetArtist()
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	// second annotation is kept
+	annotations := 0
+	for _, chunk := range aiff2.Chunks {
+		if chunk.ID == "ANNO" {
+			annotations++
+		}
+	}
+	asrt.Equal(2, annotations)
+}
+
+func aiffChunk(id string, data []byte) []byte {
+	result := make([]byte, 8, 8+len(data)+1)
+	copy(result, id)
+	binary.BigEndian.PutUint32(result[4:8], uint32(len(data)))
+	result = append(result, data...)
+	if len(data)%2 == 1 {
+		result = append(result, 0)
+	}
+	return result
+}
+
+func TestAIFFID3v22(t *testing.T) {
+	asrt := assert.New(t)
+	id3 := id3v2Tag(2, 0, []byte("TAL\x00\x00\x09\x00CatAlbum"))
+	chunks := append(aiffChunk("SSND", []byte("meow")), aiffChunk("ID3 ", id3)...)
+	data := append([]byte("FORM\x00\x00\x00\x00AIFF"), chunks...)
+	binary.BigEndian.PutUint32(data[4:8], uint32(len(data)-8))
+	out, err := ioutil.TempFile("", "aiffTst.aiff")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(ioutil.WriteFile(out.Name(), data, 0600))
+
+	aiff, err := tag.ReadAIFF(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(tag.VersionID3v22, aiff.ID3.GetVersion())
+	album, err := aiff.GetAlbum()
+	asrt.NoError(err)
+	asrt.Equal("CatAlbum", album)
+
+	// tag is saved to the same chunk
+	asrt.NoError(aiff.SetAlbum("KittenAlbum"))
+	asrt.NoError(aiff.SaveFile(out.Name()))
+	aiff, err = tag.ReadAIFF(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	id3Chunks := 0
+	for _, chunk := range aiff.Chunks {
+		if chunk.ID == "ID3 " {
+			id3Chunks++
+		}
+	}
+	asrt.Equal(1, id3Chunks)
+	album, err = aiff.GetAlbum()
+	asrt.NoError(err)
+	asrt.Equal("KittenAlbum", album)
+	asrt.Equal([]byte("meow"), aiff.GetFileData())
+}
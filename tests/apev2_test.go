@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestAPEv2Read(t *testing.T) {
+	asrt := assert.New(t)
+	metadata, err := tag.ReadFile("apev2.mp3")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.Equal(tag.VersionAPEv2, metadata.GetVersion())
+
+	title, err := metadata.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("APE Cat", title)
+
+	artist, err := metadata.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cute Kitten", artist)
+
+	year, err := metadata.GetYear()
+	asrt.NoError(err)
+	asrt.Equal(2019, year)
+
+	number, total, err := metadata.GetTrackNumber()
+	asrt.NoError(err)
+	asrt.Equal(3, number)
+	asrt.Equal(12, total)
+
+	picture, err := metadata.GetPicture()
+	asrt.NoError(err)
+	if err == nil {
+		asrt.Equal(2, picture.Bounds().Dx())
+		asrt.Equal(3, picture.Bounds().Dy())
+	}
+
+	ape, ok := metadata.(*tag.APEv2)
+	asrt.True(ok)
+	if !ok {
+		return
+	}
+
+	// keys are case insensitive
+	related, err := ape.GetItem("RELATED")
+	asrt.NoError(err)
+	if err == nil {
+		asrt.Equal(tag.APEv2ItemLocator, related.Type)
+		asrt.Equal("http://example.com/cat", string(related.Value))
+	}
+
+	// ID3v1 after APEv2 tag
+	id3v1, err := tag.ReadID3v1(mustOpen(t, "apev2.mp3"))
+	asrt.NoError(err)
+	if err == nil {
+		asrt.Equal("TITLE1234567890123456789012345", id3v1.Title)
+	}
+}
+
+func TestAPEv2Write(t *testing.T) {
+	asrt := assert.New(t)
+	data, err := ioutil.ReadFile("apev2.mp3")
+	asrt.NoError(err)
+
+	ape, err := tag.ReadAPEv2(mustOpen(t, "apev2.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	// unchanged tag
+	out, err := ioutil.TempFile("", "apeTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(ape.SaveFile(out.Name()))
+
+	saved, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(data, saved)
+
+	asrt.NoError(ape.SetTitle("APE Cat Running"))
+	asrt.NoError(ape.SetComposer("catcomposer"))
+	asrt.NoError(ape.DeletePicture())
+	asrt.NoError(ape.SaveFile(out.Name()))
+
+	ape2, err := tag.ReadAPEv2(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(ape.GetFileData(), ape2.GetFileData())
+
+	title, err := ape2.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("APE Cat Running", title)
+
+	composer, err := ape2.GetComposer()
+	asrt.NoError(err)
+	asrt.Equal("catcomposer", composer)
+
+	_, err = ape2.GetPicture()
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	// ID3v1 is untouched by APEv2 save
+	id3v1, err := tag.ReadID3v1(mustOpen(t, out.Name()))
+	asrt.NoError(err)
+	if err != nil {
+		return
+	}
+	asrt.Equal("TITLE1234567890123456789012345", id3v1.Title)
+
+	// and APEv2 is untouched by ID3v1 save
+	asrt.NoError(id3v1.SetTitle("ID3v1 Cat"))
+	out2, err := ioutil.TempFile("", "apeTst2.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out2.Name())
+	asrt.NoError(id3v1.SaveFile(out2.Name()))
+
+	ape3, err := tag.ReadAPEv2(mustOpen(t, out2.Name()))
+	asrt.NoError(err)
+	if err != nil {
+		return
+	}
+	title, err = ape3.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("APE Cat Running", title)
+
+	id3v12, err := tag.ReadID3v1(mustOpen(t, out2.Name()))
+	asrt.NoError(err)
+	if err == nil {
+		asrt.Equal("ID3v1 Cat", id3v12.Title)
+	}
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"bytes"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// commentFrame - COMM frame in UTF-8
+func commentFrame(language string, description string, text string) []byte {
+	return id3v2Frame("COMM", 0, []byte("\x03"+language+description+"\x00"+text))
+}
+
+func TestID3v24Comments(t *testing.T) {
+	asrt := assert.New(t)
+	norm := " 00000A3C 00000A3C 00003F4B"
+	frames := append(commentFrame("eng", "iTunNORM", norm), commentFrame("eng", "", "Meow")...)
+	frames = append(frames, commentFrame("deu", "", "Miau")...)
+	id3, err := tag.ReadID3v24(bytes.NewReader(id3v2Tag(4, 0, frames)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	comment, err := id3.GetComment()
+	asrt.NoError(err)
+	asrt.Equal("Meow", comment)
+	comment, err = id3.GetCommentByKey("deu", "")
+	asrt.NoError(err)
+	asrt.Equal("Miau", comment)
+	comment, err = id3.GetCommentByKey("", "iTunNORM")
+	asrt.NoError(err)
+	asrt.Equal(norm, comment)
+	_, err = id3.GetCommentByKey("fra", "")
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	// service data is kept
+	asrt.NoError(id3.SetComment("Purr"))
+	asrt.NoError(id3.SetCommentByKey(tag.Comment{Language: "deu", Text: "Schnurr"}))
+	asrt.NoError(id3.SetCommentByKey(tag.Comment{Language: "fra", Description: "Chat", Text: "Ронрон"}))
+	asrt.Equal(tag.ErrIncorrectLength, id3.SetCommentByKey(tag.Comment{Language: "en", Text: "Purr"}))
+	out, err := ioutil.TempFile("", "commentsTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(id3.SaveFile(out.Name()))
+
+	id3, err = tag.ReadID3v24(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal([]tag.Comment{
+		{Language: "eng", Description: "iTunNORM", Text: norm},
+		{Language: "eng", Text: "Purr"},
+		{Language: "deu", Text: "Schnurr"},
+		{Language: "fra", Description: "Chat", Text: "Ронрон"},
+	}, id3.Comments())
+
+	asrt.NoError(id3.DeleteComment())
+	asrt.NoError(id3.DeleteCommentByKey("fra", "Chat"))
+	asrt.Equal([]tag.Comment{{Language: "eng", Description: "iTunNORM", Text: norm}}, id3.Comments())
+	_, err = id3.GetComment()
+	asrt.Equal(tag.ErrTagNotFound, err)
+}
+
+func TestID3v2CommentFrame(t *testing.T) {
+	asrt := assert.New(t)
+	frames := id3v2Frame("TIT2", 0, []byte{0, 'C', 'a', 't'})
+
+	// language and description are written
+	id3v23, err := tag.ReadID3v23(bytes.NewReader(id3v2Tag(3, 0, frames)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(id3v23.SetComment("Meow"))
+	value, err := id3v23.GetBytes("COMM")
+	asrt.NoError(err)
+	asrt.Equal([]byte("\x00eng\x00Meow"), value)
+	asrt.NoError(id3v23.SetCommentByKey(tag.Comment{Description: "Кот", Text: "Мяу"}))
+	comment, err := id3v23.GetCommentByKey("XXX", "Кот")
+	asrt.NoError(err)
+	asrt.Equal("Мяу", comment)
+
+	id3v22, err := tag.ReadID3v22(mustOpen(t, "id3v2.2.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(id3v22.SetCommentByKey(tag.Comment{Language: "eng", Description: "iTunNORM", Text: "0"}))
+	asrt.NoError(id3v22.SetComment("Meow"))
+	asrt.NoError(id3v22.DeleteComment())
+	comment, err = id3v22.GetCommentByKey("eng", "iTunNORM")
+	asrt.NoError(err)
+	asrt.Equal("0", comment)
+	_, err = id3v22.GetComment()
+	asrt.Equal(tag.ErrTagNotFound, err)
+}
+
+func TestVorbisMp4Comments(t *testing.T) {
+	asrt := assert.New(t)
+	flac, err := tag.ReadFLAC(mustOpen(t, "v1.flac"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(flac.DeleteComment())
+	asrt.NoError(flac.SetValues("DESCRIPTION", []string{"Meow"}))
+	comment, err := flac.GetComment()
+	asrt.NoError(err)
+	asrt.Equal("Meow", comment)
+	asrt.NoError(flac.SetComment("Purr"))
+	asrt.Equal([]tag.Comment{{Text: "Purr"}, {Text: "Meow"}}, flac.Comments())
+
+	mp4, err := tag.ReadMp4(mustOpen(t, "cat_walking.mp4"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(mp4.SetValues(tag.Mp4TagComment, []string{"Meow", "Purr"}))
+	asrt.Equal([]tag.Comment{{Text: "Meow"}, {Text: "Purr"}}, mp4.Comments())
+}
@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadAll(t *testing.T) {
+	asrt := assert.New(t)
+	all, err := tag.ReadAll(mustOpen(t, "alltags.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	var versions []tag.Version
+	for _, fileTag := range all.Tags {
+		versions = append(versions, fileTag.Version)
+	}
+	asrt.Equal([]tag.Version{tag.VersionID3v24, tag.VersionAPEv2, tag.VersionLyrics3, tag.VersionID3v1}, versions)
+	if len(all.Tags) != 4 {
+		return
+	}
+
+	// byte ranges
+	asrt.Equal(int64(0), all.Tags[0].Offset)
+	asrt.Equal(int64(72), all.Tags[0].Size)
+	asrt.Equal(int64(1072), all.Tags[1].Offset)
+	asrt.Equal(all.Tags[1].Offset+all.Tags[1].Size, all.Tags[2].Offset)
+	asrt.Equal(all.Tags[2].Offset+all.Tags[2].Size, all.Tags[3].Offset)
+	asrt.Equal(int64(1363-128), all.Tags[3].Offset)
+	asrt.Equal(int64(128), all.Tags[3].Size)
+	asrt.Nil(all.Tags[2].Metadata)
+	asrt.Equal(1000, len(all.GetFileData()))
+
+	// file order by default
+	asrt.Equal(tag.VersionID3v24, all.GetVersion())
+	title, err := all.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("ID3 Cat", title)
+
+	album, err := all.GetAlbum()
+	asrt.NoError(err)
+	asrt.Equal("CatAlbum", album)
+
+	all.Priority = []tag.Version{tag.VersionID3v1}
+	title, err = all.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("V1 Cat", title)
+
+	all.Priority = []tag.Version{tag.VersionAPEv2, tag.VersionID3v1}
+	title, err = all.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("APE Cat", title)
+
+	// only in ID3v2
+	composer, err := all.GetComposer()
+	asrt.NoError(err)
+	asrt.Equal("catcomposer", composer)
+}
+
+func TestReadAllWrite(t *testing.T) {
+	asrt := assert.New(t)
+	all, err := tag.ReadAll(mustOpen(t, "alltags.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	// all tags, ID3v1 has no album artist
+	asrt.NoError(all.SetTitle("All Cat"))
+	asrt.NoError(all.SetAlbumArtist("CatAlbumArtist"))
+
+	// chosen tags
+	all.Targets = []tag.Version{tag.VersionID3v1}
+	asrt.NoError(all.SetArtist("Cute Dog"))
+
+	out, err := ioutil.TempFile("", "allTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(all.SaveFile(out.Name()))
+
+	data, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	last := all.Tags[len(all.Tags)-1]
+	asrt.Equal(int64(len(data)), last.Offset+last.Size)
+
+	all2, err := tag.ReadAll(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(all.GetFileData(), all2.GetFileData())
+	asrt.Equal(4, len(all2.Tags))
+
+	for _, fileTag := range all2.Tags {
+		if fileTag.Metadata == nil {
+			continue
+		}
+		title, err := fileTag.Metadata.GetTitle()
+		asrt.NoError(err)
+		asrt.Equal("All Cat", title, fileTag.Version.String())
+
+		artist, err := fileTag.Metadata.GetArtist()
+		switch fileTag.Version {
+		case tag.VersionID3v1:
+			asrt.NoError(err)
+			asrt.Equal("Cute Dog", artist)
+		case tag.VersionID3v24:
+			asrt.NoError(err)
+			asrt.Equal("Cute Kitten", artist)
+		}
+	}
+
+	albumArtist, err := all2.GetAlbumArtist()
+	asrt.NoError(err)
+	asrt.Equal("CatAlbumArtist", albumArtist)
+}
+
+func TestReadAllFLAC(t *testing.T) {
+	asrt := assert.New(t)
+	all, err := tag.ReadAll(mustOpen(t, "id3.flac"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	var versions []tag.Version
+	for _, fileTag := range all.Tags {
+		versions = append(versions, fileTag.Version)
+	}
+	asrt.Equal([]tag.Version{tag.VersionID3v23, tag.VersionFLAC}, versions)
+
+	title, err := all.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("ID3 Cat", title)
+
+	all.Priority = []tag.Version{tag.VersionFLAC}
+	title, err = all.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("FLAC Cat", title)
+
+	// artist is only in ID3v2
+	artist, err := all.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cute Kitten", artist)
+
+	asrt.NoError(all.SetTitle("All Cat"))
+	out, err := ioutil.TempFile("", "allTst.flac")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(all.SaveFile(out.Name()))
+
+	all2, err := tag.ReadAll(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(all.GetFileData(), all2.GetFileData())
+	for _, fileTag := range all2.Tags {
+		title, err := fileTag.Metadata.GetTitle()
+		asrt.NoError(err)
+		asrt.Equal("All Cat", title, fileTag.Version.String())
+	}
+}
+
+func TestReadAllWriteID3v1Limits(t *testing.T) {
+	asrt := assert.New(t)
+	all, err := tag.ReadAll(mustOpen(t, "alltags.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	// too long for ID3v1, it's truncated
+	title := strings.Repeat("L", 40)
+	asrt.NoError(all.SetTitle(title))
+	asrt.NoError(all.SetArtist(strings.Repeat("Кот", 10)))
+	asrt.NoError(all.SetGenre("Cat Genre"))
+
+	for _, fileTag := range all.Tags {
+		if fileTag.Metadata == nil {
+			continue
+		}
+		value, err := fileTag.Metadata.GetTitle()
+		asrt.NoError(err)
+		if fileTag.Version == tag.VersionID3v1 {
+			asrt.Equal(title[:30], value)
+			artist, err := fileTag.Metadata.GetArtist()
+			asrt.NoError(err)
+			asrt.Equal(strings.Repeat("Кот", 5), artist)
+			asrt.Equal(tag.Genre(255), fileTag.Metadata.(*tag.ID3v1).Genre)
+			continue
+		}
+		asrt.Equal(title, value, fileTag.Version.String())
+		genre, err := fileTag.Metadata.GetGenre()
+		asrt.NoError(err)
+		asrt.Equal("Cat Genre", genre, fileTag.Version.String())
+	}
+
+	// ID3v1 read alone keeps its limits
+	id3v1, err := tag.ReadID3v1(mustOpen(t, "alltags.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(tag.ErrIncorrectLength, id3v1.SetTitle(title))
+}
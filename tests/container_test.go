@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDetectContainer(t *testing.T) {
+	asrt := assert.New(t)
+	containers := map[string]tag.Container{
+		"meow_id2.4.mp3":  tag.ContainerMPEG,
+		"id3.flac":        tag.ContainerFLAC,
+		"v1.flac":         tag.ContainerFLAC,
+		"kitten.ogg":      tag.ContainerOgg,
+		"kitten.opus":     tag.ContainerOgg,
+		"cat_walking.mp4": tag.ContainerMP4,
+		"kitten.wav":      tag.ContainerWAV,
+		"kitten.aiff":     tag.ContainerAIFF,
+		"kitten.mka":      tag.ContainerMatroska,
+		"kitten.dsf":      tag.ContainerDSF,
+	}
+	for name, container := range containers {
+		asrt.Equal(container, tag.DetectContainer(mustOpen(t, name)), name)
+	}
+}
+
+func TestReadNativeTag(t *testing.T) {
+	asrt := assert.New(t)
+
+	// ID3v1 after FLAC stream
+	asrt.Equal(tag.VersionFLAC, tag.CheckVersion(mustOpen(t, "v1.flac")))
+	flac, err := tag.Read(mustOpen(t, "v1.flac"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(tag.VersionFLAC, flac.GetVersion())
+
+	title, err := flac.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("FLAC Cat", title)
+
+	// ID3v2 before FLAC stream
+	flac, err = tag.Read(mustOpen(t, "id3.flac"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(tag.VersionFLAC, flac.GetVersion())
+
+	title, err = flac.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("FLAC Cat", title)
+
+	// only in ID3v2
+	artist, err := flac.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cute Kitten", artist)
+}
@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"bytes"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestConvertID3v22ToID3v24(t *testing.T) {
+	asrt := assert.New(t)
+
+	id3, err := tag.ReadFile("id3v2.2.mp3")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	converted, err := tag.ConvertID3v2(id3, tag.VersionID3v24)
+	asrt.NoError(err)
+	if err != nil {
+		return
+	}
+
+	out, err := ioutil.TempFile("", "convertTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	err = converted.SaveFile(out.Name())
+	asrt.NoError(err)
+
+	id34, err := tag.ReadFile(out.Name())
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(tag.VersionID3v24, id34.GetVersion())
+	asrt.Equal(0, bytes.Compare(id3.GetFileData(), id34.GetFileData()))
+
+	title, err := id34.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("You Are The One", title)
+
+	date, err := id34.GetDate()
+	asrt.NoError(err)
+	asrt.Equal(2006, date.Year())
+
+	picture, err := id3.GetPicture()
+	asrt.NoError(err)
+	picture2, err := id34.GetPicture()
+	asrt.NoError(err)
+	asrt.Equal(picture, picture2)
+}
+
+func TestConvertID3v24ToID3v23(t *testing.T) {
+	asrt := assert.New(t)
+
+	id3, err := tag.ReadFile("meow_id2.4.mp3")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	converted, err := tag.ConvertID3v2(id3, tag.VersionID3v23)
+	asrt.NoError(err)
+	if err != nil {
+		return
+	}
+	asrt.Equal(tag.VersionID3v23, converted.GetVersion())
+
+	date, err := converted.GetDate()
+	asrt.NoError(err)
+	asrt.Equal(time.Date(2008, time.September, 15, 15, 53, 0, 0, time.UTC), date)
+
+	// TIPL and TMCL are merged into IPLS
+	arranger, err := converted.GetArranger()
+	asrt.NoError(err)
+	asrt.Equal("CK\x00catperf", arranger)
+
+	// and back
+	id34, err := tag.ConvertID3v2(converted, tag.VersionID3v24)
+	asrt.NoError(err)
+	if err != nil {
+		return
+	}
+
+	date, err = id34.GetDate()
+	asrt.NoError(err)
+	asrt.Equal(time.Date(2008, time.September, 15, 15, 53, 0, 0, time.UTC), date)
+
+	arranger, err = id34.GetArranger()
+	asrt.NoError(err)
+	asrt.Equal("CK\x00catperf", arranger)
+
+	_, err = tag.ConvertID3v2(id3, tag.VersionFLAC)
+	asrt.Equal(tag.ErrUnsupportedFormat, err)
+}
+
+func TestConvertID3v24Position(t *testing.T) {
+	asrt := assert.New(t)
+	data := append(append([]byte{}, appendedAudio...), appendedTag(false, textFrame("TIT2", "Appended Cat"))...)
+	data = append(data, id3v1Tag("Old Cat")...)
+	id3, err := tag.ReadID3v24(bytes.NewReader(data))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	// appended tag and ID3v1 tag stay at the end
+	converted, err := tag.ConvertID3v2(id3, tag.VersionID3v24)
+	asrt.NoError(err)
+	if err != nil {
+		return
+	}
+	asrt.True(converted.(*tag.ID3v24).AtEnd)
+	out, err := ioutil.TempFile("", "convertTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(converted.SaveFile(out.Name()))
+	raw, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(appendedAudio, raw[:len(appendedAudio)])
+	asrt.Equal(id3v1Tag("Old Cat"), raw[len(raw)-128:])
+
+	_, err = tag.ConvertID3v2(id3, tag.VersionID3v23)
+	asrt.Equal(tag.ErrUnsupportedFormat, err)
+
+	// tag chained by SEEK frame is left in audio
+	first := id3v2Tag(4, 0, append(textFrame("TALB", "First Album"), seekFrame(len(appendedAudio))...))
+	data = append(append(first, appendedAudio...), id3v2Tag(4, 0, textFrame("TIT2", "Chained Cat"))...)
+	data = append(data, appendedAudio...)
+	id3, err = tag.ReadID3v24(bytes.NewReader(data))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	_, err = tag.ConvertID3v2(id3, tag.VersionID3v23)
+	asrt.Equal(tag.ErrUnsupportedFormat, err)
+
+	// CRC of extended header
+	id3v23, err := tag.ReadID3v23(bytes.NewReader(id3v2Tag(3, 0, id3v2Frame("TIT2", 0, []byte("\x00Cat")))))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	id3v23.ExtendedHeader = &tag.ID3v23ExtendedHeader{HasCRC: true}
+	converted, err = tag.ConvertID3v2(id3v23, tag.VersionID3v24)
+	asrt.NoError(err)
+	if err != nil {
+		return
+	}
+	asrt.NotNil(converted.(*tag.ID3v24).ExtendedHeader)
+	asrt.True(converted.(*tag.ID3v24).ExtendedHeader.HasCRC)
+}
+
+func TestConvertID3v24EncodingToID3v23(t *testing.T) {
+	asrt := assert.New(t)
+	frames := append(textFrame("TPE1", "Cat\x00Kitten"), textFrame("TCOM", "Мурка")...)
+	frames = append(frames, id3v2Frame("WXXX", 0, []byte("\x03Кот\x00http://cat"))...)
+	frames = append(frames, id3v2Frame("USER", 0, []byte("\x03engМяу"))...)
+	frames = append(frames, id3v2Frame("GEOB", 0, []byte("\x03text/plain\x00cat.txt\x00Кот\x00meow"))...)
+	frames = append(frames, id3v2Frame("SYLT", 0, []byte("\x03eng\x02\x01\x00Мяу\x00\x00\x00\x00\x10"))...)
+	id3, err := tag.ReadID3v24(bytes.NewReader(id3v2Tag(4, 0, frames)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	converted, err := tag.ConvertID3v2(id3, tag.VersionID3v23)
+	asrt.NoError(err)
+	if err != nil {
+		return
+	}
+	id3v23 := converted.(*tag.ID3v23)
+
+	// multiple values are joined by "/"
+	artist, err := id3v23.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cat/Kitten", artist)
+	value, err := id3v23.GetBytes("TPE1")
+	asrt.NoError(err)
+	asrt.Equal([]byte("\x00Cat/Kitten"), value)
+
+	// UTF-8 is re-encoded to UTF-16 with BOM
+	utf16 := func(value string) []byte {
+		return tag.EncodeUTF16(value)
+	}
+	value, err = id3v23.GetBytes("TCOM")
+	asrt.NoError(err)
+	asrt.Equal(append([]byte{1}, utf16("Мурка")...), value)
+	value, err = id3v23.GetBytes("WXXX")
+	asrt.NoError(err)
+	asrt.Equal(append(append([]byte{1}, utf16("Кот")...), "\x00\x00http://cat"...), value)
+	value, err = id3v23.GetBytes("USER")
+	asrt.NoError(err)
+	asrt.Equal(append([]byte("\x01eng"), utf16("Мяу")...), value)
+	value, err = id3v23.GetBytes("GEOB")
+	asrt.NoError(err)
+	expected := append([]byte("\x01text/plain\x00"), utf16("cat.txt")...)
+	expected = append(append(append(expected, 0, 0), utf16("Кот")...), "\x00\x00meow"...)
+	asrt.Equal(expected, value)
+	value, err = id3v23.GetBytes("SYLT")
+	asrt.NoError(err)
+	expected = append(append([]byte("\x01eng\x02\x01"), utf16("")...), 0, 0)
+	expected = append(append(append(expected, utf16("Мяу")...), 0, 0), 0, 0, 0, 0x10)
+	asrt.Equal(expected, value)
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestReadOrCreateMPEG(t *testing.T) {
+	asrt := assert.New(t)
+	_, err := tag.Read(mustOpen(t, "raw.mp3"))
+	asrt.Equal(tag.ErrUnsupportedFormat, err)
+
+	// preferred version and created tag
+	versions := map[tag.Version]tag.Version{
+		tag.VersionUndefined: tag.VersionID3v24,
+		tag.VersionID3v23:    tag.VersionID3v23,
+		tag.VersionAPEv2:     tag.VersionAPEv2,
+	}
+	for preferred, version := range versions {
+		created, err := tag.ReadOrCreate(mustOpen(t, "raw.mp3"), preferred)
+		asrt.NoError(err, "open")
+		if err != nil {
+			return
+		}
+		asrt.Equal(version, created.GetVersion())
+		asrt.Equal(4096, len(created.GetFileData()))
+
+		_, err = created.GetTitle()
+		asrt.Equal(tag.ErrTagNotFound, err)
+		asrt.NoError(created.SetTitle("Raw Cat"))
+
+		out, err := ioutil.TempFile("", "rawTst.mp3")
+		asrt.NoError(err)
+		defer os.Remove(out.Name())
+		asrt.NoError(created.SaveFile(out.Name()))
+
+		saved, err := tag.ReadFile(out.Name())
+		asrt.NoError(err, "open")
+		if err != nil {
+			return
+		}
+		asrt.Equal(version, saved.GetVersion())
+		asrt.Equal(created.GetFileData(), saved.GetFileData())
+
+		title, err := saved.GetTitle()
+		asrt.NoError(err)
+		asrt.Equal("Raw Cat", title)
+	}
+
+	_, err = tag.ReadOrCreate(mustOpen(t, "raw.mp3"), tag.VersionFLAC)
+	asrt.Equal(tag.ErrUnsupportedTag, err)
+
+	// existing tag is read
+	id3, err := tag.ReadOrCreate(mustOpen(t, "meow_id2.4.mp3"), tag.VersionAPEv2)
+	asrt.NoError(err, "open")
+	if err == nil {
+		asrt.Equal(tag.VersionID3v24, id3.GetVersion())
+	}
+}
+
+func TestReadOrCreateFLAC(t *testing.T) {
+	asrt := assert.New(t)
+	flac, err := tag.ReadOrCreate(mustOpen(t, "raw.flac"), tag.VersionUndefined)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(tag.VersionFLAC, flac.GetVersion())
+	asrt.Empty(flac.GetAllTagNames())
+	asrt.NoError(flac.SetTitle("Raw Cat"))
+
+	out, err := ioutil.TempFile("", "rawTst.flac")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(flac.SaveFile(out.Name()))
+
+	saved, err := tag.ReadFLAC(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(flac.GetFileData(), saved.GetFileData())
+
+	// Vorbis comment right after STREAMINFO, padding stays last
+	data, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	streamInfo := 4 + 4 + 34
+	asrt.Equal(byte(tag.FlacVorbisComment), data[streamInfo]&0x7F)
+	asrt.Equal(tag.FlacPadding, saved.Blocks[len(saved.Blocks)-1].Type)
+
+	title, err := saved.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Raw Cat", title)
+
+	// new tags from scratch
+	empty, err := tag.NewFLACTags(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Empty(empty.GetAllTagNames())
+	asrt.Equal(flac.GetFileData(), empty.GetFileData())
+}
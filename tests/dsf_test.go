@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"encoding/binary"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDSFRead(t *testing.T) {
+	asrt := assert.New(t)
+	dsf, err := tag.ReadFile("kitten.dsf")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.Equal(tag.VersionDSF, dsf.GetVersion())
+	asrt.Equal(4096, len(dsf.GetFileData()))
+
+	title, err := dsf.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("DSF Cat", title)
+
+	artist, err := dsf.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cute Kitten", artist)
+
+	album, err := dsf.GetAlbum()
+	asrt.NoError(err)
+	asrt.Equal("CatAlbum", album)
+
+	composer, err := dsf.GetComposer()
+	asrt.NoError(err)
+	asrt.Equal("catcomposer", composer)
+}
+
+func TestDSFWrite(t *testing.T) {
+	asrt := assert.New(t)
+	dsf, err := tag.ReadDSF(mustOpen(t, "kitten.dsf"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(tag.VersionID3v23, dsf.ID3.GetVersion())
+
+	asrt.NoError(dsf.SetTitle("DSF Cat Running"))
+	asrt.NoError(dsf.SetComposer("dogcomposer"))
+	asrt.NoError(dsf.DeleteAlbum())
+
+	out, err := ioutil.TempFile("", "dsfTst.dsf")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(dsf.SaveFile(out.Name()))
+
+	// total size and metadata pointer
+	data, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(len(data), int(binary.LittleEndian.Uint64(data[12:20])))
+	pointer := int(binary.LittleEndian.Uint64(data[20:28]))
+	asrt.Equal("ID3", string(data[pointer:pointer+3]))
+
+	dsf2, err := tag.ReadFile(out.Name())
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(dsf.GetFileData(), dsf2.GetFileData())
+
+	title, err := dsf2.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("DSF Cat Running", title)
+
+	composer, err := dsf2.GetComposer()
+	asrt.NoError(err)
+	asrt.Equal("dogcomposer", composer)
+
+	_, err = dsf2.GetAlbum()
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	// no tag, zero metadata pointer
+	asrt.NoError(dsf2.DeleteAll())
+	asrt.NoError(dsf2.SaveFile(out.Name()))
+
+	data, err = ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(len(data), int(binary.LittleEndian.Uint64(data[12:20])))
+	asrt.Equal(uint64(0), binary.LittleEndian.Uint64(data[20:28]))
+
+	// new ID3v2.4 tag
+	dsf3, err := tag.ReadFile(out.Name())
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	_, err = dsf3.GetTitle()
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	asrt.NoError(dsf3.SetConductor("catconductor"))
+	asrt.NoError(dsf3.SaveFile(out.Name()))
+
+	dsf4, err := tag.ReadDSF(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(dsf.GetFileData(), dsf4.GetFileData())
+	asrt.Equal(tag.VersionID3v24, dsf4.ID3.GetVersion())
+
+	conductor, err := dsf4.GetConductor()
+	asrt.NoError(err)
+	asrt.Equal("catconductor", conductor)
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"bytes"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEncodingRead(t *testing.T) {
+	asrt := assert.New(t)
+
+	// ISO-8859-1
+	value, err := tag.GetString([]byte{0, 'C', 'a', 'f', 0xE9})
+	asrt.NoError(err)
+	asrt.Equal("Café", value)
+
+	// UTF-16 big endian BOM with surrogate pair
+	value, err = tag.GetString([]byte{1, 0xFE, 0xFF, 0x73, 0x2B, 0xD8, 0x3D, 0xDC, 0x31})
+	asrt.NoError(err)
+	asrt.Equal("猫🐱", value)
+
+	// UTF-16 little endian BOM
+	value, err = tag.GetString([]byte{1, 0xFF, 0xFE, 0x2B, 0x73, 0x3D, 0xD8, 0x31, 0xDC})
+	asrt.NoError(err)
+	asrt.Equal("猫🐱", value)
+
+	// frame of tag
+	frames := id3v2Frame("TIT2", 0, []byte{1, 0xFE, 0xFF, 0, 'C', 0, 'a', 0, 't', 0, 0xE9})
+	id3, err := tag.ReadID3v23(bytes.NewReader(id3v2Tag(3, 0, frames)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	title, err := id3.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Caté", title)
+}
+
+func TestEncodingWrite(t *testing.T) {
+	asrt := assert.New(t)
+	empty := id3v2Frame("TALB", 0, []byte{0, 'A'})
+
+	// ISO-8859-1 when representable, UTF-8 in ID3v2.4
+	id3v24, err := tag.ReadID3v24(bytes.NewReader(id3v2Tag(4, 0, empty)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(id3v24.SetTitle("Café"))
+	value, err := id3v24.GetBytes("TIT2")
+	asrt.NoError(err)
+	asrt.Equal([]byte{0, 'C', 'a', 'f', 0xE9}, value)
+	asrt.NoError(id3v24.SetTitle("猫"))
+	value, err = id3v24.GetBytes("TIT2")
+	asrt.NoError(err)
+	asrt.Equal(append([]byte{3}, "猫"...), value)
+
+	// UTF-16 in ID3v2.3
+	id3v23, err := tag.ReadID3v23(bytes.NewReader(id3v2Tag(3, 0, empty)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(id3v23.SetTitle("猫"))
+	value, err = id3v23.GetBytes("TIT2")
+	asrt.NoError(err)
+	asrt.Equal([]byte{1, 0xFF, 0xFE, 0x2B, 0x73}, value)
+	title, err := id3v23.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("猫", title)
+
+	// forced encoding
+	id3v24.Encoding = tag.EncodingUTF16BE
+	asrt.NoError(id3v24.SetTitle("Cat"))
+	value, err = id3v24.GetBytes("TIT2")
+	asrt.NoError(err)
+	asrt.Equal([]byte{2, 0, 'C', 0, 'a', 0, 't'}, value)
+
+	id3v23.Encoding = tag.EncodingUTF16
+	asrt.NoError(id3v23.SetStringTXXX("Cat", "Meow"))
+	txxx, err := id3v23.GetStringTXXX("Cat")
+	asrt.NoError(err)
+	asrt.Equal("Meow", txxx)
+
+	// ID3v2.3 doesn't support UTF-8
+	id3v23.Encoding = tag.EncodingUTF8
+	asrt.Equal(tag.ErrEncodingFormat, id3v23.SetTitle("猫"))
+	id3v24.Encoding = tag.EncodingISO88591
+	asrt.Equal(tag.ErrEncodingFormat, id3v24.SetTitle("猫"))
+}
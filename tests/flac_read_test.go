@@ -1,6 +1,8 @@
 package tests
 
 import (
+	"bytes"
+	"encoding/binary"
 	"github.com/frolovo22/tag"
 	"github.com/stretchr/testify/assert"
 	"image/png"
@@ -44,3 +46,45 @@ func TestFLACRead(t *testing.T) {
 	cmp := compareFiles("flac.png", out.Name())
 	asrt.Equal(true, cmp)
 }
+
+func TestFLACReadCommentLengths(t *testing.T) {
+	asrt := assert.New(t)
+
+	vector := func(length uint32, data string) []byte {
+		result := make([]byte, 4, 4+len(data))
+		binary.LittleEndian.PutUint32(result, length)
+		return append(result, data...)
+	}
+	flacFile := func(comment []byte) []byte {
+		data := []byte("fLaC")
+		data = append(data, 0x80|byte(tag.FlacVorbisComment), 0, byte(len(comment)>>8), byte(len(comment)))
+		return append(data, comment...)
+	}
+
+	// valid comment
+	comment := append(vector(3, "cat"), 1, 0, 0, 0)
+	comment = append(comment, vector(9, "TITLE=Cat")...)
+	flac, err := tag.ReadFLAC(bytes.NewReader(flacFile(comment)))
+	asrt.NoError(err)
+	if err == nil {
+		title, err := flac.GetTitle()
+		asrt.NoError(err)
+		asrt.Equal("Cat", title)
+	}
+
+	// vendor longer than block
+	_, err = tag.ReadFLAC(bytes.NewReader(flacFile(append(vector(0xFFFFFF00, "cat"), 0, 0, 0, 0))))
+	asrt.Equal(tag.ErrIncorrectLength, err)
+
+	// comment longer than block
+	comment = append(vector(3, "cat"), 1, 0, 0, 0)
+	comment = append(comment, vector(100, "TITLE=Cat")...)
+	_, err = tag.ReadFLAC(bytes.NewReader(flacFile(comment)))
+	asrt.Equal(tag.ErrIncorrectLength, err)
+
+	// more comments than block can hold
+	comment = append(vector(3, "cat"), 0xFF, 0xFF, 0xFF, 0xFF)
+	comment = append(comment, vector(9, "TITLE=Cat")...)
+	_, err = tag.ReadFLAC(bytes.NewReader(flacFile(comment)))
+	asrt.Equal(tag.ErrIncorrectLength, err)
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"bytes"
 	"github.com/frolovo22/tag"
 	"github.com/stretchr/testify/assert"
 	"image/png"
@@ -59,3 +60,54 @@ func TestId3v22Read(t *testing.T) {
 	asrt.Equal(1, trackNumber)
 	asrt.Equal(11, totalNumber)
 }
+
+func TestId3v22Unsynchronisation(t *testing.T) {
+	asrt := assert.New(t)
+	id3, err := tag.ReadID3v22(mustOpen(t, "id3v2.2_unsync.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.True(id3.Flags.IsUnsynchronisation())
+
+	title, err := id3.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Unsync Cat", title)
+
+	picture, err := id3.GetAttachedPicture()
+	asrt.NoError(err)
+	asrt.Equal("image/jpeg", picture.MIME)
+	img, err := id3.GetPicture()
+	asrt.NoError(err)
+	asrt.Equal(2, img.Bounds().Dx())
+	asrt.Equal(3, img.Bounds().Dy())
+
+	raw, err := ioutil.ReadFile("raw.mp3")
+	asrt.NoError(err)
+	asrt.Equal(raw, id3.GetFileData())
+
+	// saved unsynchronised
+	out, err := ioutil.TempFile("", "unsyncTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(id3.SaveFile(out.Name()))
+
+	data, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(byte(0x80), data[5])
+
+	id3v2, err := tag.ReadID3v22(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(raw, id3v2.GetFileData())
+	saved, err := id3v2.GetAttachedPicture()
+	asrt.NoError(err)
+	asrt.Equal(picture.Data, saved.Data)
+
+	// compression scheme isn't defined
+	data[5] = 0x40
+	_, err = tag.ReadID3v22(bytes.NewReader(data))
+	asrt.Equal(tag.ErrUnsupportedFormat, err)
+}
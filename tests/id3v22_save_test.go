@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"bytes"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestId3v22Save(t *testing.T) {
+	asrt := assert.New(t)
+
+	id3, err := tag.ReadFile("id3v2.2.mp3")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	err = id3.SaveFile("id3v2.2.save.mp3")
+	asrt.NoError(err, "save")
+	if err != nil {
+		return
+	}
+	defer os.Remove("id3v2.2.save.mp3")
+
+	cmp := compareFiles("id3v2.2.mp3", "id3v2.2.save.mp3")
+	asrt.True(cmp)
+}
+
+// nolint:funlen
+func TestId3v22Change(t *testing.T) {
+	asrt := assert.New(t)
+
+	id3, err := tag.ReadFile("id3v2.2.mp3")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.NoError(id3.SetTitle("Kitten"))
+	asrt.NoError(id3.SetAlbumArtist("Cats"))
+	asrt.NoError(id3.SetComment("meow"))
+	asrt.NoError(id3.SetDate(time.Date(2007, time.March, 4, 15, 30, 0, 0, time.UTC)))
+	asrt.NoError(id3.SetTrackNumber(2, 11))
+	asrt.NoError(id3.SetCatalogNumber("cat-01"))
+	asrt.NoError(id3.DeleteGenre())
+
+	out, err := ioutil.TempFile("", "id3v22Tst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	err = id3.SaveFile(out.Name())
+	asrt.NoError(err)
+
+	id32, err := tag.ReadFile(out.Name())
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(tag.VersionID3v22, id32.GetVersion())
+
+	// Check file main data is untouched
+	asrt.Equal(0, bytes.Compare(id3.GetFileData(), id32.GetFileData()))
+
+	title, err := id32.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Kitten", title)
+
+	artist, err := id32.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Shiny Toy Guns", artist)
+
+	albumArtist, err := id32.GetAlbumArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cats", albumArtist)
+
+	comment, err := id32.GetComment()
+	asrt.NoError(err)
+	asrt.Equal("meow", comment)
+
+	date, err := id32.GetDate()
+	asrt.NoError(err)
+	asrt.Equal(time.Date(2007, time.March, 4, 15, 30, 0, 0, time.UTC), date)
+
+	track, total, err := id32.GetTrackNumber()
+	asrt.NoError(err)
+	asrt.Equal(2, track)
+	asrt.Equal(11, total)
+
+	catalogNumber, err := id32.GetCatalogNumber()
+	asrt.NoError(err)
+	asrt.Equal("cat-01", catalogNumber)
+
+	_, err = id32.GetGenre()
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	_, err = id32.GetPicture()
+	asrt.NoError(err)
+}
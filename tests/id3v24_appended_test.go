@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"bytes"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+var appendedAudio = bytes.Repeat([]byte{0xFF, 0xFB, 0x90, 0x00}, 64)
+
+// appendedTag - ID3v2.4 tag with footer, update tag has extended header with update flag
+func appendedTag(update bool, frames []byte) []byte {
+	flags := byte(0x10)
+	var body []byte
+	if update {
+		flags |= 0x40
+		body = []byte{0, 0, 0, 7, 1, 0x40, 0}
+	}
+	data := id3v2Tag(4, flags, append(body, frames...))
+	return append(data, append([]byte("3DI"), data[3:10]...)...)
+}
+
+// id3v1Tag - ID3v1 tag with title
+func id3v1Tag(title string) []byte {
+	data := make([]byte, 128)
+	copy(data, "TAG")
+	copy(data[3:], title)
+	copy(data[93:], "2019")
+	return data
+}
+
+func textFrame(key string, value string) []byte {
+	return id3v2Frame(key, 0, append([]byte{3}, value...))
+}
+
+func seekFrame(offset int) []byte {
+	return id3v2Frame("SEEK", 0, []byte{byte(offset >> 24), byte(offset >> 16), byte(offset >> 8), byte(offset)})
+}
+
+func TestID3v24AppendedRead(t *testing.T) {
+	asrt := assert.New(t)
+	frames := append(textFrame("TIT2", "Appended Cat"), textFrame("TPE1", "Appended Kitten")...)
+	data := append(append([]byte{}, appendedAudio...), appendedTag(false, frames)...)
+	data = append(data, id3v1Tag("Old Cat")...)
+
+	asrt.Equal(tag.VersionID3v24, tag.CheckVersion(bytes.NewReader(data)))
+	id3, err := tag.ReadID3v24(bytes.NewReader(data))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.True(id3.AtEnd)
+	asrt.Equal(appendedAudio, id3.GetFileData())
+	title, err := id3.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Appended Cat", title)
+
+	// all tags of the file
+	all, err := tag.ReadAll(bytes.NewReader(data))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(2, len(all.Tags))
+	asrt.Equal(tag.VersionID3v24, all.Tags[0].Version)
+	asrt.Equal(tag.VersionID3v1, all.Tags[1].Version)
+	asrt.Equal(appendedAudio, all.GetFileData())
+}
+
+func TestID3v24SeekRead(t *testing.T) {
+	asrt := assert.New(t)
+
+	// update tag in the middle and at the end of the file
+	middle := appendedTag(true, textFrame("TPE1", "Middle Kitten"))
+	last := appendedTag(true, textFrame("TIT2", "Last Cat"))
+	first := id3v2Tag(4, 0, append(append(textFrame("TIT2", "First Cat"), textFrame("TALB", "First Album")...),
+		seekFrame(len(appendedAudio))...))
+
+	data := append(append([]byte{}, first...), appendedAudio...)
+	data = append(data, middle...)
+	data = append(data, appendedAudio...)
+	data = append(data, last...)
+
+	id3, err := tag.ReadID3v24(bytes.NewReader(data))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.False(id3.AtEnd)
+	_, err = id3.GetBytes("SEEK")
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	title, err := id3.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Last Cat", title)
+	artist, err := id3.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Middle Kitten", artist)
+	album, err := id3.GetAlbum()
+	asrt.NoError(err)
+	asrt.Equal("First Album", album)
+
+	// tag chained in audio is left in audio
+	audio := append(append(append([]byte{}, appendedAudio...), middle...), appendedAudio...)
+	asrt.Equal(audio, id3.GetFileData())
+
+	// tag which isn't an update replaces frames
+	first = id3v2Tag(4, 0, append(textFrame("TALB", "First Album"), seekFrame(len(appendedAudio))...))
+	data = append(append(first, appendedAudio...), appendedTag(false, textFrame("TIT2", "Last Cat"))...)
+	id3, err = tag.ReadID3v24(bytes.NewReader(data))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(appendedAudio, id3.GetFileData())
+	_, err = id3.GetAlbum()
+	asrt.Equal(tag.ErrTagNotFound, err)
+	title, err = id3.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Last Cat", title)
+}
+
+func TestID3v24AppendedWrite(t *testing.T) {
+	asrt := assert.New(t)
+	trailer := id3v1Tag("Old Cat")
+	data := append(append([]byte{}, appendedAudio...), appendedTag(false, textFrame("TIT2", "Appended Cat"))...)
+	data = append(data, trailer...)
+
+	out, err := ioutil.TempFile("", "appendedTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	_, err = out.Write(data)
+	asrt.NoError(err)
+
+	// tag is written back at the end
+	id3, err := tag.ReadID3v24(out)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(id3.SetArtist("Appended Kitten"))
+	asrt.NoError(id3.SaveInPlace(out))
+
+	raw, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(appendedAudio, raw[:len(appendedAudio)])
+	asrt.Equal(trailer, raw[len(raw)-len(trailer):])
+
+	id3, err = tag.ReadID3v24(out)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.True(id3.AtEnd)
+	asrt.Equal(appendedAudio, id3.GetFileData())
+	artist, err := id3.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Appended Kitten", artist)
+
+	// tag is moved to the start
+	id3.AtEnd = false
+	asrt.NoError(id3.SaveFile(out.Name()))
+	raw, err = ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal("ID3", string(raw[:3]))
+	asrt.Equal(append(append([]byte{}, appendedAudio...), trailer...), raw[len(raw)-len(appendedAudio)-len(trailer):])
+
+	// tag of MPEG file is moved to the end
+	mp3, err := tag.ReadID3v24(mustOpen(t, "meow_id2.4.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	audio := mp3.GetFileData()
+	mp3.AtEnd = true
+	asrt.NoError(mp3.SaveFile(out.Name()))
+	raw, err = ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(audio, raw[:len(audio)])
+
+	mp3, err = tag.ReadID3v24(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.True(mp3.AtEnd)
+	asrt.True(mp3.Flags.HasFooter())
+	asrt.Equal(audio, mp3.GetFileData())
+}
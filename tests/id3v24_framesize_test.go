@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"bytes"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// synchsafeFrame - frame with synchsafe size
+func synchsafeFrame(key string, data []byte) []byte {
+	length := len(data)
+	frame := append([]byte(key), byte(length>>21)&0x7F, byte(length>>14)&0x7F, byte(length>>7)&0x7F,
+		byte(length)&0x7F, 0, 0)
+	return append(frame, data...)
+}
+
+func TestID3v24FrameSize(t *testing.T) {
+	asrt := assert.New(t)
+	picture := append([]byte{0, 'i', 'm', 'a', 'g', 'e', '/', 'p', 'n', 'g', 0, 3, 0}, bytes.Repeat([]byte{0x89}, 300)...)
+	title := append([]byte{3}, "Broken Cat"...)
+
+	// plain sizes of broken tagger
+	frames := append(id3v2Frame("APIC", 0, picture), id3v2Frame("TIT2", 0, title)...)
+	data := append(id3v2Tag(4, 0, append(frames, make([]byte, 32)...)), 0xFF, 0xFB, 0x90, 0x00)
+	id3, err := tag.ReadID3v24(bytes.NewReader(data))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.True(id3.PlainFrameSizes)
+	value, err := id3.GetBytes("APIC")
+	asrt.NoError(err)
+	asrt.Equal(picture, value)
+	name, err := id3.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Broken Cat", name)
+
+	// synchsafe sizes
+	frames = append(synchsafeFrame("APIC", picture), synchsafeFrame("TIT2", title)...)
+	id3v2, err := tag.ReadID3v24(bytes.NewReader(id3v2Tag(4, 0, frames)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.False(id3v2.PlainFrameSizes)
+	value, err = id3v2.GetBytes("APIC")
+	asrt.NoError(err)
+	asrt.Equal(picture, value)
+
+	// sizes are written synchsafe
+	out, err := ioutil.TempFile("", "frameSizeTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(id3.SaveFile(out.Name()))
+	raw, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(synchsafeFrame("APIC", picture), raw[10:10+10+len(picture)])
+
+	id3, err = tag.ReadID3v24(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.False(id3.PlainFrameSizes)
+	name, err = id3.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Broken Cat", name)
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"bytes"
+	"compress/zlib"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// id3v2Tag - ID3v2 tag of version with frames and flags
+func id3v2Tag(version byte, flags byte, frames []byte) []byte {
+	length := len(frames)
+	header := []byte{'I', 'D', '3', version, 0, flags,
+		byte(length>>21) & 0x7F, byte(length>>14) & 0x7F, byte(length>>7) & 0x7F, byte(length) & 0x7F}
+	return append(header, frames...)
+}
+
+// id3v2Frame - frame with 4 bytes size and flags
+func id3v2Frame(key string, flags uint16, data []byte) []byte {
+	length := len(data)
+	frame := append([]byte(key), byte(length>>24), byte(length>>16), byte(length>>8), byte(length),
+		byte(flags>>8), byte(flags))
+	return append(frame, data...)
+}
+
+func zlibData(t *testing.T, data []byte) []byte {
+	output := new(bytes.Buffer)
+	writer := zlib.NewWriter(output)
+	_, err := writer.Write(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return output.Bytes()
+}
+
+func TestID3v24FrameFlagsRead(t *testing.T) {
+	asrt := assert.New(t)
+	title := append([]byte{0}, "Compressed Cat"...)
+
+	// grouped, compressed, data length indicator
+	compressed := append([]byte{7, 0, 0, 0, byte(len(title))}, zlibData(t, title)...)
+	// unsynchronised, data length indicator
+	picture := []byte{0, 'i', 'm', 'a', 'g', 'e', '/', 'j', 'p', 'e', 'g', 0, 3, 0, 0xFF, 0xE0, 0xFF}
+	unsynchronised := append([]byte{0, 0, 0, byte(len(picture))}, 0, 'i', 'm', 'a', 'g', 'e', '/', 'j', 'p', 'e', 'g',
+		0, 3, 0, 0xFF, 0, 0xE0, 0xFF, 0)
+
+	frames := append(id3v2Frame("TIT2", 0x4049, compressed), id3v2Frame("APIC", 0x0003, unsynchronised)...)
+	id3, err := tag.ReadID3v24(bytes.NewReader(id3v2Tag(4, 0, frames)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	value, err := id3.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Compressed Cat", value)
+	asrt.True(id3.Frames[0].Flags.IsCompressed())
+	asrt.True(id3.Frames[0].Flags.HasGroup())
+	asrt.Equal(byte(7), id3.Frames[0].Group)
+
+	data, err := id3.GetBytes("APIC")
+	asrt.NoError(err)
+	asrt.Equal(picture, data)
+	asrt.True(id3.Frames[1].Flags.IsUnsynchronised())
+
+	// unsynchronised tag
+	frames = id3v2Frame("APIC", 0x0001, unsynchronised)
+	id3, err = tag.ReadID3v24(bytes.NewReader(id3v2Tag(4, 0x80, frames)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	data, err = id3.GetBytes("APIC")
+	asrt.NoError(err)
+	asrt.Equal(picture, data)
+}
+
+func TestID3v23FrameFlagsRead(t *testing.T) {
+	asrt := assert.New(t)
+	title := append([]byte{0}, "Compressed Cat"...)
+
+	// compressed with decompressed size, grouped
+	compressed := append([]byte{0, 0, 0, byte(len(title)), 7}, zlibData(t, title)...)
+	artist := []byte{0, 'C', 'a', 't', 0xFF, 0xE0}
+	frames := append(id3v2Frame("TIT2", 0x00A0, compressed), id3v2Frame("TPE1", 0, artist)...)
+
+	// whole tag is unsynchronised
+	var unsynchronised []byte
+	for i, b := range frames {
+		unsynchronised = append(unsynchronised, b)
+		if b == 0xFF && (i+1 == len(frames) || frames[i+1] >= 0xE0 || frames[i+1] == 0) {
+			unsynchronised = append(unsynchronised, 0)
+		}
+	}
+
+	id3, err := tag.ReadID3v23(bytes.NewReader(id3v2Tag(3, 0x80, unsynchronised)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	value, err := id3.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Compressed Cat", value)
+	asrt.True(id3.Frames[0].Flags.IsCompressed())
+	asrt.Equal(byte(7), id3.Frames[0].Group)
+
+	data, err := id3.GetBytes("TPE1")
+	asrt.NoError(err)
+	asrt.Equal(artist, data)
+}
+
+func TestID3v2FrameFlagsWrite(t *testing.T) {
+	asrt := assert.New(t)
+	id3, err := tag.ReadID3v24(mustOpen(t, "meow_id2.4.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	audio := id3.GetFileData()
+
+	id3.Compress = true
+	id3.Unsynchronise = true
+	asrt.NoError(id3.SetTitle("Compressed Cat"))
+	asrt.NoError(id3.SetBytes("PRIV", []byte{0xFF, 0xE0, 0xFF, 0x00, 0xFF}))
+	out, err := ioutil.TempFile("", "flagsTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(id3.SaveFile(out.Name()))
+
+	// flags are kept
+	id3v2, err := tag.ReadID3v24(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.True(id3v2.Flags.IsUnsynchronisation())
+	asrt.Equal(audio, id3v2.GetFileData())
+	title, err := id3v2.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Compressed Cat", title)
+	data, err := id3v2.GetBytes("PRIV")
+	asrt.NoError(err)
+	asrt.Equal([]byte{0xFF, 0xE0, 0xFF, 0x00, 0xFF}, data)
+	for i := range id3v2.Frames {
+		asrt.True(id3v2.Frames[i].Flags.IsCompressed(), id3v2.Frames[i].Key)
+		asrt.True(id3v2.Frames[i].Flags.IsUnsynchronised(), id3v2.Frames[i].Key)
+	}
+
+	// compression is kept on conversion
+	converted, err := tag.ConvertID3v2(id3v2, tag.VersionID3v23)
+	asrt.NoError(err)
+	id3v23 := converted.(*tag.ID3v23)
+	asrt.NoError(id3v23.SaveFile(out.Name()))
+
+	id3v23, err = tag.ReadID3v23(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, id3v23.GetFileData())
+	title, err = id3v23.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Compressed Cat", title)
+	for i := range id3v23.Frames {
+		asrt.True(id3v23.Frames[i].Flags.IsCompressed(), id3v23.Frames[i].Key)
+	}
+}
@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"bytes"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestID3v24ExtendedHeaderRead(t *testing.T) {
+	asrt := assert.New(t)
+	frames := id3v2Frame("TIT2", 0, append([]byte{0}, "Extended Cat"...))
+	// zlib.crc32 of frames and 16 bytes of padding
+	crc := uint32(0x70E8D743)
+
+	// update, CRC and restrictions
+	extended := []byte{0, 0, 0, 15, 1, 0x70, 0, 5, byte(crc>>28) & 0x7F,
+		byte(crc>>21) & 0x7F, byte(crc>>14) & 0x7F, byte(crc>>7) & 0x7F, byte(crc) & 0x7F, 1, 0x12}
+	body := append(append(extended, frames...), make([]byte, 16)...)
+	audio := []byte{0xFF, 0xFB, 0x90, 0x00}
+	data := append(id3v2Tag(4, 0x40, body), audio...)
+
+	id3, err := tag.ReadID3v24(bytes.NewReader(data))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, id3.GetFileData())
+	asrt.NotNil(id3.ExtendedHeader)
+	asrt.True(id3.ExtendedHeader.Update)
+	asrt.True(id3.ExtendedHeader.HasCRC)
+	asrt.Equal(crc, id3.ExtendedHeader.CRC)
+	asrt.True(id3.ExtendedHeader.HasRestrictions)
+	asrt.Equal(byte(0x12), id3.ExtendedHeader.Restrictions)
+	asrt.NoError(id3.VerifyCRC())
+	title, err := id3.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Extended Cat", title)
+
+	// wrong CRC
+	data[18]++
+	id3, err = tag.ReadID3v24(bytes.NewReader(data))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(tag.ErrIncorrectCRC, id3.VerifyCRC())
+
+	// footer
+	data = append(id3v2Tag(4, 0x10, frames), '3', 'D', 'I', 4, 0, 0x10, 0, 0, 0, byte(len(frames)))
+	data = append(data, audio...)
+	id3, err = tag.ReadID3v24(bytes.NewReader(data))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.True(id3.Flags.HasFooter())
+	asrt.Equal(audio, id3.GetFileData())
+	asrt.Equal(tag.ErrTagNotFound, id3.VerifyCRC())
+}
+
+func TestID3v24ExtendedHeaderWrite(t *testing.T) {
+	asrt := assert.New(t)
+	id3, err := tag.ReadID3v24(mustOpen(t, "meow_id2.4.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	audio := id3.GetFileData()
+
+	id3.ExtendedHeader = &tag.ID3v24ExtendedHeader{HasCRC: true, HasRestrictions: true, Restrictions: 0x12}
+	id3.Flags.SetFooter(true)
+	out, err := ioutil.TempFile("", "extendedTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(id3.SaveFile(out.Name()))
+
+	id3v2, err := tag.ReadID3v24(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, id3v2.GetFileData())
+	asrt.NotNil(id3v2.ExtendedHeader)
+	asrt.Equal(byte(0x12), id3v2.ExtendedHeader.Restrictions)
+	asrt.Equal(id3.ExtendedHeader.CRC, id3v2.ExtendedHeader.CRC)
+	asrt.NoError(id3v2.VerifyCRC())
+	asrt.True(id3v2.Flags.HasExtendedHeader())
+	asrt.True(id3v2.Flags.HasFooter())
+
+	raw, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal("3DI", string(raw[10+id3v2.Length:13+id3v2.Length]))
+
+	// footer without padding in place
+	file := tempCopy(t, out.Name())
+	id3v2, err = tag.ReadID3v24(file)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(id3v2.SetTitle("Footer Cat"))
+	asrt.NoError(id3v2.SaveInPlace(file))
+
+	id3v2, err = tag.ReadID3v24(file)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, id3v2.GetFileData())
+	asrt.NoError(id3v2.VerifyCRC())
+	title, err := id3v2.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Footer Cat", title)
+
+	// CRC of padding written in place
+	id3v2.Flags.SetFooter(false)
+	asrt.NoError(id3v2.SetTitle("Padded Cat"))
+	asrt.NoError(id3v2.SaveInPlace(file))
+	id3v2, err = tag.ReadID3v24(file)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(id3v2.VerifyCRC())
+	raw, err = ioutil.ReadFile(file.Name())
+	asrt.NoError(err)
+	asrt.Equal(byte(0), raw[9+id3v2.Length])
+	asrt.Equal(crc32.ChecksumIEEE(raw[10+14:10+id3v2.Length]), id3v2.ExtendedHeader.CRC)
+}
+
+func TestID3v23ExtendedHeader(t *testing.T) {
+	asrt := assert.New(t)
+	frames := id3v2Frame("TIT2", 0, []byte{0, 'C', 'a', 't', 0xFF})
+	crc := crc32.ChecksumIEEE(frames)
+
+	// padding size and CRC, unsynchronised with frames
+	extended := []byte{0, 0, 0, 10, 0x80, 0, 0, 0, 0, 4, byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)}
+	body := append(extended, frames...)
+	var unsynchronised []byte
+	for i, b := range body {
+		unsynchronised = append(unsynchronised, b)
+		if b == 0xFF && (i+1 == len(body) || body[i+1] >= 0xE0 || body[i+1] == 0) {
+			unsynchronised = append(unsynchronised, 0)
+		}
+	}
+	audio := []byte{0xFF, 0xFB, 0x90, 0x00}
+	data := append(id3v2Tag(3, 0xC0, append(unsynchronised, 0, 0, 0, 0)), audio...)
+
+	id3, err := tag.ReadID3v23(bytes.NewReader(data))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, id3.GetFileData())
+	asrt.NotNil(id3.ExtendedHeader)
+	asrt.Equal(4, id3.ExtendedHeader.Padding)
+	asrt.Equal(crc, id3.ExtendedHeader.CRC)
+	asrt.NoError(id3.VerifyCRC())
+	value, err := id3.GetBytes("TIT2")
+	asrt.NoError(err)
+	asrt.Equal([]byte{0, 'C', 'a', 't', 0xFF}, value)
+
+	// saved with CRC
+	asrt.NoError(id3.SetArtist("Extended Kitten"))
+	out, err := ioutil.TempFile("", "extendedTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(id3.SaveFile(out.Name()))
+
+	id3v2, err := tag.ReadID3v23(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, id3v2.GetFileData())
+	asrt.True(id3v2.Flags.IsUnsynchronisation())
+	asrt.NoError(id3v2.VerifyCRC())
+	asrt.Equal(0, id3v2.ExtendedHeader.Padding)
+	artist, err := id3v2.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Extended Kitten", artist)
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestID3v24SaveInPlace(t *testing.T) {
+	asrt := assert.New(t)
+	file := tempCopy(t, "meow_id2.4.mp3")
+	id3, err := tag.ReadID3v24(file)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	audio := id3.GetFileData()
+
+	// tag doesn't fit, audio is moved after padding
+	asrt.NoError(id3.SetTitle("In Place Cat"))
+	asrt.NoError(id3.SaveInPlace(file))
+	info, err := file.Stat()
+	asrt.NoError(err)
+	size := info.Size()
+	asrt.Equal(int64(10+id3.Length+len(audio)), size)
+
+	// tag with padding is read
+	id3v2, err := tag.ReadID3v24(file)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, id3v2.GetFileData())
+	title, err := id3v2.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("In Place Cat", title)
+
+	// tag fits into padding, file size is the same
+	asrt.NoError(id3v2.SetArtist("In Place Kitten"))
+	asrt.NoError(id3v2.SaveInPlace(file))
+	info, err = file.Stat()
+	asrt.NoError(err)
+	asrt.Equal(size, info.Size())
+
+	id3v2, err = tag.ReadID3v24(file)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, id3v2.GetFileData())
+	artist, err := id3v2.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("In Place Kitten", artist)
+
+	// configurable padding
+	id3v2.Padding = 100
+	asrt.NoError(id3v2.SetComment(strings.Repeat("meow ", 500)))
+	asrt.NoError(id3v2.SaveInPlace(file))
+	info, err = file.Stat()
+	asrt.NoError(err)
+	asrt.Equal(int64(10+id3v2.Length+len(audio)), info.Size())
+
+	id3v2, err = tag.ReadID3v24(file)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, id3v2.GetFileData())
+	title, err = id3v2.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("In Place Cat", title)
+}
+
+func TestFLACSaveInPlace(t *testing.T) {
+	asrt := assert.New(t)
+	file := tempCopy(t, "raw.flac")
+	flac, err := tag.ReadFLAC(file)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	audio := flac.GetFileData()
+	info, err := file.Stat()
+	asrt.NoError(err)
+	size := info.Size()
+
+	// Vorbis comment takes space of padding block
+	asrt.NoError(flac.SetTitle("In Place Cat"))
+	asrt.NoError(flac.SaveInPlace(file))
+	info, err = file.Stat()
+	asrt.NoError(err)
+	asrt.Equal(size, info.Size())
+
+	flac2, err := tag.ReadFLAC(file)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, flac2.GetFileData())
+	asrt.Equal(tag.FlacPadding, flac2.Blocks[len(flac2.Blocks)-1].Type)
+	title, err := flac2.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("In Place Cat", title)
+
+	// metadata doesn't fit, audio is moved
+	flac2.Padding = -1
+	asrt.NoError(flac2.SetDescription(strings.Repeat("meow ", 500)))
+	asrt.NoError(flac2.SaveInPlace(file))
+	asrt.NotEqual(tag.FlacPadding, flac2.Blocks[len(flac2.Blocks)-1].Type)
+
+	flac3, err := tag.ReadFLAC(file)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, flac3.GetFileData())
+	description, err := flac3.GetDescription()
+	asrt.NoError(err)
+	asrt.Equal(strings.Repeat("meow ", 500), description)
+}
+
+// tempCopy - copy of test file opened for writing
+func tempCopy(t *testing.T, path string) *os.File {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := ioutil.TempFile("", "inPlaceTst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		file.Close()
+		os.Remove(file.Name())
+	})
+	_, err = file.Write(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
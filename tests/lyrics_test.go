@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"bytes"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestID3v2Lyrics(t *testing.T) {
+	asrt := assert.New(t)
+	frames := append(id3v2Frame("USLT", 0, []byte("\x03engVerse\x00Meow meow")),
+		id3v2Frame("USLT", 0, []byte("\x03deu\x00Miau miau"))...)
+	id3, err := tag.ReadID3v24(bytes.NewReader(id3v2Tag(4, 0, frames)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	lyrics, err := id3.GetLyrics("")
+	asrt.NoError(err)
+	asrt.Equal("Meow meow", lyrics)
+	lyrics, err = id3.GetLyrics("deu")
+	asrt.NoError(err)
+	asrt.Equal("Miau miau", lyrics)
+	_, err = id3.GetLyrics("fra")
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	// content descriptor is kept
+	asrt.NoError(id3.SetLyrics("eng", "Purr"))
+	asrt.NoError(id3.SetLyrics("fra", "Ронрон"))
+	asrt.NoError(id3.DeleteLyrics("deu"))
+	asrt.Equal(tag.ErrIncorrectLength, id3.SetLyrics("english", "Purr"))
+	out, err := ioutil.TempFile("", "lyricsTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(id3.SaveFile(out.Name()))
+
+	id3, err = tag.ReadID3v24(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	value, err := id3.GetBytes("USLT")
+	asrt.NoError(err)
+	asrt.Equal([]byte("\x00engVerse\x00Purr"), value)
+	lyrics, err = id3.GetLyrics("fra")
+	asrt.NoError(err)
+	asrt.Equal("Ронрон", lyrics)
+	_, err = id3.GetLyrics("deu")
+	asrt.Equal(tag.ErrTagNotFound, err)
+	asrt.NoError(id3.DeleteLyrics(""))
+	_, err = id3.GetLyrics("")
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	// malformed frame is skipped
+	frames = append(id3v2Frame("USLT", 0, []byte("\x03en")), id3v2Frame("USLT", 0, []byte("\x03deu\x00Miau"))...)
+	id3, err = tag.ReadID3v24(bytes.NewReader(id3v2Tag(4, 0, frames)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	lyrics, err = id3.GetLyrics("deu")
+	asrt.NoError(err)
+	asrt.Equal("Miau", lyrics)
+	_, err = id3.GetLyrics("eng")
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	// ID3v2.2 ULT frame
+	id3v22, err := tag.ReadID3v22(mustOpen(t, "id3v2.2.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	lyrics, err = id3v22.GetLyrics("eng")
+	asrt.NoError(err)
+	asrt.NotEmpty(lyrics)
+	asrt.NoError(id3v22.DeleteLyrics("eng"))
+	asrt.NoError(id3v22.SetLyrics("", "Meow"))
+	value, err = id3v22.GetBytes("ULT")
+	asrt.NoError(err)
+	asrt.Equal([]byte("\x00XXX\x00Meow"), value)
+	lyrics, err = id3v22.GetLyrics("xxx")
+	asrt.NoError(err)
+	asrt.Equal("Meow", lyrics)
+}
+
+func TestLyrics(t *testing.T) {
+	asrt := assert.New(t)
+
+	// Vorbis comments
+	ogg, err := tag.ReadOggVorbis(mustOpen(t, "kitten.ogg"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(ogg.SetValues("UNSYNCEDLYRICS", []string{"Meow"}))
+	lyrics, err := ogg.GetLyrics("")
+	asrt.NoError(err)
+	asrt.Equal("Meow", lyrics)
+	asrt.NoError(ogg.SetLyrics("eng", "Purr"))
+	_, err = ogg.GetValues("UNSYNCEDLYRICS")
+	asrt.Equal(tag.ErrTagNotFound, err)
+	values, err := ogg.GetValues("LYRICS")
+	asrt.NoError(err)
+	asrt.Equal([]string{"Purr"}, values)
+	asrt.NoError(ogg.DeleteLyrics(""))
+	_, err = ogg.GetLyrics("")
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	// MP4
+	mp4, err := tag.ReadMp4(mustOpen(t, "cat_walking.mp4"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(mp4.SetLyrics("eng", "Meow\nMeow"))
+	out, err := ioutil.TempFile("", "lyricsTst.mp4")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(mp4.SaveFile(out.Name()))
+
+	mp4, err = tag.ReadMp4(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	lyrics, err = mp4.GetLyrics("")
+	asrt.NoError(err)
+	asrt.Equal("Meow\nMeow", lyrics)
+	asrt.Equal("Meow\nMeow", tag.GetMap(mp4)["lyrics"])
+
+	// ID3v1 has no lyrics
+	id3v1, err := tag.ReadID3v1(mustOpen(t, "id3v1.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(tag.ErrUnsupportedTag, id3v1.SetLyrics("", "Meow"))
+}
@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatroskaRead(t *testing.T) {
+	asrt := assert.New(t)
+	mkv, err := tag.ReadFile("kitten.mka")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.Equal(tag.VersionMatroska, mkv.GetVersion())
+	asrt.Equal(1033, len(mkv.GetFileData()))
+
+	// track level
+	title, err := mkv.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Kitten Purr", title)
+
+	artist, err := mkv.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cute Kitten", artist)
+
+	genre, err := mkv.GetGenre()
+	asrt.NoError(err)
+	asrt.Equal("catmusic", genre)
+
+	// album level
+	album, err := mkv.GetAlbum()
+	asrt.NoError(err)
+	asrt.Equal("CatAlbum", album)
+
+	albumArtist, err := mkv.GetAlbumArtist()
+	asrt.NoError(err)
+	asrt.Equal("CatAlbumArtist", albumArtist)
+
+	date, err := mkv.GetDate()
+	asrt.NoError(err)
+	asrt.Equal(time.Date(2019, time.March, 5, 0, 0, 0, 0, time.UTC), date)
+
+	year, err := mkv.GetYear()
+	asrt.NoError(err)
+	asrt.Equal(2019, year)
+
+	number, total, err := mkv.GetTrackNumber()
+	asrt.NoError(err)
+	asrt.Equal(3, number)
+	asrt.Equal(12, total)
+
+	// tags for one track only are not used
+	asrt.NotContains(mkv.GetAllTagNames(), "ENCODER")
+
+	picture, err := mkv.GetPicture()
+	asrt.NoError(err)
+	if picture != nil {
+		asrt.Equal(2, picture.Bounds().Dx())
+		asrt.Equal(3, picture.Bounds().Dy())
+	}
+}
+
+func TestMatroskaWrite(t *testing.T) {
+	asrt := assert.New(t)
+	data, err := ioutil.ReadFile("kitten.mka")
+	asrt.NoError(err)
+
+	mkv, err := tag.ReadMatroska(mustOpen(t, "kitten.mka"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	// unchanged tags
+	out, err := ioutil.TempFile("", "mkaTst.mka")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(mkv.SaveFile(out.Name()))
+
+	saved, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(data, saved)
+
+	// small change fits into Void after Tags
+	asrt.NoError(mkv.SetComment("meow"))
+	asrt.NoError(mkv.SaveFile(out.Name()))
+
+	saved, err = ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(len(data), len(saved))
+
+	mkv2, err := tag.ReadMatroska(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	comment, err := mkv2.GetComment()
+	asrt.NoError(err)
+	asrt.Equal("meow", comment)
+
+	// big change moves Tags to the end of segment
+	description := strings.Repeat("cat ", 500)
+	asrt.NoError(mkv2.SetDescription(description))
+	asrt.NoError(mkv2.SetDiscNumber(1, 2))
+	asrt.NoError(mkv2.DeleteArtist())
+	img := image.NewRGBA(image.Rect(0, 0, 4, 5))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	asrt.NoError(mkv2.SetPicture(img))
+	asrt.NoError(mkv2.SaveFile(out.Name()))
+
+	mkv3, err := tag.ReadMatroska(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(mkv.GetFileData(), mkv3.GetFileData())
+
+	value, err := mkv3.GetDescription()
+	asrt.NoError(err)
+	asrt.Equal(description, value)
+
+	number, total, err := mkv3.GetDiscNumber()
+	asrt.NoError(err)
+	asrt.Equal(1, number)
+	asrt.Equal(2, total)
+
+	_, err = mkv3.GetArtist()
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	albumArtist, err := mkv3.GetAlbumArtist()
+	asrt.NoError(err)
+	asrt.Equal("CatAlbumArtist", albumArtist)
+
+	picture, err := mkv3.GetPicture()
+	asrt.NoError(err)
+	if picture != nil {
+		asrt.Equal(4, picture.Bounds().Dx())
+		asrt.Equal(5, picture.Bounds().Dy())
+	}
+
+	// tag for one track is kept
+	encoder := false
+	for _, mkvTag := range mkv3.Tags {
+		for _, simple := range mkvTag.SimpleTags {
+			if simple.Name == "ENCODER" {
+				encoder = !mkvTag.Global()
+			}
+		}
+	}
+	asrt.True(encoder)
+
+	asrt.NoError(mkv3.DeleteAll())
+	asrt.NoError(mkv3.SaveFile(out.Name()))
+
+	mkv4, err := tag.ReadFile(out.Name())
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(mkv.GetFileData(), mkv4.GetFileData())
+
+	_, err = mkv4.GetTitle()
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	_, err = mkv4.GetPicture()
+	asrt.Equal(tag.ErrTagNotFound, err)
+}
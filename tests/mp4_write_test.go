@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// nolint:funlen
+func TestMp4Write(t *testing.T) {
+	asrt := assert.New(t)
+	mp4, err := tag.ReadFile("cat_walking.mp4")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.NoError(mp4.SetTitle("Cat Running"))
+	asrt.NoError(mp4.SetArtist("Black Cat"))
+	asrt.NoError(mp4.SetComment("meow"))
+	asrt.NoError(mp4.SetBPM(120))
+	asrt.NoError(mp4.SetDiscNumber(1, 2))
+	asrt.NoError(mp4.SetTrackNumber(3, 12))
+	asrt.NoError(mp4.SetConductor("Conductor Cat"))
+	asrt.NoError(mp4.DeleteCopyright())
+
+	out, err := ioutil.TempFile("", "mp4Tst.mp4")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	err = mp4.SaveFile(out.Name())
+	asrt.NoError(err)
+
+	mp42, err := tag.ReadFile(out.Name())
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	// Check file main data is untouched
+	asrt.Equal(0, bytes.Compare(mp4.GetFileData(), mp42.GetFileData()))
+
+	title, err := mp42.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Cat Running", title)
+
+	artist, err := mp42.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Black Cat", artist)
+
+	album, err := mp42.GetAlbum()
+	asrt.NoError(err)
+	asrt.Equal("Travel", album)
+
+	comment, err := mp42.GetComment()
+	asrt.NoError(err)
+	asrt.Equal("meow", comment)
+
+	bpm, err := mp42.GetBPM()
+	asrt.NoError(err)
+	asrt.Equal(120, bpm)
+
+	disc, discTotal, err := mp42.GetDiscNumber()
+	asrt.NoError(err)
+	asrt.Equal(1, disc)
+	asrt.Equal(2, discTotal)
+
+	track, trackTotal, err := mp42.GetTrackNumber()
+	asrt.NoError(err)
+	asrt.Equal(3, track)
+	asrt.Equal(12, trackTotal)
+
+	conductor, err := mp42.GetConductor()
+	asrt.NoError(err)
+	asrt.Equal("Conductor Cat", conductor)
+
+	_, err = mp42.GetCopyright()
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	_, err = mp42.GetPicture()
+	asrt.NoError(err)
+}
+
+func mp4Box(name string, data ...[]byte) []byte {
+	payload := bytes.Join(data, nil)
+	result := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(result, uint32(8+len(payload)))
+	copy(result[4:], name)
+	return append(result, payload...)
+}
+
+func TestMp4WriteUnknownItems(t *testing.T) {
+	asrt := assert.New(t)
+	// 'trkn' too short to parse
+	trkn := mp4Box("trkn", mp4Box("data", make([]byte, 8), []byte{0, 0, 0, 1}))
+	ilst := mp4Box("ilst", trkn)
+	moov := mp4Box("moov", mp4Box("udta", mp4Box("meta", make([]byte, 4), ilst)))
+	data := append(append(mp4Box("ftyp", []byte("M4A \x00\x00\x00\x00")), moov...), mp4Box("mdat", []byte("meow"))...)
+
+	mp4, err := tag.ReadMp4(bytes.NewReader(data))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(mp4.SetTrackNumber(3, 12))
+	out, err := ioutil.TempFile("", "mp4Tst.mp4")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(mp4.SaveFile(out.Name()))
+
+	raw, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(1, bytes.Count(raw, []byte("trkn")))
+	mp4, err = tag.ReadMp4(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	track, total, err := mp4.GetTrackNumber()
+	asrt.NoError(err)
+	asrt.Equal(3, track)
+	asrt.Equal(12, total)
+}
+
+func TestMp4WriteChunkOffsets(t *testing.T) {
+	asrt := assert.New(t)
+	// the first chunk offset overflows 32 bits when 'moov' grows
+	offsets := make([]byte, 8)
+	binary.BigEndian.PutUint32(offsets[0:4], 0xFFFFFFF0)
+	binary.BigEndian.PutUint32(offsets[4:8], 8)
+	stco := mp4Box("stco", []byte{0, 0, 0, 0, 0, 0, 0, 2}, offsets)
+	moov := mp4Box("moov", mp4Box("trak", mp4Box("mdia", mp4Box("minf", mp4Box("stbl", stco)))))
+	ftyp := mp4Box("ftyp", []byte("M4A \x00\x00\x00\x00"))
+	data := append(append(append([]byte{}, ftyp...), moov...), mp4Box("mdat", []byte("meow"))...)
+
+	mp4, err := tag.ReadMp4(bytes.NewReader(data))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(mp4.SetTitle("Large Cat"))
+	out, err := ioutil.TempFile("", "mp4Tst.mp4")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(mp4.SaveFile(out.Name()))
+
+	raw, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(-1, bytes.Index(raw, []byte("stco")))
+	index := bytes.Index(raw, []byte("co64"))
+	asrt.NotEqual(-1, index)
+	if index == -1 {
+		return
+	}
+	delta := int64(binary.BigEndian.Uint32(raw[len(ftyp):])) - int64(len(moov))
+	asrt.Equal(uint32(2), binary.BigEndian.Uint32(raw[index+8:]))
+	asrt.Equal(uint64(0xFFFFFFF0+delta), binary.BigEndian.Uint64(raw[index+12:]))
+	asrt.Equal(uint64(8), binary.BigEndian.Uint64(raw[index+20:]))
+	asrt.Equal([]byte("meow"), raw[len(raw)-4:])
+
+	mp4, err = tag.ReadMp4(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	title, err := mp4.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Large Cat", title)
+}
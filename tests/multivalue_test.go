@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"bytes"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestID3v24Values(t *testing.T) {
+	asrt := assert.New(t)
+	frames := append(textFrame("TPE1", "Cat\x00Kitten\x00"), textFrame("TIT2", "Meow")...)
+	id3, err := tag.ReadID3v24(bytes.NewReader(id3v2Tag(4, 0, frames)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	artist, err := id3.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cat", artist)
+	artists, err := id3.GetValues("TPE1")
+	asrt.NoError(err)
+	asrt.Equal([]string{"Cat", "Kitten"}, artists)
+	_, err = id3.GetValues("TCON")
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	// round trip
+	asrt.NoError(id3.SetValues("TCON", []string{"Rock", "Ворчание"}))
+	out, err := ioutil.TempFile("", "valuesTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(id3.SaveFile(out.Name()))
+
+	id3, err = tag.ReadID3v24(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	genres, err := id3.GetValues("TCON")
+	asrt.NoError(err)
+	asrt.Equal([]string{"Rock", "Ворчание"}, genres)
+	artists, err = id3.GetValues("TPE1")
+	asrt.NoError(err)
+	asrt.Equal([]string{"Cat", "Kitten"}, artists)
+
+	asrt.NoError(id3.SetValues("TPE1", nil))
+	_, err = id3.GetArtist()
+	asrt.Equal(tag.ErrTagNotFound, err)
+}
+
+func TestVorbisValues(t *testing.T) {
+	asrt := assert.New(t)
+	flac, err := tag.ReadFLAC(mustOpen(t, "v1.flac"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.NoError(flac.SetValues("artist", []string{"Cat", "Kitten"}))
+	asrt.NoError(flac.SetValues("GENRE", []string{"Rock", "Pop"}))
+	out, err := ioutil.TempFile("", "valuesTst.flac")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(flac.SaveFile(out.Name()))
+
+	flac, err = tag.ReadFLAC(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	artists, err := flac.GetValues("ARTIST")
+	asrt.NoError(err)
+	asrt.Equal([]string{"Cat", "Kitten"}, artists)
+	genres, err := flac.GetValues("genre")
+	asrt.NoError(err)
+	asrt.Equal([]string{"Rock", "Pop"}, genres)
+	artist, err := flac.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cat", artist)
+
+	// Ogg Vorbis
+	ogg, err := tag.ReadOggVorbis(mustOpen(t, "kitten.ogg"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(ogg.SetValues("ARTIST", []string{"Cat", "Kitten"}))
+	out2, err := ioutil.TempFile("", "valuesTst.ogg")
+	asrt.NoError(err)
+	defer os.Remove(out2.Name())
+	asrt.NoError(ogg.SaveFile(out2.Name()))
+
+	ogg, err = tag.ReadOggVorbis(mustOpen(t, out2.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	artists, err = ogg.GetValues("ARTIST")
+	asrt.NoError(err)
+	asrt.Equal([]string{"Cat", "Kitten"}, artists)
+
+	asrt.NoError(ogg.SetValues("ARTIST", nil))
+	_, err = ogg.GetValues("ARTIST")
+	asrt.Equal(tag.ErrTagNotFound, err)
+}
+
+func TestMp4Values(t *testing.T) {
+	asrt := assert.New(t)
+	mp4, err := tag.ReadMp4(mustOpen(t, "cat_walking.mp4"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.NoError(mp4.SetValues(tag.Mp4TagArtist, []string{"Cat", "Kitten"}))
+	asrt.NoError(mp4.SetValues(tag.Mp4TagArranger, []string{"Tom", "Jerry"}))
+	asrt.Equal(tag.ErrIncorrectTag, mp4.SetValues(tag.Mp4TagTrack, []string{"1"}))
+	out, err := ioutil.TempFile("", "valuesTst.mp4")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(mp4.SaveFile(out.Name()))
+
+	mp4, err = tag.ReadMp4(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	artists, err := mp4.GetValues(tag.Mp4TagArtist)
+	asrt.NoError(err)
+	asrt.Equal([]string{"Cat", "Kitten"}, artists)
+	artist, err := mp4.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cat", artist)
+	arrangers, err := mp4.GetValues(tag.Mp4TagArranger)
+	asrt.NoError(err)
+	asrt.Equal([]string{"Tom", "Jerry"}, arrangers)
+}
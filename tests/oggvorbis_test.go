@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOggVorbisRead(t *testing.T) {
+	asrt := assert.New(t)
+	ogg, err := tag.ReadFile("kitten.ogg")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.Equal(tag.VersionOggVorbis, ogg.GetVersion())
+
+	title, err := ogg.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Ogg Cat", title)
+
+	artist, err := ogg.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cute Kitten", artist)
+
+	album, err := ogg.GetAlbum()
+	asrt.NoError(err)
+	asrt.Equal("CatAlbum", album)
+
+	genre, err := ogg.GetGenre()
+	asrt.NoError(err)
+	asrt.Equal("catmusic", genre)
+
+	date, err := ogg.GetDate()
+	asrt.NoError(err)
+	asrt.Equal(time.Date(2019, time.March, 5, 10, 0, 0, 0, time.UTC), date)
+
+	number, total, err := ogg.GetTrackNumber()
+	asrt.NoError(err)
+	asrt.Equal(3, number)
+	asrt.Equal(12, total)
+
+	_, err = ogg.GetPicture()
+	asrt.Equal(tag.ErrTagNotFound, err)
+}
+
+func TestOggVorbisWrite(t *testing.T) {
+	asrt := assert.New(t)
+	ogg, err := tag.ReadFile("kitten.ogg")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	// comment header spans several pages
+	description := strings.Repeat("meow ", 30000)
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+
+	asrt.NoError(ogg.SetTitle("Ogg Cat Running"))
+	asrt.NoError(ogg.SetDescription(description))
+	asrt.NoError(ogg.SetPicture(img))
+	asrt.NoError(ogg.DeleteGenre())
+
+	out, err := ioutil.TempFile("", "oggTst.ogg")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(ogg.SaveFile(out.Name()))
+
+	ogg2, err := tag.ReadFile(out.Name())
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	// audio pages are only renumbered
+	asrt.Equal(len(ogg.GetFileData()), len(ogg2.GetFileData()))
+
+	title, err := ogg2.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Ogg Cat Running", title)
+
+	desc, err := ogg2.GetDescription()
+	asrt.NoError(err)
+	asrt.Equal(description, desc)
+
+	album, err := ogg2.GetAlbum()
+	asrt.NoError(err)
+	asrt.Equal("CatAlbum", album)
+
+	_, err = ogg2.GetGenre()
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	picture, err := ogg2.GetPicture()
+	asrt.NoError(err)
+	if err == nil {
+		asrt.Equal(img.Bounds(), picture.Bounds())
+		r, _, _, _ := picture.At(1, 1).RGBA()
+		asrt.Equal(uint32(0xffff), r)
+	}
+
+	// second save of the repaginated file is stable
+	out2, err := ioutil.TempFile("", "oggTst2.ogg")
+	asrt.NoError(err)
+	defer os.Remove(out2.Name())
+	asrt.NoError(ogg2.SaveFile(out2.Name()))
+
+	first, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	second, err := ioutil.ReadFile(out2.Name())
+	asrt.NoError(err)
+	asrt.Equal(first, second)
+}
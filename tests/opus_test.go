@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestOpusRead(t *testing.T) {
+	asrt := assert.New(t)
+	metadata, err := tag.ReadFile("kitten.opus")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.Equal(tag.VersionOpus, metadata.GetVersion())
+
+	title, err := metadata.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Opus Cat", title)
+
+	artist, err := metadata.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cute Kitten", artist)
+
+	opus, ok := metadata.(*tag.Opus)
+	asrt.True(ok)
+	if !ok {
+		return
+	}
+
+	gain, err := opus.GetOutputGain()
+	asrt.NoError(err)
+	asrt.Equal(-512, gain)
+
+	trackGain, err := opus.GetTrackGain()
+	asrt.NoError(err)
+	asrt.Equal(-1536, trackGain)
+
+	albumGain, err := opus.GetAlbumGain()
+	asrt.NoError(err)
+	asrt.Equal(-1024, albumGain)
+}
+
+func TestOpusWrite(t *testing.T) {
+	asrt := assert.New(t)
+	opus, err := tag.ReadOpus(mustOpen(t, "kitten.opus"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.NoError(opus.SetTitle("Opus Cat Running"))
+	asrt.NoError(opus.DeleteArtist())
+	asrt.NoError(opus.SetOutputGain(256))
+	asrt.NoError(opus.SetTrackGain(-2048))
+	asrt.NoError(opus.DeleteAlbumGain())
+	asrt.Equal(tag.ErrIncorrectValue, opus.SetTrackGain(40000))
+	asrt.Equal(tag.ErrIncorrectValue, opus.SetOutputGain(-40000))
+
+	out, err := ioutil.TempFile("", "opusTst.opus")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(opus.SaveFile(out.Name()))
+
+	opus2, err := tag.ReadOpus(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.Equal(opus.GetFileData(), opus2.GetFileData())
+	asrt.Equal(opus.Extra, opus2.Extra)
+
+	title, err := opus2.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Opus Cat Running", title)
+
+	album, err := opus2.GetAlbum()
+	asrt.NoError(err)
+	asrt.Equal("CatAlbum", album)
+
+	_, err = opus2.GetArtist()
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	gain, err := opus2.GetOutputGain()
+	asrt.NoError(err)
+	asrt.Equal(256, gain)
+
+	trackGain, err := opus2.GetTrackGain()
+	asrt.NoError(err)
+	asrt.Equal(-2048, trackGain)
+
+	_, err = opus2.GetAlbumGain()
+	asrt.Equal(tag.ErrTagNotFound, err)
+}
+
+func mustOpen(t *testing.T, path string) *os.File {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
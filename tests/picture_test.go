@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"bytes"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func testPictures(t *testing.T) (tag.AttachedPicture, tag.AttachedPicture) {
+	jpeg, err := ioutil.ReadFile("cat_walking_cover.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	png, err := ioutil.ReadFile("flac.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	front := tag.AttachedPicture{MIME: "image/jpeg", PictureType: tag.PictureTypeFrontCover,
+		Description: "Front", Data: jpeg}
+	back := tag.AttachedPicture{MIME: "image/png", PictureType: tag.PictureTypeBackCover,
+		Description: "Назад", Data: png}
+	return front, back
+}
+
+func TestID3v2Pictures(t *testing.T) {
+	asrt := assert.New(t)
+	front, back := testPictures(t)
+	id3, err := tag.ReadID3v24(mustOpen(t, "meow_id2.4.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.NoError(id3.DeletePicture())
+	asrt.NoError(id3.AddPicture(back))
+	asrt.NoError(id3.AddPicture(tag.AttachedPicture{MIME: "image/png", PictureType: tag.PictureTypeFrontCover,
+		Data: back.Data}))
+	asrt.NoError(id3.ReplacePicture(front))
+	out, err := ioutil.TempFile("", "picturesTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(id3.SaveFile(out.Name()))
+
+	id3, err = tag.ReadID3v24(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal([]tag.AttachedPicture{back, front}, id3.Pictures())
+	asrt.NoError(id3.RemovePicture(tag.PictureTypeBackCover))
+	asrt.Equal([]tag.AttachedPicture{front}, id3.Pictures())
+
+	// ID3v2.3 and ID3v2.2
+	frames := id3v2Frame("TIT2", 0, []byte{0, 'C', 'a', 't'})
+	id3v23, err := tag.ReadID3v23(bytes.NewReader(id3v2Tag(3, 0, frames)))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(id3v23.DeletePicture())
+	asrt.NoError(id3v23.AddPicture(front))
+	asrt.NoError(id3v23.AddPicture(back))
+	asrt.Equal([]tag.AttachedPicture{front, back}, id3v23.Pictures())
+
+	id3v22, err := tag.ReadID3v22(mustOpen(t, "id3v2.2.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(id3v22.DeletePicture())
+	asrt.NoError(id3v22.AddPicture(front))
+	asrt.NoError(id3v22.AddPicture(back))
+	asrt.NoError(id3v22.RemovePicture(tag.PictureTypeFrontCover))
+	asrt.Equal([]tag.AttachedPicture{back}, id3v22.Pictures())
+}
+
+func TestFLACPictures(t *testing.T) {
+	asrt := assert.New(t)
+	front, back := testPictures(t)
+	flac, err := tag.ReadFLAC(mustOpen(t, "v1.flac"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.NoError(flac.DeletePicture())
+	asrt.NoError(flac.AddPicture(front))
+	asrt.NoError(flac.AddPicture(back))
+	out, err := ioutil.TempFile("", "picturesTst.flac")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(flac.SaveFile(out.Name()))
+
+	flac, err = tag.ReadFLAC(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal([]tag.AttachedPicture{front, back}, flac.Pictures())
+	block, err := flac.GetMetadataBlockPicture()
+	asrt.NoError(err)
+	asrt.NotZero(block.Width)
+	asrt.NotZero(block.Height)
+
+	asrt.NoError(flac.ReplacePicture(tag.AttachedPicture{MIME: "image/png", PictureType: tag.PictureTypeBackCover,
+		Description: "Back", Data: back.Data}))
+	pictures := flac.Pictures()
+	asrt.Equal(2, len(pictures))
+	asrt.Equal("Back", pictures[1].Description)
+	asrt.NoError(flac.DeletePicture())
+	asrt.Empty(flac.Pictures())
+}
+
+func TestMp4Pictures(t *testing.T) {
+	asrt := assert.New(t)
+	front, back := testPictures(t)
+	mp4, err := tag.ReadMp4(mustOpen(t, "cat_walking.mp4"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.NoError(mp4.ReplacePicture(front))
+	asrt.NoError(mp4.AddPicture(back))
+	asrt.Equal(tag.ErrIncorrectTag, mp4.AddPicture(tag.AttachedPicture{MIME: "image/gif", Data: []byte("GIF89a")}))
+	out, err := ioutil.TempFile("", "picturesTst.mp4")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(mp4.SaveFile(out.Name()))
+
+	mp4, err = tag.ReadMp4(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	pictures := mp4.Pictures()
+	asrt.Equal(2, len(pictures))
+	asrt.Equal(front.Data, pictures[0].Data)
+	asrt.Equal("image/png", pictures[1].MIME)
+	asrt.Equal(back.Data, pictures[1].Data)
+	asrt.Equal(byte(tag.PictureTypeFrontCover), pictures[1].PictureType)
+}
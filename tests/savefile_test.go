@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveFileAtomic(t *testing.T) {
+	asrt := assert.New(t)
+	dir, err := ioutil.TempDir("", "saveTst")
+	asrt.NoError(err)
+	defer os.RemoveAll(dir)
+
+	original, err := ioutil.ReadFile("meow_id2.4.mp3")
+	asrt.NoError(err)
+	path := filepath.Join(dir, "meow.mp3")
+	asrt.NoError(ioutil.WriteFile(path, original, 0640))
+	asrt.NoError(os.Chmod(path, 0640))
+	modTime := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	asrt.NoError(os.Chtimes(path, modTime, modTime))
+
+	// saved over the source several times
+	id3, err := tag.ReadID3v24(mustOpen(t, path))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	audio := id3.GetFileData()
+	asrt.NoError(id3.SetTitle("Atomic Cat"))
+	asrt.NoError(tag.SaveFileWithOptions(id3, path, tag.SaveOptions{KeepModTime: true, Backup: true}))
+	asrt.NoError(id3.SetArtist("Atomic Kitten"))
+	asrt.NoError(id3.SaveFile(path))
+	asrt.Equal(audio, id3.GetFileData())
+
+	saved, err := tag.ReadFile(path)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, saved.GetFileData())
+	title, err := saved.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Atomic Cat", title)
+
+	info, err := os.Stat(path)
+	asrt.NoError(err)
+	asrt.Equal(os.FileMode(0640), info.Mode().Perm())
+
+	// backup of the first save
+	backup, err := ioutil.ReadFile(path + ".bak")
+	asrt.NoError(err)
+	asrt.Equal(original, backup)
+
+	// modification time
+	asrt.NoError(os.Chtimes(path, modTime, modTime))
+	asrt.NoError(tag.SaveFileWithOptions(saved, path, tag.SaveOptions{KeepModTime: true}))
+	info, err = os.Stat(path)
+	asrt.NoError(err)
+	asrt.True(modTime.Equal(info.ModTime()))
+
+	// target of symlink is replaced
+	link := filepath.Join(dir, "link.mp3")
+	asrt.NoError(os.Symlink(path, link))
+	asrt.NoError(saved.SetAlbum("Atomic Album"))
+	asrt.NoError(saved.SaveFile(link))
+	linkInfo, err := os.Lstat(link)
+	asrt.NoError(err)
+	asrt.True(linkInfo.Mode()&os.ModeSymlink != 0)
+	saved2, err := tag.ReadFile(path)
+	asrt.NoError(err, "open")
+	if err == nil {
+		album, err := saved2.GetAlbum()
+		asrt.NoError(err)
+		asrt.Equal("Atomic Album", album)
+	}
+	asrt.NoError(os.Remove(link))
+
+	// failed save doesn't change the file
+	data, err := ioutil.ReadFile(path)
+	asrt.NoError(err)
+	broken := &tag.ID3v22{Frames: []tag.ID3v22Frame{{Key: "TT2", Value: make([]byte, 0x1000000)}}}
+	asrt.Equal(tag.ErrIncorrectLength, broken.SaveFile(path))
+
+	after, err := ioutil.ReadFile(path)
+	asrt.NoError(err)
+	asrt.Equal(data, after)
+
+	// no temporary files
+	files, err := ioutil.ReadDir(dir)
+	asrt.NoError(err)
+	asrt.Equal(2, len(files))
+}
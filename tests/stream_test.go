@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAudioReader(t *testing.T) {
+	asrt := assert.New(t)
+	for _, name := range []string{"meow_id2.4.mp3", "id3v1.mp3", "apev2.mp3", "cat_walking.mp4", "kitten.wav", "kitten.aiff", "kitten.dsf", "kitten.ogg",
+		"kitten.opus", "kitten.mka", "id3.flac"} {
+		metadata, err := tag.Read(mustOpen(t, name))
+		asrt.NoError(err, name)
+		if err != nil {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(metadata.AudioReader())
+		asrt.NoError(err, name)
+		asrt.NotEmpty(data, name)
+		asrt.Equal(metadata.GetFileData(), data, name)
+	}
+}
+
+func TestStreamingSave(t *testing.T) {
+	asrt := assert.New(t)
+	id3, err := tag.ReadID3v24(mustOpen(t, "meow_id2.4.mp3"))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	// audio is read from the source on save
+	asrt.Nil(id3.Data)
+	audio := id3.GetFileData()
+
+	asrt.NoError(id3.SetTitle("Streaming Cat"))
+	out, err := ioutil.TempFile("", "streamTst.mp3")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(id3.SaveFile(out.Name()))
+
+	// file closed by ReadFile is saved over itself
+	saved, err := tag.ReadFile(out.Name())
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, saved.GetFileData())
+	asrt.NoError(saved.SetArtist("Streaming Kitten"))
+	asrt.NoError(saved.SaveFile(out.Name()))
+
+	saved2, err := tag.ReadFile(out.Name())
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, saved2.GetFileData())
+
+	title, err := saved2.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("Streaming Cat", title)
+
+	artist, err := saved2.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Streaming Kitten", artist)
+
+	// data set by user replaces audio of the source
+	id3.Data = []byte{0xFF, 0xFB, 0x90, 0x00}
+	asrt.Equal(id3.Data, id3.GetFileData())
+	asrt.NoError(id3.SaveFile(out.Name()))
+
+	saved3, err := tag.ReadFile(out.Name())
+	asrt.NoError(err, "open")
+	if err == nil {
+		asrt.Equal(id3.Data, saved3.GetFileData())
+	}
+}
+
+func TestStreamingSaveContainers(t *testing.T) {
+	asrt := assert.New(t)
+	for _, name := range []string{"kitten.ogg", "kitten.opus", "kitten.mka", "id3.flac"} {
+		// file closed by ReadFile is saved over itself twice
+		path := tempCopy(t, name).Name()
+		var audio []byte
+		for i, title := range []string{"Streaming Cat", strings.Repeat("Streaming Kitten ", 200)} {
+			metadata, err := tag.ReadFile(path)
+			asrt.NoError(err, name)
+			if err != nil {
+				break
+			}
+			if i == 0 {
+				audio = metadata.GetFileData()
+				asrt.NotEmpty(audio, name)
+			}
+			asrt.NoError(metadata.SetTitle(title), name)
+			asrt.NoError(metadata.SaveFile(path), name)
+			asrt.Equal(audio, metadata.GetFileData(), name)
+
+			saved, err := tag.ReadFile(path)
+			asrt.NoError(err, name)
+			if err != nil {
+				break
+			}
+			asrt.Equal(audio, saved.GetFileData(), name)
+			value, err := saved.GetTitle()
+			asrt.NoError(err, name)
+			asrt.Equal(title, value, name)
+		}
+	}
+}
+
+func TestStreamingSaveShared(t *testing.T) {
+	asrt := assert.New(t)
+	dir, err := ioutil.TempDir("", "streamTst")
+	asrt.NoError(err)
+	defer os.RemoveAll(dir)
+
+	original, err := tag.ReadFile("alltags.mp3")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	audio := original.GetFileData()
+	data, err := ioutil.ReadFile("alltags.mp3")
+	asrt.NoError(err)
+	path := filepath.Join(dir, "alltags.mp3")
+	asrt.NoError(ioutil.WriteFile(path, data, 0600))
+
+	// file replaced by another Metadata is still read
+	a, err := tag.ReadFile(path)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	b, err := tag.ReadFile(path)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.NoError(b.SetTitle(strings.Repeat("Long Cat ", 3)))
+	asrt.NoError(b.SaveFile(path))
+	asrt.Equal(audio, a.GetFileData())
+	asrt.Equal(audio, b.GetFileData())
+
+	other := filepath.Join(dir, "other.mp3")
+	asrt.NoError(a.SaveFile(other))
+	saved, err := tag.ReadFile(other)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(audio, saved.GetFileData())
+
+	// audio moved in place by another Metadata isn't read
+	a, err = tag.ReadFile(path)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	asrt.NoError(err)
+	defer file.Close()
+	id3, err := tag.ReadID3v24(file)
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	id3.Padding = -1
+	asrt.NoError(id3.SetComment(strings.Repeat("meow ", 1000)))
+	asrt.NoError(id3.SaveInPlace(file))
+	asrt.Nil(a.GetFileData())
+	asrt.Equal(tag.ErrFileChanged, a.SaveFile(other))
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"encoding/binary"
+	"github.com/frolovo22/tag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWAVRead(t *testing.T) {
+	asrt := assert.New(t)
+	wav, err := tag.ReadFile("kitten.wav")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.Equal(tag.VersionWAV, wav.GetVersion())
+	asrt.Equal(1001, len(wav.GetFileData()))
+
+	// INFO has priority
+	title, err := wav.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("WAV Cat", title)
+
+	artist, err := wav.GetArtist()
+	asrt.NoError(err)
+	asrt.Equal("Cute Kitten", artist)
+
+	date, err := wav.GetDate()
+	asrt.NoError(err)
+	asrt.Equal(time.Date(2019, time.March, 5, 0, 0, 0, 0, time.UTC), date)
+
+	number, _, err := wav.GetTrackNumber()
+	asrt.NoError(err)
+	asrt.Equal(3, number)
+
+	// id3 chunk
+	albumArtist, err := wav.GetAlbumArtist()
+	asrt.NoError(err)
+	asrt.Equal("CatAlbumArtist", albumArtist)
+
+	composer, err := wav.GetComposer()
+	asrt.NoError(err)
+	asrt.Equal("catcomposer", composer)
+}
+
+func TestWAVWrite(t *testing.T) {
+	asrt := assert.New(t)
+	wav, err := tag.ReadFile("kitten.wav")
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+
+	asrt.NoError(wav.SetTitle("WAV Cat Running"))
+	asrt.NoError(wav.SetComment("meow"))
+	asrt.NoError(wav.SetBPM(120))
+	asrt.NoError(wav.DeleteGenre())
+
+	out, err := ioutil.TempFile("", "wavTst.wav")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(wav.SaveFile(out.Name()))
+
+	// RIFF size and word alignment
+	data, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(len(data)-8, int(binary.LittleEndian.Uint32(data[4:8])))
+	asrt.Equal(0, len(data)%2)
+
+	wav2, err := tag.ReadFile(out.Name())
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(wav.GetFileData(), wav2.GetFileData())
+
+	title, err := wav2.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("WAV Cat Running", title)
+
+	comment, err := wav2.GetComment()
+	asrt.NoError(err)
+	asrt.Equal("meow", comment)
+
+	bpm, err := wav2.GetBPM()
+	asrt.NoError(err)
+	asrt.Equal(120, bpm)
+
+	_, err = wav2.GetGenre()
+	asrt.Equal(tag.ErrTagNotFound, err)
+
+	// id3 chunk is updated too
+	id3 := wav2.(*tag.WAV).ID3
+	asrt.NotNil(id3)
+	if id3 != nil {
+		title, err = id3.GetTitle()
+		asrt.NoError(err)
+		asrt.Equal("WAV Cat Running", title)
+	}
+
+	// new id3 chunk
+	asrt.NoError(wav2.DeleteAll())
+	asrt.NoError(wav2.SetConductor("catconductor"))
+	asrt.NoError(wav2.SaveFile(out.Name()))
+
+	wav3, err := tag.ReadFile(out.Name())
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(wav.GetFileData(), wav3.GetFileData())
+
+	_, err = wav3.GetTitle()
+	asrt.Error(err)
+
+	conductor, err := wav3.GetConductor()
+	asrt.NoError(err)
+	asrt.Equal("catconductor", conductor)
+}
+
+func TestWAVTrailingData(t *testing.T) {
+	asrt := assert.New(t)
+	// chunk sizes past the RIFF chunk are not read, ID3v1 tag after it is kept
+	data := append([]byte("RIFF\x00\x00\x00\x00WAVEdata\x05\x00\x00\x00meows\x00"), id3v1Tag("Old Cat")...)
+	binary.LittleEndian.PutUint32(data[4:8], 18)
+	out, err := ioutil.TempFile("", "wavTst.wav")
+	asrt.NoError(err)
+	defer os.Remove(out.Name())
+	asrt.NoError(ioutil.WriteFile(out.Name(), data, 0600))
+
+	wav, err := tag.ReadWAV(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal(1, len(wav.Chunks))
+	asrt.Equal([]byte("meows"), wav.GetFileData())
+
+	asrt.NoError(wav.SetTitle("WAV Cat"))
+	asrt.NoError(wav.SaveFile(out.Name()))
+	saved, err := ioutil.ReadFile(out.Name())
+	asrt.NoError(err)
+	asrt.Equal(id3v1Tag("Old Cat"), saved[len(saved)-128:])
+	asrt.Equal(len(saved)-8-128, int(binary.LittleEndian.Uint32(saved[4:8])))
+
+	wav, err = tag.ReadWAV(mustOpen(t, out.Name()))
+	asrt.NoError(err, "open")
+	if err != nil {
+		return
+	}
+	asrt.Equal([]byte("meows"), wav.GetFileData())
+	title, err := wav.GetTitle()
+	asrt.NoError(err)
+	asrt.Equal("WAV Cat", title)
+}
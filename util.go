@@ -2,13 +2,16 @@ package tag
 
 import (
 	"bytes"
+	"compress/zlib"
 	"encoding/binary"
 	"image"
 	"image/color"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
 	"unicode/utf16"
-	"unicode/utf8"
 )
 
 func seekAndRead(input io.ReadSeeker, offset int64, whence int, read int) ([]byte, error) {
@@ -43,32 +46,48 @@ func readBytes(input io.Reader, size int) ([]byte, error) {
 		return nil, ErrEmptyFile
 	}
 
+	// buffered readers may return less than size
 	data := make([]byte, size)
-	nReaded, err := input.Read(data)
+	_, err := io.ReadFull(input, data)
+	if err == io.ErrUnexpectedEOF {
+		return nil, ErrReadFile
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	if nReaded != size {
-		return nil, ErrReadFile
-	}
-
 	return data, nil
 }
 
 func GetEncoding(code byte) string {
-	if code == 0 || code == 3 {
-		return encodingUTF8
-	}
-	if code == 1 {
-		return encodingUTF16
-	}
-	if code == 2 {
-		return encodingUTF16BE
+	switch code {
+	case 0:
+		return EncodingISO88591
+	case 1:
+		return EncodingUTF16
+	case 2:
+		return EncodingUTF16BE
+	case 3:
+		return EncodingUTF8
 	}
 	return ""
 }
 
+// encodingCode - encoding byte of text frame, 0xFF for unknown encoding
+func encodingCode(encoding string) byte {
+	switch encoding {
+	case EncodingISO88591:
+		return 0
+	case EncodingUTF16:
+		return 1
+	case EncodingUTF16BE:
+		return 2
+	case EncodingUTF8:
+		return 3
+	}
+	return 0xFF
+}
+
 // TextEncoding -
 // Text Encoding for text frame header
 // First byte determinate text encoding.
@@ -84,42 +103,61 @@ func TextEncoding(b []byte) string {
 
 func DecodeString(b []byte, encoding string) (string, error) {
 	switch encoding {
-	case encodingUTF8:
+	case EncodingISO88591:
+		return DecodeISO88591(b), nil
+	case EncodingUTF8:
 		return string(b), nil
-	case encodingUTF16:
+	case EncodingUTF16:
 		value, err := DecodeUTF16(b)
 		if err != nil {
 			return "", err
 		}
 		return value, nil
-	case encodingUTF16BE:
+	case EncodingUTF16BE:
 		return DecodeUTF16BE(b)
 	}
 
 	return "", ErrEncodingFormat
 }
 
-// Decode UTF-16 Little Endian to UTF-8.
+// DecodeISO88591 - decode ISO-8859-1 to UTF-8, each byte is a character.
+func DecodeISO88591(b []byte) string {
+	result := make([]rune, len(b))
+	for i, c := range b {
+		result[i] = rune(c)
+	}
+	return string(result)
+}
+
+// Decode UTF-16 to UTF-8.
+// Byte order is set by BOM at the start of each zero separated string, little endian without BOM.
 func DecodeUTF16(b []byte) (string, error) {
 	if len(b)%2 != 0 {
 		return "", ErrDecodeEvenLength
 	}
 
-	u16s := make([]uint16, 1)
-
-	ret := &bytes.Buffer{}
-
-	b8buf := make([]byte, 4)
+	units := make([]uint16, 0, len(b)/2)
+	bigEndian := false
+	start := true
+	for i := 0; i < len(b); i += 2 {
+		if start && b[i] == 0xFF && b[i+1] == 0xFE {
+			bigEndian, start = false, false
+			continue
+		}
+		if start && b[i] == 0xFE && b[i+1] == 0xFF {
+			bigEndian, start = true, false
+			continue
+		}
 
-	lb := len(b)
-	for i := 0; i < lb; i += 2 {
-		u16s[0] = uint16(b[i]) + (uint16(b[i+1]) << 8)
-		r := utf16.Decode(u16s)
-		n := utf8.EncodeRune(b8buf, r[0])
-		ret.Write(b8buf[:n])
+		unit := uint16(b[i]) + (uint16(b[i+1]) << 8)
+		if bigEndian {
+			unit = uint16(b[i+1]) + (uint16(b[i]) << 8)
+		}
+		units = append(units, unit)
+		start = unit == 0
 	}
 
-	return ret.String(), nil
+	return string(utf16.Decode(units)), nil
 }
 
 // Decode UTF-16 Big Endian To UTF-8.
@@ -128,21 +166,80 @@ func DecodeUTF16BE(b []byte) (string, error) {
 		return "", ErrDecodeEvenLength
 	}
 
-	u16s := make([]uint16, 1)
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i < len(b); i += 2 {
+		units = append(units, uint16(b[i+1])+(uint16(b[i])<<8))
+	}
+
+	return string(utf16.Decode(units)), nil
+}
 
-	ret := &bytes.Buffer{}
+// EncodeString - text with encoding byte. Value isn't representable in ISO-8859-1
+// if it has characters above U+00FF. Zero characters separate strings
+func EncodeString(value string, encoding string) ([]byte, error) {
+	code := encodingCode(encoding)
+	result := []byte{code}
+	switch encoding {
+	case EncodingISO88591:
+		for _, r := range value {
+			if r > 0xFF {
+				return nil, ErrEncodingFormat
+			}
+			result = append(result, byte(r))
+		}
+		return result, nil
+	case EncodingUTF16:
+		return append(result, EncodeUTF16(value)...), nil
+	case EncodingUTF16BE:
+		for _, u := range utf16.Encode([]rune(value)) {
+			result = append(result, byte(u>>8), byte(u))
+		}
+		return result, nil
+	case EncodingUTF8:
+		return append(result, value...), nil
+	}
+	return nil, ErrEncodingFormat
+}
 
-	b8buf := make([]byte, 4)
+// textTerminator - end of string in text encoding, $00 00 for UTF-16
+func textTerminator(code byte) []byte {
+	if code == 1 || code == 2 {
+		return []byte{0, 0}
+	}
+	return []byte{0}
+}
 
-	lb := len(b)
-	for i := 0; i < lb; i += 2 {
-		u16s[0] = uint16(b[i+1]) + (uint16(b[i]) << 8)
-		r := utf16.Decode(u16s)
-		n := utf8.EncodeRune(b8buf, r[0])
-		ret.Write(b8buf[:n])
+// chooseEncoding - forced encoding, or ISO-8859-1 if value is representable in it,
+// UTF-8 if allowed (ID3v2.4), UTF-16 with BOM otherwise
+func chooseEncoding(value string, forced string, utf8Allowed bool) string {
+	if forced != "" {
+		return forced
 	}
+	for _, r := range value {
+		if r > 0xFF {
+			if utf8Allowed {
+				return EncodingUTF8
+			}
+			return EncodingUTF16
+		}
+	}
+	return EncodingISO88591
+}
 
-	return ret.String(), nil
+// EncodeUTF16 - encode UTF-8 to UTF-16 Little Endian with BOM.
+// Zero characters separate strings, each string gets own BOM.
+func EncodeUTF16(value string) []byte {
+	result := []byte{}
+	for i, part := range strings.Split(value, "\x00") {
+		if i > 0 {
+			result = append(result, 0, 0)
+		}
+		result = append(result, 0xFF, 0xFE)
+		for _, u := range utf16.Encode([]rune(part)) {
+			result = append(result, byte(u), byte(u>>8))
+		}
+	}
+	return result
 }
 
 // ByteToIntSynchsafe -
@@ -166,8 +263,8 @@ func ByteToIntSynchsafe(data []byte) int {
 func IntToByteSynchsafe(data int) []byte {
 	// 7F = 0111 1111
 	return []byte{
-		byte(data>>23) & 0x7F,
-		byte(data>>15) & 0x7F,
+		byte(data>>21) & 0x7F,
+		byte(data>>14) & 0x7F,
 		byte(data>>7) & 0x7F,
 		byte(data) & 0x7F,
 	}
@@ -204,11 +301,11 @@ func GetString(b []byte) (string, error) {
 	return DecodeString(b[1:], TextEncoding(b))
 }
 
+// SetString - text with encoding byte, valid for all ID3v2 versions:
+// ISO-8859-1 if value is representable in it, UTF-16 with BOM otherwise
 func SetString(value string) []byte {
-	// Set UTF-8
-	result := []byte{0}
-	// Set data
-	return append(result, []byte(value)...)
+	result, _ := EncodeString(value, chooseEncoding(value, "", false))
+	return result
 }
 
 // Read format:
@@ -256,7 +353,6 @@ func downloadImage(url string) (image.Image, error) {
 	return img, err
 }
 
-// nolint:deadcode,unused
 func colorModelToBitsPerPixel(model color.Model) int {
 	var bpp int
 	switch model {
@@ -285,7 +381,7 @@ func colorModelToBitsPerPixel(model color.Model) int {
 
 func SplitBytesWithTextDescription(data []byte, encoding string) [][]byte {
 	separator := []byte{0}
-	if encoding == encodingUTF16 || encoding == encodingUTF16BE {
+	if encoding == EncodingUTF16 || encoding == EncodingUTF16BE {
 		separator = []byte{0, 0}
 	}
 
@@ -304,3 +400,109 @@ func SplitBytesWithTextDescription(data []byte, encoding string) [][]byte {
 	}
 	return result
 }
+
+// parseNumberTotal - value in format 'number/total' or 'number'
+func parseNumberTotal(value string) (int, int, error) {
+	numbers := strings.SplitN(value, "/", 2)
+	number, err := strconv.Atoi(strings.TrimSpace(numbers[0]))
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(numbers) == 1 {
+		return number, 0, nil
+	}
+	total, err := strconv.Atoi(strings.TrimSpace(numbers[1]))
+	if err != nil {
+		return 0, 0, err
+	}
+	return number, total, nil
+}
+
+// writeSeekBuffer - in memory io.WriteSeeker for saving nested tags
+type writeSeekBuffer struct {
+	data   []byte
+	offset int
+}
+
+func (buffer *writeSeekBuffer) Write(p []byte) (int, error) {
+	end := buffer.offset + len(p)
+	if end > len(buffer.data) {
+		buffer.data = append(buffer.data, make([]byte, end-len(buffer.data))...)
+	}
+	copy(buffer.data[buffer.offset:], p)
+	buffer.offset = end
+	return len(p), nil
+}
+
+func (buffer *writeSeekBuffer) Seek(offset int64, whence int) (int64, error) {
+	var position int64
+	switch whence {
+	case io.SeekStart:
+		position = offset
+	case io.SeekCurrent:
+		position = int64(buffer.offset) + offset
+	case io.SeekEnd:
+		position = int64(len(buffer.data)) + offset
+	default:
+		return 0, ErrSeekFile
+	}
+	if position < 0 {
+		return 0, ErrSeekFile
+	}
+	buffer.offset = int(position)
+	return position, nil
+}
+
+func (buffer *writeSeekBuffer) Bytes() []byte {
+	return buffer.data
+}
+
+// unsynchronise - insert $00 after $FF followed by %111xxxxx or $00 and after $FF at the end,
+// so data has no false MPEG synchs
+func unsynchronise(data []byte) []byte {
+	result := make([]byte, 0, len(data))
+	for i, b := range data {
+		result = append(result, b)
+		if b == 0xFF && (i+1 == len(data) || data[i+1] >= 0xE0 || data[i+1] == 0) {
+			result = append(result, 0)
+		}
+	}
+	return result
+}
+
+// resynchronise - remove $00 after $FF, reverse of unsynchronise
+func resynchronise(data []byte) []byte {
+	result := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		result = append(result, data[i])
+		if data[i] == 0xFF && i+1 < len(data) && data[i+1] == 0 {
+			i++
+		}
+	}
+	return result
+}
+
+// compressData - zlib compressed data
+func compressData(data []byte) ([]byte, error) {
+	output := new(bytes.Buffer)
+	writer := zlib.NewWriter(output)
+	_, err := writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return output.Bytes(), nil
+}
+
+// decompressData - data decompressed with zlib
+func decompressData(data []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
@@ -0,0 +1,632 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VorbisComments - Vorbis comment header of FLAC, Ogg Vorbis and Opus. Pictures are stored
+// in base64 encoded METADATA_BLOCK_PICTURE fields, FLAC keeps them in PICTURE blocks instead
+type VorbisComments struct {
+	Vendor string
+	Tags   map[string][]string
+}
+
+func (comments *VorbisComments) GetAllTagNames() []string {
+	result := make([]string, 0, len(comments.Tags))
+	for key := range comments.Tags {
+		result = append(result, key)
+	}
+	return result
+}
+
+func (comments *VorbisComments) GetTitle() (string, error) {
+	return comments.GetVorbisComment("TITLE")
+}
+
+func (comments *VorbisComments) GetArtist() (string, error) {
+	return comments.GetVorbisComment("ARTIST")
+}
+
+func (comments *VorbisComments) GetAlbum() (string, error) {
+	return comments.GetVorbisComment("ALBUM")
+}
+
+func (comments *VorbisComments) GetYear() (int, error) {
+	return comments.GetVorbisCommentInt("YEAR")
+}
+
+// GetComment - COMMENT field, DESCRIPTION if there is no COMMENT
+func (comments *VorbisComments) GetComment() (string, error) {
+	values := vorbisComments(comments.Tags)
+	if len(values) == 0 {
+		return "", ErrTagNotFound
+	}
+	return values[0].Text, nil
+}
+
+// Comments - values of COMMENT and DESCRIPTION fields, Vorbis comments have no language and description
+func (comments *VorbisComments) Comments() []Comment {
+	return vorbisComments(comments.Tags)
+}
+
+func (comments *VorbisComments) GetGenre() (string, error) {
+	return comments.GetVorbisComment("GENRE")
+}
+
+func (comments *VorbisComments) GetAlbumArtist() (string, error) {
+	return comments.GetVorbisComment("ALBUMARTIST")
+}
+
+func (comments *VorbisComments) GetDate() (time.Time, error) {
+	return comments.GetVorbisCommentTime("DATE")
+}
+
+func (comments *VorbisComments) GetArranger() (string, error) {
+	return comments.GetVorbisComment("ARRANGER")
+}
+
+func (comments *VorbisComments) GetAuthor() (string, error) {
+	return comments.GetVorbisComment("AUTHOR")
+}
+
+func (comments *VorbisComments) GetBPM() (int, error) {
+	return comments.GetVorbisCommentInt("BPM")
+}
+
+func (comments *VorbisComments) GetCatalogNumber() (string, error) {
+	return comments.GetVorbisComment("CATALOGNUMBER")
+}
+
+func (comments *VorbisComments) GetCompilation() (string, error) {
+	return comments.GetVorbisComment("COMPILATION")
+}
+
+func (comments *VorbisComments) GetComposer() (string, error) {
+	return comments.GetVorbisComment("COMPOSER")
+}
+
+func (comments *VorbisComments) GetConductor() (string, error) {
+	return comments.GetVorbisComment("CONDUCTOR")
+}
+
+func (comments *VorbisComments) GetCopyright() (string, error) {
+	return comments.GetVorbisComment("COPYRIGHT")
+}
+
+func (comments *VorbisComments) GetDescription() (string, error) {
+	return comments.GetVorbisComment("DESCRIPTION")
+}
+
+func (comments *VorbisComments) GetDiscNumber() (int, int, error) {
+	number, err := comments.GetVorbisCommentInt("DISCNUMBER")
+	if err != nil {
+		return 0, 0, err
+	}
+	total, err := comments.GetVorbisCommentInt("DISCTOTAL")
+	if err != nil {
+		return 0, 0, err
+	}
+	return number, total, nil
+}
+
+func (comments *VorbisComments) GetEncodedBy() (string, error) {
+	return comments.GetVorbisComment("ENCODED-BY")
+}
+
+func (comments *VorbisComments) GetTrackNumber() (int, int, error) {
+	number, err := comments.GetVorbisCommentInt("TRACKNUMBER")
+	if err != nil {
+		return 0, 0, err
+	}
+	total, err := comments.GetVorbisCommentInt("TRACKTOTAL")
+	if err != nil {
+		return 0, 0, err
+	}
+	return number, total, nil
+}
+
+func (comments *VorbisComments) GetPicture() (image.Image, error) {
+	pictureBlock, err := comments.GetMetadataBlockPicture()
+	if err != nil {
+		return nil, err
+	}
+	switch pictureBlock.MIME {
+	case mimeImageJPEG:
+		return jpeg.Decode(bytes.NewReader(pictureBlock.PictureData))
+	case mimeImagePNG:
+		return png.Decode(bytes.NewReader(pictureBlock.PictureData))
+	case mimeImageLink:
+		return downloadImage(string(pictureBlock.PictureData))
+	}
+
+	return nil, ErrIncorrectTag
+}
+
+// GetLyrics - LYRICS field, UNSYNCEDLYRICS if there is no LYRICS. Vorbis comments have no language
+func (comments *VorbisComments) GetLyrics(language string) (string, error) {
+	return vorbisLyrics(comments.Tags)
+}
+
+func (comments *VorbisComments) SetTitle(title string) error {
+	comments.Tags["TITLE"] = []string{title}
+	return nil
+}
+
+func (comments *VorbisComments) SetArtist(artist string) error {
+	comments.Tags["ARTIST"] = []string{artist}
+	return nil
+}
+
+func (comments *VorbisComments) SetAlbum(album string) error {
+	comments.Tags["ALBUM"] = []string{album}
+	return nil
+}
+
+func (comments *VorbisComments) SetYear(year int) error {
+	comments.Tags["YEAR"] = []string{strconv.Itoa(year)}
+	return nil
+}
+
+func (comments *VorbisComments) SetComment(comment string) error {
+	comments.Tags["COMMENT"] = []string{comment}
+	return nil
+}
+
+func (comments *VorbisComments) SetGenre(genre string) error {
+	comments.Tags["GENRE"] = []string{genre}
+	return nil
+}
+
+func (comments *VorbisComments) SetAlbumArtist(albumArtist string) error {
+	comments.Tags["ALBUMARTIST"] = []string{albumArtist}
+	return nil
+}
+
+func (comments *VorbisComments) SetDate(date time.Time) error {
+	comments.Tags["DATE"] = []string{date.Format("2006-01-02T15:04:05")}
+	return nil
+}
+
+func (comments *VorbisComments) SetArranger(arranger string) error {
+	comments.Tags["ARRANGER"] = []string{arranger}
+	return nil
+}
+
+func (comments *VorbisComments) SetAuthor(author string) error {
+	comments.Tags["AUTHOR"] = []string{author}
+	return nil
+}
+
+func (comments *VorbisComments) SetBPM(bmp int) error {
+	comments.Tags["BPM"] = []string{strconv.Itoa(bmp)}
+	return nil
+}
+
+func (comments *VorbisComments) SetCatalogNumber(catalogNumber string) error {
+	comments.Tags["CATALOGNUMBER"] = []string{catalogNumber}
+	return nil
+}
+
+func (comments *VorbisComments) SetCompilation(compilation string) error {
+	comments.Tags["COMPILATION"] = []string{compilation}
+	return nil
+}
+
+func (comments *VorbisComments) SetComposer(composer string) error {
+	comments.Tags["COMPOSER"] = []string{composer}
+	return nil
+}
+
+func (comments *VorbisComments) SetConductor(conductor string) error {
+	comments.Tags["CONDUCTOR"] = []string{conductor}
+	return nil
+}
+
+func (comments *VorbisComments) SetCopyright(copyright string) error {
+	comments.Tags["COPYRIGHT"] = []string{copyright}
+	return nil
+}
+
+func (comments *VorbisComments) SetDescription(description string) error {
+	comments.Tags["DESCRIPTION"] = []string{description}
+	return nil
+}
+
+func (comments *VorbisComments) SetDiscNumber(number int, total int) error {
+	comments.Tags["DISCNUMBER"] = []string{strconv.Itoa(number)}
+	comments.Tags["DISCTOTAL"] = []string{strconv.Itoa(total)}
+	return nil
+}
+
+func (comments *VorbisComments) SetEncodedBy(encodedBy string) error {
+	comments.Tags["ENCODED-BY"] = []string{encodedBy}
+	return nil
+}
+
+func (comments *VorbisComments) SetTrackNumber(number int, total int) error {
+	comments.Tags["TRACKNUMBER"] = []string{strconv.Itoa(number)}
+	comments.Tags["TRACKTOTAL"] = []string{strconv.Itoa(total)}
+	return nil
+}
+
+func (comments *VorbisComments) SetPicture(picture image.Image) error {
+	// Only PNG
+	buf := new(bytes.Buffer)
+	err := png.Encode(buf, picture)
+	if err != nil {
+		return err
+	}
+
+	pictureBlock := FlacMetadataBlockPicture{
+		Type:         3, // Cover (front)
+		MIME:         mimeImagePNG,
+		Width:        int32(picture.Bounds().Size().X),
+		Height:       int32(picture.Bounds().Size().Y),
+		BitsPerPixel: int32(colorModelToBitsPerPixel(picture.ColorModel())),
+		PictureData:  buf.Bytes(),
+	}
+
+	data := new(bytes.Buffer)
+	err = writeFlacPicture(data, &pictureBlock)
+	if err != nil {
+		return err
+	}
+
+	comments.Tags[vorbisPictureTag] = []string{base64.StdEncoding.EncodeToString(data.Bytes())}
+	return nil
+}
+
+// SetLyrics - set LYRICS field, UNSYNCEDLYRICS is replaced
+func (comments *VorbisComments) SetLyrics(language string, lyrics string) error {
+	delete(comments.Tags, vorbisUnsyncedLyricsTag)
+	comments.Tags[vorbisLyricsTag] = []string{lyrics}
+	return nil
+}
+
+func (comments *VorbisComments) DeleteAll() error {
+	comments.Tags = map[string][]string{}
+	return nil
+}
+
+func (comments *VorbisComments) DeleteTitle() error {
+	delete(comments.Tags, "TITLE")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteArtist() error {
+	delete(comments.Tags, "ARTIST")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteAlbum() error {
+	delete(comments.Tags, "ALBUM")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteYear() error {
+	delete(comments.Tags, "YEAR")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteComment() error {
+	delete(comments.Tags, "COMMENT")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteGenre() error {
+	delete(comments.Tags, "GENRE")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteAlbumArtist() error {
+	delete(comments.Tags, "ALBUMARTIST")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteDate() error {
+	delete(comments.Tags, "DATE")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteArranger() error {
+	delete(comments.Tags, "ARRANGER")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteAuthor() error {
+	delete(comments.Tags, "AUTHOR")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteBPM() error {
+	delete(comments.Tags, "BPM")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteCatalogNumber() error {
+	delete(comments.Tags, "CATALOGNUMBER")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteCompilation() error {
+	delete(comments.Tags, "COMPILATION")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteComposer() error {
+	delete(comments.Tags, "COMPOSER")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteConductor() error {
+	delete(comments.Tags, "CONDUCTOR")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteCopyright() error {
+	delete(comments.Tags, "COPYRIGHT")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteDescription() error {
+	delete(comments.Tags, "DESCRIPTION")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteDiscNumber() error {
+	delete(comments.Tags, "DISCNUMBER")
+	delete(comments.Tags, "DISCTOTAL")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteEncodedBy() error {
+	delete(comments.Tags, "ENCODED-BY")
+	return nil
+}
+
+func (comments *VorbisComments) DeleteTrackNumber() error {
+	delete(comments.Tags, "TRACKNUMBER")
+	delete(comments.Tags, "TRACKTOTAL")
+	return nil
+}
+
+func (comments *VorbisComments) DeletePicture() error {
+	delete(comments.Tags, vorbisPictureTag)
+	return nil
+}
+
+func (comments *VorbisComments) DeleteLyrics(language string) error {
+	delete(comments.Tags, vorbisLyricsTag)
+	delete(comments.Tags, vorbisUnsyncedLyricsTag)
+	return nil
+}
+
+func (comments *VorbisComments) GetVorbisComment(key string) (string, error) {
+	val, ok := comments.Tags[key]
+	if !ok || len(val) == 0 {
+		return "", ErrTagNotFound
+	}
+	return val[0], nil
+}
+
+// GetValues - all values of the field, Vorbis comments may repeat it
+func (comments *VorbisComments) GetValues(field string) ([]string, error) {
+	return vorbisValues(comments.Tags, field)
+}
+
+// SetValues - replace all values of the field, empty values delete it
+func (comments *VorbisComments) SetValues(field string, values []string) error {
+	setVorbisValues(comments.Tags, field, values)
+	return nil
+}
+
+func (comments *VorbisComments) GetVorbisCommentInt(key string) (int, error) {
+	comment, err := comments.GetVorbisComment(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(comment)
+}
+
+func (comments *VorbisComments) GetVorbisCommentTime(key string) (time.Time, error) {
+	comment, err := comments.GetVorbisComment(key)
+	if err != nil {
+		return time.Now(), err
+	}
+	result, err := time.Parse("2006-01-02T15:04:05", comment)
+	if err != nil {
+		return time.Now(), err
+	}
+	return result, nil
+}
+
+// GetMetadataBlockPicture - picture from base64 encoded METADATA_BLOCK_PICTURE comment
+func (comments *VorbisComments) GetMetadataBlockPicture() (*FlacMetadataBlockPicture, error) {
+	value, err := comments.GetVorbisComment(vorbisPictureTag)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return readFlacPicture(bytes.NewReader(data))
+}
+
+type VorbisComment struct {
+	Name  string
+	Value string
+}
+
+// The comment header is decoded as follows:
+//
+//  1. [vendor_length] = read an unsigned integer of 32 bits
+//
+//  2. [vendor_string] = read a UTF-8 vector as [vendor_length] octets
+//
+//  3. [user_comment_list_length] = read an unsigned integer of 32 bits
+//
+//  4. iterate [user_comment_list_length] times {
+//
+//  5. [length] = read an unsigned integer of 32 bits
+//
+//  6. this iteration's user comment = read a UTF-8 vector as [length] octets
+//
+//     }
+//
+//  7. [framing_bit] = read a single bit as boolean
+//
+// Declared lengths are checked against the rest of the packet, ErrIncorrectLength is returned if they exceed it
+func readVorbisComments(input *bytes.Reader) ([]VorbisComment, string, error) {
+	result := []VorbisComment{}
+
+	// vendor
+	vendorByte, err := readVorbisLengthData(input)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// user_comment_list_length
+	var length uint32
+	err = binary.Read(input, binary.LittleEndian, &length)
+	if err != nil {
+		return nil, "", err
+	}
+	// every comment has 4 bytes length
+	if int64(length)*4 > int64(input.Len()) {
+		return nil, "", ErrIncorrectLength
+	}
+
+	// iterate
+	for i := 0; i < int(length); i++ {
+		data, err := readVorbisLengthData(input)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// Parse data
+		vorbis := strings.SplitN(string(data), "=", 2)
+		if len(vorbis) != 2 {
+			return nil, "", ErrIncorrectTag
+		}
+
+		comment := VorbisComment{
+			Name:  vorbis[0],
+			Value: vorbis[1],
+		}
+		result = append(result, comment)
+	}
+	return result, string(vendorByte), nil
+}
+
+// readVorbisLengthData - vector with 32 bits little endian length, the length must fit into the packet
+func readVorbisLengthData(input *bytes.Reader) ([]byte, error) {
+	var length uint32
+	err := binary.Read(input, binary.LittleEndian, &length)
+	if err != nil {
+		return nil, err
+	}
+	if int64(length) > int64(input.Len()) {
+		return nil, ErrIncorrectLength
+	}
+	return readBytes(input, int(length))
+}
+
+// vorbisComments - comments of COMMENT fields, then of DESCRIPTION fields used by some taggers
+func vorbisComments(tags map[string][]string) []Comment {
+	var result []Comment
+	for _, field := range []string{"COMMENT", "DESCRIPTION"} {
+		for _, value := range tags[field] {
+			result = append(result, Comment{Text: value})
+		}
+	}
+	return result
+}
+
+// vorbisLyrics - lyrics of LYRICS field or UNSYNCEDLYRICS field written by some taggers
+func vorbisLyrics(tags map[string][]string) (string, error) {
+	for _, field := range []string{vorbisLyricsTag, vorbisUnsyncedLyricsTag} {
+		if len(tags[field]) > 0 {
+			return tags[field][0], nil
+		}
+	}
+	return "", ErrTagNotFound
+}
+
+// vorbisValues - values of case insensitive field
+func vorbisValues(tags map[string][]string, field string) ([]string, error) {
+	values := tags[strings.ToUpper(field)]
+	if len(values) == 0 {
+		return nil, ErrTagNotFound
+	}
+	return append([]string{}, values...), nil
+}
+
+func setVorbisValues(tags map[string][]string, field string, values []string) {
+	field = strings.ToUpper(field)
+	if len(values) == 0 {
+		delete(tags, field)
+		return
+	}
+	tags[field] = append([]string{}, values...)
+}
+
+func serializeVorbisComments(comments map[string][]string, vendorHeader string) []byte {
+	// Serialize out the vorbis comments as a metadata block payload
+	// Spawn out all the tag blobs first
+	output := bytes.NewBuffer([]byte{})
+	keys := make([]string, 0, len(comments))
+	for key := range comments {
+		keys = append(keys, key)
+	}
+	// stable output
+	sort.Strings(keys)
+	count := 0
+	for _, key := range keys {
+		// repeated fields keep the order of values
+		for _, value := range comments[key] {
+			line := key + "=" + value
+			if err := writeLengthData(output, binary.LittleEndian, []byte(line)); err != nil {
+				return []byte{}
+			}
+			count++
+		}
+	}
+
+	// Now that we have the payload content figured out, we can reconstruct its headers
+	dataPayload, err := ioutil.ReadAll(output)
+	if err != nil {
+		return []byte{}
+	}
+
+	output.Grow(len(vendorHeader) + 4)
+	output.Reset() // clear our scratchpad
+	if err = writeLengthData(output, binary.LittleEndian, []byte(vendorHeader)); err != nil {
+		return []byte{}
+	}
+
+	userCommentLength := uint32(count)
+	if err = binary.Write(output, binary.LittleEndian, userCommentLength); err != nil {
+		return []byte{}
+	}
+	output.Write(dataPayload)
+
+	payload, err := ioutil.ReadAll(output)
+	if err != nil {
+		return []byte{}
+	}
+
+	return payload
+}
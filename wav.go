@@ -0,0 +1,664 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WAV - LIST INFO chunk and ID3v2 tag from 'id3 ' chunk.
+// INFO values have priority on read, ID3v2 tag keeps other fields
+type WAV struct {
+	Chunks []*IFFChunk
+	Info   map[string]string
+	ID3    Metadata // *ID3v22, *ID3v23 or *ID3v24
+
+	trailer *fileRegion // data after RIFF chunk, e.g. ID3v1 tag, written as is
+}
+
+func (wav *WAV) GetAllTagNames() []string {
+	result := make([]string, 0, len(wav.Info))
+	for key := range wav.Info {
+		result = append(result, key)
+	}
+	if wav.ID3 != nil {
+		result = append(result, wav.ID3.GetAllTagNames()...)
+	}
+	return result
+}
+
+func (wav *WAV) GetVersion() Version {
+	return VersionWAV
+}
+
+func (wav *WAV) GetFileData() []byte {
+	chunk := findIFFChunk(wav.Chunks, wavDataChunk)
+	if chunk == nil {
+		return nil
+	}
+	return readSection(chunk.Reader())
+}
+
+func (wav *WAV) AudioReader() io.Reader {
+	chunk := findIFFChunk(wav.Chunks, wavDataChunk)
+	if chunk == nil {
+		return bytes.NewReader(nil)
+	}
+	return chunk.Reader()
+}
+
+func (wav *WAV) GetTitle() (string, error) {
+	title, err := wav.GetInfo("INAM")
+	if err == nil || wav.ID3 == nil {
+		return title, err
+	}
+	return wav.ID3.GetTitle()
+}
+
+func (wav *WAV) GetArtist() (string, error) {
+	artist, err := wav.GetInfo("IART")
+	if err == nil || wav.ID3 == nil {
+		return artist, err
+	}
+	return wav.ID3.GetArtist()
+}
+
+func (wav *WAV) GetAlbum() (string, error) {
+	album, err := wav.GetInfo("IPRD")
+	if err == nil || wav.ID3 == nil {
+		return album, err
+	}
+	return wav.ID3.GetAlbum()
+}
+
+func (wav *WAV) GetYear() (int, error) {
+	date, err := wav.GetInfo("ICRD")
+	if err == nil && len(date) >= 4 {
+		return strconv.Atoi(date[0:4])
+	}
+	if wav.ID3 == nil {
+		return 0, ErrTagNotFound
+	}
+	return wav.ID3.GetYear()
+}
+
+func (wav *WAV) GetComment() (string, error) {
+	comment, err := wav.GetInfo("ICMT")
+	if err == nil || wav.ID3 == nil {
+		return comment, err
+	}
+	return wav.ID3.GetComment()
+}
+
+func (wav *WAV) GetGenre() (string, error) {
+	genre, err := wav.GetInfo("IGNR")
+	if err == nil || wav.ID3 == nil {
+		return genre, err
+	}
+	return wav.ID3.GetGenre()
+}
+
+func (wav *WAV) GetAlbumArtist() (string, error) {
+	if wav.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return wav.ID3.GetAlbumArtist()
+}
+
+// GetDate - ICRD in format yyyy-MM-dd or ID3v2 date
+func (wav *WAV) GetDate() (time.Time, error) {
+	date, err := wav.GetInfo("ICRD")
+	if err == nil {
+		var result time.Time
+		result, err = time.Parse("2006-01-02", date)
+		if err == nil || wav.ID3 == nil {
+			return result, err
+		}
+	}
+	if wav.ID3 == nil {
+		return time.Now(), ErrTagNotFound
+	}
+	return wav.ID3.GetDate()
+}
+
+func (wav *WAV) GetArranger() (string, error) {
+	if wav.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return wav.ID3.GetArranger()
+}
+
+func (wav *WAV) GetAuthor() (string, error) {
+	if wav.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return wav.ID3.GetAuthor()
+}
+
+func (wav *WAV) GetBPM() (int, error) {
+	if wav.ID3 == nil {
+		return 0, ErrTagNotFound
+	}
+	return wav.ID3.GetBPM()
+}
+
+func (wav *WAV) GetCatalogNumber() (string, error) {
+	if wav.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return wav.ID3.GetCatalogNumber()
+}
+
+func (wav *WAV) GetCompilation() (string, error) {
+	if wav.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return wav.ID3.GetCompilation()
+}
+
+func (wav *WAV) GetComposer() (string, error) {
+	if wav.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return wav.ID3.GetComposer()
+}
+
+func (wav *WAV) GetConductor() (string, error) {
+	if wav.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return wav.ID3.GetConductor()
+}
+
+func (wav *WAV) GetCopyright() (string, error) {
+	if wav.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return wav.ID3.GetCopyright()
+}
+
+func (wav *WAV) GetDescription() (string, error) {
+	if wav.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return wav.ID3.GetDescription()
+}
+
+func (wav *WAV) GetDiscNumber() (int, int, error) {
+	if wav.ID3 == nil {
+		return 0, 0, ErrTagNotFound
+	}
+	return wav.ID3.GetDiscNumber()
+}
+
+func (wav *WAV) GetEncodedBy() (string, error) {
+	if wav.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return wav.ID3.GetEncodedBy()
+}
+
+func (wav *WAV) GetTrackNumber() (int, int, error) {
+	track, err := wav.GetInfo("ITRK")
+	if err == nil {
+		return parseNumberTotal(track)
+	}
+	if wav.ID3 == nil {
+		return 0, 0, err
+	}
+	return wav.ID3.GetTrackNumber()
+}
+
+func (wav *WAV) GetPicture() (image.Image, error) {
+	if wav.ID3 == nil {
+		return nil, ErrTagNotFound
+	}
+	return wav.ID3.GetPicture()
+}
+
+func (wav *WAV) GetLyrics(language string) (string, error) {
+	if wav.ID3 == nil {
+		return "", ErrTagNotFound
+	}
+	return wav.ID3.GetLyrics(language)
+}
+
+func (wav *WAV) SetTitle(title string) error {
+	wav.Info["INAM"] = title
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.SetTitle(title)
+}
+
+func (wav *WAV) SetArtist(artist string) error {
+	wav.Info["IART"] = artist
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.SetArtist(artist)
+}
+
+func (wav *WAV) SetAlbum(album string) error {
+	wav.Info["IPRD"] = album
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.SetAlbum(album)
+}
+
+func (wav *WAV) SetYear(year int) error {
+	wav.Info["ICRD"] = strconv.Itoa(year)
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.SetYear(year)
+}
+
+func (wav *WAV) SetComment(comment string) error {
+	wav.Info["ICMT"] = comment
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.SetComment(comment)
+}
+
+func (wav *WAV) SetGenre(genre string) error {
+	wav.Info["IGNR"] = genre
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.SetGenre(genre)
+}
+
+func (wav *WAV) SetAlbumArtist(albumArtist string) error {
+	return wav.id3().SetAlbumArtist(albumArtist)
+}
+
+func (wav *WAV) SetDate(date time.Time) error {
+	wav.Info["ICRD"] = date.Format("2006-01-02")
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.SetDate(date)
+}
+
+func (wav *WAV) SetArranger(arranger string) error {
+	return wav.id3().SetArranger(arranger)
+}
+
+func (wav *WAV) SetAuthor(author string) error {
+	return wav.id3().SetAuthor(author)
+}
+
+func (wav *WAV) SetBPM(bmp int) error {
+	return wav.id3().SetBPM(bmp)
+}
+
+func (wav *WAV) SetCatalogNumber(catalogNumber string) error {
+	return wav.id3().SetCatalogNumber(catalogNumber)
+}
+
+func (wav *WAV) SetCompilation(compilation string) error {
+	return wav.id3().SetCompilation(compilation)
+}
+
+func (wav *WAV) SetComposer(composer string) error {
+	return wav.id3().SetComposer(composer)
+}
+
+func (wav *WAV) SetConductor(conductor string) error {
+	return wav.id3().SetConductor(conductor)
+}
+
+func (wav *WAV) SetCopyright(copyright string) error {
+	return wav.id3().SetCopyright(copyright)
+}
+
+func (wav *WAV) SetDescription(description string) error {
+	return wav.id3().SetDescription(description)
+}
+
+func (wav *WAV) SetDiscNumber(number int, total int) error {
+	return wav.id3().SetDiscNumber(number, total)
+}
+
+func (wav *WAV) SetEncodedBy(encodedBy string) error {
+	return wav.id3().SetEncodedBy(encodedBy)
+}
+
+func (wav *WAV) SetTrackNumber(number int, total int) error {
+	wav.Info["ITRK"] = strconv.Itoa(number)
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.SetTrackNumber(number, total)
+}
+
+func (wav *WAV) SetPicture(picture image.Image) error {
+	return wav.id3().SetPicture(picture)
+}
+
+func (wav *WAV) SetLyrics(language string, lyrics string) error {
+	return wav.id3().SetLyrics(language, lyrics)
+}
+
+func (wav *WAV) DeleteAll() error {
+	wav.Info = map[string]string{}
+	wav.ID3 = nil
+	return nil
+}
+
+func (wav *WAV) DeleteTitle() error {
+	delete(wav.Info, "INAM")
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteTitle()
+}
+
+func (wav *WAV) DeleteArtist() error {
+	delete(wav.Info, "IART")
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteArtist()
+}
+
+func (wav *WAV) DeleteAlbum() error {
+	delete(wav.Info, "IPRD")
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteAlbum()
+}
+
+func (wav *WAV) DeleteYear() error {
+	delete(wav.Info, "ICRD")
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteYear()
+}
+
+func (wav *WAV) DeleteComment() error {
+	delete(wav.Info, "ICMT")
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteComment()
+}
+
+func (wav *WAV) DeleteGenre() error {
+	delete(wav.Info, "IGNR")
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteGenre()
+}
+
+func (wav *WAV) DeleteAlbumArtist() error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteAlbumArtist()
+}
+
+func (wav *WAV) DeleteDate() error {
+	delete(wav.Info, "ICRD")
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteDate()
+}
+
+func (wav *WAV) DeleteArranger() error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteArranger()
+}
+
+func (wav *WAV) DeleteAuthor() error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteAuthor()
+}
+
+func (wav *WAV) DeleteBPM() error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteBPM()
+}
+
+func (wav *WAV) DeleteCatalogNumber() error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteCatalogNumber()
+}
+
+func (wav *WAV) DeleteCompilation() error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteCompilation()
+}
+
+func (wav *WAV) DeleteComposer() error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteComposer()
+}
+
+func (wav *WAV) DeleteConductor() error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteConductor()
+}
+
+func (wav *WAV) DeleteCopyright() error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteCopyright()
+}
+
+func (wav *WAV) DeleteDescription() error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteDescription()
+}
+
+func (wav *WAV) DeleteDiscNumber() error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteDiscNumber()
+}
+
+func (wav *WAV) DeleteEncodedBy() error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteEncodedBy()
+}
+
+func (wav *WAV) DeleteTrackNumber() error {
+	delete(wav.Info, "ITRK")
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteTrackNumber()
+}
+
+func (wav *WAV) DeletePicture() error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeletePicture()
+}
+
+func (wav *WAV) DeleteLyrics(language string) error {
+	if wav.ID3 == nil {
+		return nil
+	}
+	return wav.ID3.DeleteLyrics(language)
+}
+
+func (wav *WAV) SaveFile(path string) error {
+	return saveFile(path, wav.Save, SaveOptions{})
+}
+
+// Save - write RIFF file with rebuilt LIST INFO and 'id3 ' chunks.
+// Chunks keep their order, new chunks are added to the end
+func (wav *WAV) Save(input io.WriteSeeker) error {
+	info := serializeWAVInfo(wav.Info)
+	var id3 []byte
+	if wav.ID3 != nil {
+		buffer := &writeSeekBuffer{}
+		if err := wav.ID3.Save(buffer); err != nil {
+			return err
+		}
+		id3 = buffer.Bytes()
+	}
+
+	chunks := make([]*IFFChunk, 0, len(wav.Chunks)+2)
+	for _, chunk := range wav.Chunks {
+		switch {
+		case isWAVInfoChunk(chunk):
+			if info != nil {
+				chunks = append(chunks, &IFFChunk{ID: wavListChunk, Data: info})
+				info = nil
+			}
+		case isIFFID3(chunk):
+			if id3 != nil {
+				chunks = append(chunks, &IFFChunk{ID: wavID3Chunk, Data: id3})
+				id3 = nil
+			}
+		default:
+			chunks = append(chunks, chunk)
+		}
+	}
+	if info != nil {
+		chunks = append(chunks, &IFFChunk{ID: wavListChunk, Data: info})
+	}
+	if id3 != nil {
+		chunks = append(chunks, &IFFChunk{ID: wavID3Chunk, Data: id3})
+	}
+
+	err := writeIFF(input, binary.LittleEndian, wavRIFFIdentifier, wavWAVEIdentifier, chunks)
+	if err != nil {
+		return err
+	}
+	err = writeAudio(input, nil, wav.trailer)
+	if err != nil {
+		return err
+	}
+	wav.Chunks = chunks
+	return nil
+}
+
+func checkWAV(input io.ReadSeeker) bool {
+	header, err := seekAndRead(input, 0, io.SeekStart, 12)
+	if err != nil {
+		return false
+	}
+	return string(header[0:4]) == wavRIFFIdentifier && string(header[8:12]) == wavWAVEIdentifier
+}
+
+func ReadWAV(input io.ReadSeeker) (*WAV, error) {
+	wav := WAV{
+		Info: map[string]string{},
+	}
+
+	if !checkWAV(input) {
+		return nil, ErrFileMarker
+	}
+
+	// chunks after RIFF header
+	var err error
+	wav.Chunks, wav.trailer, err = readIFFFileChunks(input, binary.LittleEndian, wavDataChunk)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, chunk := range wav.Chunks {
+		switch {
+		case isWAVInfoChunk(chunk):
+			readWAVInfo(chunk.Data[4:], wav.Info)
+		case isIFFID3(chunk) && wav.ID3 == nil:
+			wav.ID3, err = readIFFID3(chunk.Data)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &wav, nil
+}
+
+func readWAVInfo(data []byte, info map[string]string) {
+	for _, chunk := range readIFFChunks(data, binary.LittleEndian) {
+		// zero terminated strings
+		info[chunk.ID] = strings.TrimRight(string(chunk.Data), "\x00")
+	}
+}
+
+func serializeWAVInfo(info map[string]string) []byte {
+	if len(info) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(info))
+	for key := range info {
+		keys = append(keys, key)
+	}
+	// stable output
+	sort.Strings(keys)
+
+	buffer := bytes.NewBufferString(wavInfoIdentifier)
+	for _, key := range keys {
+		chunk := IFFChunk{
+			ID:   key,
+			Data: append([]byte(info[key]), 0),
+		}
+		if err := chunk.Write(buffer, binary.LittleEndian); err != nil {
+			return nil
+		}
+	}
+	return buffer.Bytes()
+}
+
+func isWAVInfoChunk(chunk *IFFChunk) bool {
+	return chunk.ID == wavListChunk && len(chunk.Data) >= 4 && string(chunk.Data[0:4]) == wavInfoIdentifier
+}
+
+func (wav *WAV) GetInfo(id string) (string, error) {
+	value, ok := wav.Info[id]
+	if !ok {
+		return "", ErrTagNotFound
+	}
+	return value, nil
+}
+
+// id3 - ID3v2 tag for fields without INFO chunk, created on demand
+func (wav *WAV) id3() Metadata {
+	if wav.ID3 == nil {
+		wav.ID3 = &ID3v24{
+			Marker:  id3MarkerValue,
+			Version: VersionID3v24,
+		}
+	}
+	return wav.ID3
+}